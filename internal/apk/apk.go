@@ -0,0 +1,211 @@
+// Package apk reads the values ordercli needs from an Android app package:
+// the Firebase project the app talks to, its signing certificate and its
+// version. It understands plain .apk files and the .xapk/.apkm bundles that
+// wrap a base APK and its splits.
+package apk
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Info is what Inspect found in an APK.
+type Info struct {
+	PackageName string
+	VersionCode int64
+	VersionName string
+
+	// Firebase values from the google-services resources.
+	APIKey     string // google_api_key
+	ProjectID  string // project_id
+	ProjectNum string // gcm_defaultSenderId
+	AppID      string // google_app_id
+
+	// CertSHA1 is the uppercase hex SHA1 of the signing certificate.
+	CertSHA1 string
+}
+
+// maxEntrySize caps how much of a single zip entry is read into memory.
+const maxEntrySize = 512 << 20
+
+// Inspect reads path, which may be an APK or an XAPK/APKM bundle.
+func Inspect(path string) (Info, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer zr.Close()
+	return inspectZip(&zr.Reader)
+}
+
+// InspectBytes is Inspect for an archive already in memory.
+func InspectBytes(b []byte) (Info, error) {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return Info{}, err
+	}
+	return inspectZip(zr)
+}
+
+func inspectZip(zr *zip.Reader) (Info, error) {
+	if findFile(zr, "AndroidManifest.xml") == nil {
+		// A bundle: inspect the base APK inside it.
+		f := baseAPK(zr)
+		if f == nil {
+			return Info{}, errors.New("no AndroidManifest.xml and no base APK inside the archive")
+		}
+		b, err := readFile(f)
+		if err != nil {
+			return Info{}, err
+		}
+		inner, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			return Info{}, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if findFile(inner, "AndroidManifest.xml") == nil {
+			return Info{}, fmt.Errorf("%s: no AndroidManifest.xml", f.Name)
+		}
+		zr = inner
+	}
+
+	var info Info
+	manifest, err := readFile(findFile(zr, "AndroidManifest.xml"))
+	if err != nil {
+		return Info{}, err
+	}
+	m, err := parseManifest(manifest)
+	if err != nil {
+		return Info{}, fmt.Errorf("AndroidManifest.xml: %w", err)
+	}
+	info.PackageName, info.VersionCode, info.VersionName = m.Package, m.VersionCode, m.VersionName
+
+	f := findFile(zr, "resources.arsc")
+	if f == nil {
+		return Info{}, errors.New("no resources.arsc")
+	}
+	arsc, err := readFile(f)
+	if err != nil {
+		return Info{}, err
+	}
+	strs, err := parseStringResources(arsc)
+	if err != nil {
+		return Info{}, fmt.Errorf("resources.arsc: %w", err)
+	}
+	info.APIKey = strs["google_api_key"]
+	info.ProjectID = strs["project_id"]
+	info.ProjectNum = strs["gcm_defaultSenderId"]
+	info.AppID = strs["google_app_id"]
+	if info.ProjectNum == "" {
+		// google_app_id is 1:<project number>:android:<hash>.
+		if parts := strings.Split(info.AppID, ":"); len(parts) >= 2 {
+			info.ProjectNum = parts[1]
+		}
+	}
+
+	sig := signatureFile(zr)
+	if sig == nil {
+		return Info{}, errors.New("no v1 signature in META-INF (APKs signed only with v2+ are not supported)")
+	}
+	b, err := readFile(sig)
+	if err != nil {
+		return Info{}, err
+	}
+	info.CertSHA1, err = signerCertSHA1(b)
+	if err != nil {
+		return Info{}, fmt.Errorf("%s: %w", sig.Name, err)
+	}
+	return info, nil
+}
+
+// Missing lists the Firebase values Inspect could not find.
+func (i Info) Missing() []string {
+	var out []string
+	for _, f := range []struct{ name, v string }{
+		{"google_api_key", i.APIKey},
+		{"project_id", i.ProjectID},
+		{"gcm_defaultSenderId", i.ProjectNum},
+		{"google_app_id", i.AppID},
+	} {
+		if f.v == "" {
+			out = append(out, f.name)
+		}
+	}
+	return out
+}
+
+func findFile(zr *zip.Reader, name string) *zip.File {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// baseAPK picks the base APK of a bundle: base.apk, else the largest APK
+// that isn't a config split.
+func baseAPK(zr *zip.Reader) *zip.File {
+	var apks []*zip.File
+	for _, f := range zr.File {
+		name := path.Base(f.Name)
+		if !strings.EqualFold(path.Ext(name), ".apk") {
+			continue
+		}
+		if name == "base.apk" {
+			return f
+		}
+		if strings.HasPrefix(name, "config.") || strings.HasPrefix(name, "split_") {
+			continue
+		}
+		apks = append(apks, f)
+	}
+	if len(apks) == 0 {
+		return nil
+	}
+	sort.SliceStable(apks, func(i, j int) bool {
+		return apks[i].UncompressedSize64 > apks[j].UncompressedSize64
+	})
+	return apks[0]
+}
+
+// signatureFile returns the PKCS#7 block of the v1 (JAR) signature.
+func signatureFile(zr *zip.Reader) *zip.File {
+	var out []*zip.File
+	for _, f := range zr.File {
+		dir, name := path.Split(f.Name)
+		if dir != "META-INF/" {
+			continue
+		}
+		switch strings.ToUpper(path.Ext(name)) {
+		case ".RSA", ".DSA", ".EC":
+			out = append(out, f)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out[0]
+}
+
+func readFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > maxEntrySize {
+		return nil, fmt.Errorf("%s: too large (%d bytes)", f.Name, f.UncompressedSize64)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(io.LimitReader(rc, maxEntrySize))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	return b, nil
+}
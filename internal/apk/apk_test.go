@@ -0,0 +1,308 @@
+package apk
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+func TestInspect_APK(t *testing.T) {
+	apk, certSHA1 := testAPK(t)
+	info, err := InspectBytes(apk)
+	if err != nil {
+		t.Fatalf("InspectBytes: %v", err)
+	}
+	want := Info{
+		PackageName: "at.example",
+		VersionCode: 250300134,
+		VersionName: "25.3.0",
+		APIKey:      "AIzaTestKey",
+		ProjectID:   "example-123",
+		ProjectNum:  "384955814039",
+		AppID:       "1:384955814039:android:d8bb88d8282d022e",
+		CertSHA1:    certSHA1,
+	}
+	if info != want {
+		t.Fatalf("got %#v\nwant %#v", info, want)
+	}
+	if m := info.Missing(); len(m) != 0 {
+		t.Fatalf("missing %v", m)
+	}
+}
+
+func TestInspect_Bundle(t *testing.T) {
+	apk, certSHA1 := testAPK(t)
+	bundle := zipOf(t, map[string][]byte{
+		"manifest.json":     []byte(`{"package_name":"at.example"}`),
+		"config.arm64.apk":  zipOf(t, map[string][]byte{"x": make([]byte, 4096)}),
+		"at.example.apk":    apk,
+		"icon.png":          {0x89, 'P', 'N', 'G'},
+		"config.de.apk":     zipOf(t, nil),
+		"Android/obb/x.obb": nil,
+	})
+	info, err := InspectBytes(bundle)
+	if err != nil {
+		t.Fatalf("InspectBytes: %v", err)
+	}
+	if info.PackageName != "at.example" || info.CertSHA1 != certSHA1 {
+		t.Fatalf("unexpected: %#v", info)
+	}
+}
+
+func TestInspect_Errors(t *testing.T) {
+	if _, err := InspectBytes(zipOf(t, map[string][]byte{"readme.txt": []byte("hi")})); err == nil || !strings.Contains(err.Error(), "no base APK") {
+		t.Fatalf("err=%v", err)
+	}
+	apk := zipOf(t, map[string][]byte{
+		"AndroidManifest.xml": testManifest(),
+		"resources.arsc":      testResources(),
+	})
+	if _, err := InspectBytes(apk); err == nil || !strings.Contains(err.Error(), "no v1 signature") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestInfoMissing(t *testing.T) {
+	got := Info{APIKey: "k", AppID: "a"}.Missing()
+	if strings.Join(got, ",") != "project_id,gcm_defaultSenderId" {
+		t.Fatalf("got %v", got)
+	}
+}
+
+// testAPK builds a minimal APK and returns it with its signer's SHA1.
+func testAPK(t *testing.T) ([]byte, string) {
+	t.Helper()
+	sig, sum := testSignature(t)
+	return zipOf(t, map[string][]byte{
+		"AndroidManifest.xml":  testManifest(),
+		"resources.arsc":       testResources(),
+		"classes.dex":          {0x64, 0x65, 0x78},
+		"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\n"),
+		"META-INF/CERT.SF":     []byte("Signature-Version: 1.0\n"),
+		"META-INF/CERT.RSA":    sig,
+	}), sum
+}
+
+func zipOf(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, b := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testSignature(t *testing.T) ([]byte, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Android"},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Unix(0, 0).AddDate(30, 0, 0),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustMarshal := func(v any) []byte {
+		b, err := asn1.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	set := asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true}
+	data := mustMarshal(struct{ Type asn1.ObjectIdentifier }{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}})
+	var sd []byte
+	sd = append(sd, mustMarshal(1)...)
+	sd = append(sd, mustMarshal(set)...)
+	sd = append(sd, data...)
+	sd = append(sd, mustMarshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: cert})...)
+	sd = append(sd, mustMarshal(set)...)
+	signedData := mustMarshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: sd})
+	der := mustMarshal(struct {
+		Type    asn1.ObjectIdentifier
+		Content asn1.RawValue
+	}{oidSignedData, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData}})
+
+	sum := sha1.Sum(cert)
+	return der, strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// resWriter builds little-endian resource chunks.
+type resWriter struct{ bytes.Buffer }
+
+func (w *resWriter) u8(v uint8)   { w.WriteByte(v) }
+func (w *resWriter) u16(v uint16) { _ = binary.Write(w, binary.LittleEndian, v) }
+func (w *resWriter) u32(v uint32) { _ = binary.Write(w, binary.LittleEndian, v) }
+
+// chunkOf wraps body in a chunk header; extra is the rest of the header.
+func chunkOf(typ uint16, extra, body []byte) []byte {
+	var w resWriter
+	w.u16(typ)
+	w.u16(uint16(8 + len(extra)))
+	w.u32(uint32(8 + len(extra) + len(body)))
+	w.Write(extra)
+	w.Write(body)
+	return w.Bytes()
+}
+
+func stringPool(strs []string, utf8 bool) []byte {
+	var data resWriter
+	var offsets []uint32
+	for _, s := range strs {
+		offsets = append(offsets, uint32(data.Len()))
+		if utf8 {
+			data.u8(uint8(len(s))) // all test strings are short and ASCII
+			data.u8(uint8(len(s)))
+			data.WriteString(s)
+			data.u8(0)
+		} else {
+			units := utf16.Encode([]rune(s))
+			data.u16(uint16(len(units)))
+			for _, u := range units {
+				data.u16(u)
+			}
+			data.u16(0)
+		}
+	}
+	for data.Len()%4 != 0 {
+		data.u8(0)
+	}
+	var hdr resWriter
+	hdr.u32(uint32(len(strs)))
+	hdr.u32(0)
+	if utf8 {
+		hdr.u32(stringPoolUTF8)
+	} else {
+		hdr.u32(0)
+	}
+	hdr.u32(uint32(28 + 4*len(strs)))
+	hdr.u32(0)
+	var body resWriter
+	for _, o := range offsets {
+		body.u32(o)
+	}
+	body.Write(data.Bytes())
+	return chunkOf(chunkStringPool, hdr.Bytes(), body.Bytes())
+}
+
+func testResources() []byte {
+	values := []string{"AIzaTestKey", "example-123", "384955814039", "1:384955814039:android:d8bb88d8282d022e", "Example"}
+	keys := []string{"app_name", "google_api_key", "project_id", "gcm_defaultSenderId", "google_app_id"}
+	// key index -> value index.
+	entries := []struct{ key, value uint32 }{{0, 4}, {1, 0}, {2, 1}, {3, 2}, {4, 3}}
+
+	var typ resWriter
+	var typeHdr resWriter
+	typeHdr.u8(2) // "string"
+	typeHdr.u8(0)
+	typeHdr.u16(0)
+	typeHdr.u32(uint32(len(entries)))
+	const configSize = 64
+	typeHdr.u32(uint32(8 + 12 + configSize + 4*len(entries)))
+	typeHdr.u32(configSize)
+	typeHdr.Write(make([]byte, configSize-4))
+	for i := range entries {
+		typ.u32(uint32(16 * i))
+	}
+	for _, e := range entries {
+		typ.u16(8)
+		typ.u16(0)
+		typ.u32(e.key)
+		typ.u16(8)
+		typ.u8(0)
+		typ.u8(typeString)
+		typ.u32(e.value)
+	}
+	typeChunk := chunkOf(chunkTableType, typeHdr.Bytes(), typ.Bytes())
+
+	typePool := stringPool([]string{"attr", "string"}, true)
+	keyPool := stringPool(keys, true)
+	const pkgHeader = 288
+	var pkgHdr resWriter
+	pkgHdr.u32(0x7f)
+	pkgHdr.Write(make([]byte, 256))
+	pkgHdr.u32(pkgHeader)
+	pkgHdr.u32(2)
+	pkgHdr.u32(uint32(pkgHeader + len(typePool)))
+	pkgHdr.u32(uint32(len(keys)))
+	pkgHdr.u32(0)
+	var pkgBody []byte
+	pkgBody = append(pkgBody, typePool...)
+	pkgBody = append(pkgBody, keyPool...)
+	pkgBody = append(pkgBody, typeChunk...)
+	pkg := chunkOf(chunkTablePackage, pkgHdr.Bytes(), pkgBody)
+
+	var tableHdr resWriter
+	tableHdr.u32(1)
+	var body []byte
+	body = append(body, stringPool(values, false)...)
+	body = append(body, pkg...)
+	return chunkOf(chunkTable, tableHdr.Bytes(), body)
+}
+
+func testManifest() []byte {
+	strs := []string{"versionCode", "versionName", "package", "manifest", "25.3.0", "at.example"}
+	var resMap resWriter
+	resMap.u32(attrVersionCode)
+	resMap.u32(attrVersionName)
+
+	var elemHdr resWriter
+	elemHdr.u32(1)          // line
+	elemHdr.u32(0xFFFFFFFF) // comment
+	var elem resWriter
+	elem.u32(0xFFFFFFFF) // ns
+	elem.u32(3)          // "manifest"
+	elem.u16(20)
+	elem.u16(20)
+	elem.u16(3)
+	elem.u16(0)
+	elem.u16(0)
+	elem.u16(0)
+	attr := func(name, raw uint32, dataType uint8, data uint32) {
+		elem.u32(0xFFFFFFFF)
+		elem.u32(name)
+		elem.u32(raw)
+		elem.u16(8)
+		elem.u8(0)
+		elem.u8(dataType)
+		elem.u32(data)
+	}
+	attr(0, 0xFFFFFFFF, typeIntDec, 250300134)
+	attr(1, 4, typeString, 4)
+	attr(2, 5, typeString, 5)
+
+	var body []byte
+	body = append(body, stringPool(strs, false)...)
+	body = append(body, chunkOf(chunkXMLResMap, nil, resMap.Bytes())...)
+	body = append(body, chunkOf(chunkXMLStartElem, elemHdr.Bytes(), elem.Bytes())...)
+	return chunkOf(chunkXML, nil, body)
+}
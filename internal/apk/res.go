@@ -0,0 +1,406 @@
+package apk
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Chunk types of Android's binary resource format (ResourceTypes.h).
+const (
+	chunkStringPool   = 0x0001
+	chunkTable        = 0x0002
+	chunkXML          = 0x0003
+	chunkXMLStartElem = 0x0102
+	chunkXMLResMap    = 0x0180
+	chunkTablePackage = 0x0200
+	chunkTableType    = 0x0201
+)
+
+// Res_value data types.
+const (
+	typeString = 0x03
+	typeIntDec = 0x10
+	typeIntHex = 0x11
+)
+
+const (
+	stringPoolUTF8    = 1 << 8
+	tableTypeSparse   = 0x01
+	tableTypeOffset16 = 0x02
+	entryComplex      = 0x0001
+	entryCompact      = 0x0008
+	noEntry           = 0xFFFFFFFF
+	noIndex           = 0xFFFFFFFF
+)
+
+var errTruncated = errors.New("truncated chunk")
+
+var le = binary.LittleEndian
+
+// chunk is one ResChunk_header and its bytes (header included).
+type chunk struct {
+	typ        uint16
+	headerSize int
+	data       []byte
+}
+
+func readChunk(b []byte) (chunk, error) {
+	if len(b) < 8 {
+		return chunk{}, errTruncated
+	}
+	c := chunk{typ: le.Uint16(b), headerSize: int(le.Uint16(b[2:]))}
+	size := int(le.Uint32(b[4:]))
+	if c.headerSize < 8 || size < c.headerSize || size > len(b) {
+		return chunk{}, fmt.Errorf("chunk 0x%04x: bad size %d (header %d, have %d)", c.typ, size, c.headerSize, len(b))
+	}
+	c.data = b[:size]
+	return c, nil
+}
+
+// children walks the chunks following c's header.
+func (c chunk) children(fn func(chunk) error) error {
+	b := c.data[c.headerSize:]
+	for len(b) > 0 {
+		sub, err := readChunk(b)
+		if err != nil {
+			return err
+		}
+		if err := fn(sub); err != nil {
+			return err
+		}
+		b = b[len(sub.data):]
+	}
+	return nil
+}
+
+func parseStringPool(c chunk) ([]string, error) {
+	b := c.data
+	if c.headerSize < 28 {
+		return nil, errTruncated
+	}
+	count := int(le.Uint32(b[8:]))
+	flags := le.Uint32(b[16:])
+	start := int(le.Uint32(b[20:]))
+	if c.headerSize+4*count > len(b) || start > len(b) {
+		return nil, errTruncated
+	}
+	out := make([]string, count)
+	for i := range out {
+		off := start + int(le.Uint32(b[c.headerSize+4*i:]))
+		if off >= len(b) {
+			return nil, errTruncated
+		}
+		var err error
+		if flags&stringPoolUTF8 != 0 {
+			out[i], err = utf8PoolString(b[off:])
+		} else {
+			out[i], err = utf16PoolString(b[off:])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func utf8PoolString(b []byte) (string, error) {
+	// UTF-16 length, then UTF-8 length; each one or two bytes.
+	_, b, err := utf8Len(b)
+	if err != nil {
+		return "", err
+	}
+	n, b, err := utf8Len(b)
+	if err != nil {
+		return "", err
+	}
+	if n > len(b) {
+		return "", errTruncated
+	}
+	return string(b[:n]), nil
+}
+
+func utf8Len(b []byte) (int, []byte, error) {
+	if len(b) < 1 {
+		return 0, nil, errTruncated
+	}
+	if b[0]&0x80 == 0 {
+		return int(b[0]), b[1:], nil
+	}
+	if len(b) < 2 {
+		return 0, nil, errTruncated
+	}
+	return int(b[0]&0x7F)<<8 | int(b[1]), b[2:], nil
+}
+
+func utf16PoolString(b []byte) (string, error) {
+	if len(b) < 2 {
+		return "", errTruncated
+	}
+	n := int(le.Uint16(b))
+	b = b[2:]
+	if n&0x8000 != 0 {
+		if len(b) < 2 {
+			return "", errTruncated
+		}
+		n = (n&0x7FFF)<<16 | int(le.Uint16(b))
+		b = b[2:]
+	}
+	if 2*n > len(b) {
+		return "", errTruncated
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = le.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+func poolString(pool []string, i uint32) string {
+	if i == noIndex || int(i) >= len(pool) {
+		return ""
+	}
+	return pool[i]
+}
+
+// parseStringResources returns the string resources of every package in a
+// resources.arsc by name. When a string has several configurations (locales
+// etc.) the first non-empty one wins; the Firebase values aren't localized.
+func parseStringResources(b []byte) (map[string]string, error) {
+	table, err := readChunk(b)
+	if err != nil {
+		return nil, err
+	}
+	if table.typ != chunkTable {
+		return nil, fmt.Errorf("not a resource table (chunk 0x%04x)", table.typ)
+	}
+	var values []string
+	out := map[string]string{}
+	err = table.children(func(c chunk) error {
+		switch c.typ {
+		case chunkStringPool:
+			var err error
+			values, err = parseStringPool(c)
+			return err
+		case chunkTablePackage:
+			return parsePackageStrings(c, values, out)
+		}
+		return nil
+	})
+	return out, err
+}
+
+func parsePackageStrings(pkg chunk, values []string, out map[string]string) error {
+	// ResTable_package: header, id, name[128], typeStrings, lastPublicType,
+	// keyStrings, lastPublicKey.
+	if pkg.headerSize < 8+4+256+16 {
+		return errTruncated
+	}
+	typeStringsOff := int(le.Uint32(pkg.data[8+4+256:]))
+	keyStringsOff := int(le.Uint32(pkg.data[8+4+256+8:]))
+
+	// The type and key pools are told apart by their offsets.
+	var typeNames, keyNames []string
+	pos := pkg.headerSize
+	for pos < len(pkg.data) {
+		c, err := readChunk(pkg.data[pos:])
+		if err != nil {
+			return err
+		}
+		switch {
+		case c.typ == chunkStringPool && pos == typeStringsOff:
+			if typeNames, err = parseStringPool(c); err != nil {
+				return err
+			}
+		case c.typ == chunkStringPool && pos == keyStringsOff:
+			if keyNames, err = parseStringPool(c); err != nil {
+				return err
+			}
+		case c.typ == chunkTableType:
+			if err := parseTypeStrings(c, typeNames, keyNames, values, out); err != nil {
+				return err
+			}
+		}
+		pos += len(c.data)
+	}
+	return nil
+}
+
+func parseTypeStrings(c chunk, typeNames, keyNames, values []string, out map[string]string) error {
+	// ResTable_type: header, id, flags, reserved, entryCount, entriesStart, config.
+	b := c.data
+	if c.headerSize < 20 {
+		return errTruncated
+	}
+	id := int(b[8])
+	if id == 0 || id > len(typeNames) || typeNames[id-1] != "string" {
+		return nil
+	}
+	flags := b[9]
+	count := int(le.Uint32(b[12:]))
+	entriesStart := int(le.Uint32(b[16:]))
+
+	var offsets []uint32
+	idx := c.headerSize
+	switch {
+	case flags&tableTypeSparse != 0:
+		for i := 0; i < count; i++ {
+			if idx+4 > len(b) {
+				return errTruncated
+			}
+			offsets = append(offsets, uint32(le.Uint16(b[idx+2:]))*4)
+			idx += 4
+		}
+	case flags&tableTypeOffset16 != 0:
+		for i := 0; i < count; i++ {
+			if idx+2 > len(b) {
+				return errTruncated
+			}
+			if o := le.Uint16(b[idx:]); o == 0xFFFF {
+				offsets = append(offsets, noEntry)
+			} else {
+				offsets = append(offsets, uint32(o)*4)
+			}
+			idx += 2
+		}
+	default:
+		for i := 0; i < count; i++ {
+			if idx+4 > len(b) {
+				return errTruncated
+			}
+			offsets = append(offsets, le.Uint32(b[idx:]))
+			idx += 4
+		}
+	}
+
+	for _, o := range offsets {
+		if o == noEntry {
+			continue
+		}
+		e := entriesStart + int(o)
+		if e+8 > len(b) {
+			return errTruncated
+		}
+		eflags := le.Uint16(b[e+2:])
+		var key uint32
+		var dataType byte
+		var data uint32
+		switch {
+		case eflags&entryCompact != 0:
+			// Compact entry: key, flags (data type in the high byte), data.
+			key = uint32(le.Uint16(b[e:]))
+			dataType = byte(eflags >> 8)
+			data = le.Uint32(b[e+4:])
+		case eflags&entryComplex != 0:
+			continue
+		default:
+			size := int(le.Uint16(b[e:]))
+			key = le.Uint32(b[e+4:])
+			v := e + size
+			if v+8 > len(b) {
+				return errTruncated
+			}
+			dataType = b[v+3]
+			data = le.Uint32(b[v+4:])
+		}
+		if dataType != typeString {
+			continue
+		}
+		name := poolString(keyNames, key)
+		if name == "" || out[name] != "" {
+			continue
+		}
+		out[name] = poolString(values, data)
+	}
+	return nil
+}
+
+// Framework resource IDs of the manifest attributes we read; obfuscated
+// manifests may strip the attribute names but keep these.
+const (
+	attrVersionCode = 0x0101021b
+	attrVersionName = 0x0101021c
+)
+
+type manifest struct {
+	Package     string
+	VersionCode int64
+	VersionName string
+}
+
+// parseManifest reads the <manifest> element of a binary AndroidManifest.xml.
+func parseManifest(b []byte) (manifest, error) {
+	doc, err := readChunk(b)
+	if err != nil {
+		return manifest{}, err
+	}
+	if doc.typ != chunkXML {
+		return manifest{}, fmt.Errorf("not binary XML (chunk 0x%04x)", doc.typ)
+	}
+	var pool []string
+	var resIDs []uint32
+	var m manifest
+	found := false
+	errDone := errors.New("done")
+	err = doc.children(func(c chunk) error {
+		switch c.typ {
+		case chunkStringPool:
+			var err error
+			pool, err = parseStringPool(c)
+			return err
+		case chunkXMLResMap:
+			for i := c.headerSize; i+4 <= len(c.data); i += 4 {
+				resIDs = append(resIDs, le.Uint32(c.data[i:]))
+			}
+		case chunkXMLStartElem:
+			// ResXMLTree_attrExt follows the node header: ns, name,
+			// attributeStart, attributeSize, attributeCount, ...
+			x := c.data[c.headerSize:]
+			if len(x) < 20 {
+				return errTruncated
+			}
+			if poolString(pool, le.Uint32(x[4:])) != "manifest" {
+				return nil
+			}
+			start, size, count := int(le.Uint16(x[8:])), int(le.Uint16(x[10:])), int(le.Uint16(x[12:]))
+			for i := 0; i < count; i++ {
+				a := start + i*size
+				if a+20 > len(x) {
+					return errTruncated
+				}
+				nameIdx := le.Uint32(x[a+4:])
+				raw := le.Uint32(x[a+8:])
+				dataType, data := x[a+15], le.Uint32(x[a+16:])
+				var resID uint32
+				if int(nameIdx) < len(resIDs) {
+					resID = resIDs[nameIdx]
+				}
+				str := poolString(pool, raw)
+				if str == "" && dataType == typeString {
+					str = poolString(pool, data)
+				}
+				switch name := poolString(pool, nameIdx); {
+				case name == "package":
+					m.Package = str
+				case resID == attrVersionCode || (resID == 0 && name == "versionCode"):
+					if dataType == typeIntDec || dataType == typeIntHex {
+						m.VersionCode = int64(data)
+					}
+				case resID == attrVersionName || (resID == 0 && name == "versionName"):
+					m.VersionName = str
+				}
+			}
+			found = true
+			return errDone
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDone) {
+		return manifest{}, err
+	}
+	if !found {
+		return manifest{}, errors.New("no <manifest> element")
+	}
+	return m, nil
+}
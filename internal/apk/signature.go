@@ -0,0 +1,47 @@
+package apk
+
+import (
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+// signerCertSHA1 returns the SHA1 of the first certificate in a PKCS#7
+// SignedData block, which for a JAR signature is the signing certificate.
+// This is the fingerprint Google API keys restricted to Android apps check.
+func signerCertSHA1(der []byte) (string, error) {
+	var ci struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue `asn1:"explicit,tag:0"`
+	}
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		return "", fmt.Errorf("pkcs7: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return "", fmt.Errorf("pkcs7: content type %s is not signedData", ci.ContentType)
+	}
+	var sd struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      asn1.RawValue
+		Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	}
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return "", fmt.Errorf("pkcs7 signedData: %w", err)
+	}
+	if len(sd.Certificates.Bytes) == 0 {
+		return "", errors.New("pkcs7: no certificates")
+	}
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("pkcs7 certificates: %w", err)
+	}
+	sum := sha1.Sum(certs[0].Raw)
+	return strings.ToUpper(hex.EncodeToString(sum[:])), nil
+}
@@ -0,0 +1,451 @@
+// Package archive is a local, append-only order store: JSON Lines segments plus
+// a small JSON index keyed by "provider:id".
+//
+// Layout (inside the archive dir):
+//
+//	index.json              segment list + key -> (segment, line)
+//	index.json.lock         held while a process appends (see config.Lock)
+//	orders-000001.jsonl     one Record per line, never rewritten
+//	orders-000002.jsonl     started once the previous segment is full
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+const (
+	indexFile         = "index.json"
+	indexVersion      = 1
+	maxSegmentRecords = 5000
+)
+
+// Record is one archived order: normalized fields plus the raw upstream payload.
+type Record struct {
+	Provider   string          `json:"provider"`
+	ID         string          `json:"id"`
+	ArchivedAt time.Time       `json:"archived_at"`
+	Order      provider.Order  `json:"order"`
+	Raw        json.RawMessage `json:"raw,omitempty"`
+}
+
+func (r Record) Key() string { return r.Provider + ":" + r.ID }
+
+// NewRecord wraps a normalized order (keeping its Raw payload).
+func NewRecord(o provider.Order, now time.Time) Record {
+	return Record{
+		Provider:   o.Provider,
+		ID:         o.ID,
+		ArchivedAt: now.UTC(),
+		Order:      o,
+		Raw:        o.Raw,
+	}
+}
+
+type Segment struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+}
+
+type Location struct {
+	Segment string `json:"segment"`
+	Line    int    `json:"line"`
+}
+
+type index struct {
+	Version  int                 `json:"version"`
+	Segments []Segment           `json:"segments"`
+	Orders   map[string]Location `json:"orders"`
+	// Complete lists the providers whose whole history has been archived.
+	Complete map[string]bool `json:"complete,omitempty"`
+}
+
+type Archive struct {
+	dir string
+	idx index
+}
+
+// DefaultDir returns the archive dir that lives next to the config file.
+func DefaultDir(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "archive")
+}
+
+// Open loads the index in dir, rebuilding it from the segments when it is
+// missing or out of sync (e.g. after a crash between append and index write).
+func Open(dir string) (*Archive, error) {
+	a := &Archive{dir: dir}
+	ok, err := a.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	if ok && a.indexMatchesSegments() {
+		return a, nil
+	}
+	if _, err := os.Stat(a.dir); errors.Is(err, os.ErrNotExist) {
+		// Nothing archived yet; don't create the dir just to read it.
+		if err := a.rebuild(); err != nil {
+			return nil, err
+		}
+		return a, nil
+	}
+	// A rebuild rewrites the index and may truncate a torn tail, so it runs
+	// under the writers' lock; lock reloads and rebuilds if still needed.
+	unlock, err := a.lock()
+	if err != nil {
+		return nil, err
+	}
+	unlock()
+	return a, nil
+}
+
+func (a *Archive) Dir() string { return a.dir }
+
+// Len returns the number of archived orders.
+func (a *Archive) Len() int { return len(a.idx.Orders) }
+
+// Has reports whether the order key ("provider:id") is archived.
+func (a *Archive) Has(key string) bool {
+	_, ok := a.idx.Orders[key]
+	return ok
+}
+
+// IsComplete reports whether provider's history was archived to its end.
+func (a *Archive) IsComplete(provider string) bool { return a.idx.Complete[provider] }
+
+// SetComplete records whether provider's history is archived to its end, so
+// syncs know whether older orders may still be missing.
+func (a *Archive) SetComplete(provider string, complete bool) error {
+	if a.idx.Complete[provider] == complete {
+		return nil
+	}
+	unlock, err := a.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if complete {
+		if a.idx.Complete == nil {
+			a.idx.Complete = map[string]bool{}
+		}
+		a.idx.Complete[provider] = true
+	} else {
+		delete(a.idx.Complete, provider)
+	}
+	return a.saveIndex()
+}
+
+// Append writes records whose key is not archived yet and returns how many were added.
+func (a *Archive) Append(records []Record) (int, error) {
+	if len(a.fresh(records)) == 0 {
+		return 0, nil
+	}
+	unlock, err := a.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	fresh := a.fresh(records)
+	if len(fresh) == 0 {
+		return 0, nil
+	}
+	added := len(fresh)
+
+	for len(fresh) > 0 {
+		seg := a.currentSegment()
+		n := min(len(fresh), maxSegmentRecords-seg.Records)
+		if err := a.appendToSegment(seg, fresh[:n]); err != nil {
+			return 0, err
+		}
+		fresh = fresh[n:]
+	}
+	if err := a.saveIndex(); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// fresh returns the records that are valid and not archived yet, once each.
+func (a *Archive) fresh(records []Record) []Record {
+	var out []Record
+	seen := map[string]bool{}
+	for _, r := range records {
+		k := r.Key()
+		if r.Provider == "" || r.ID == "" || a.Has(k) || seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, r)
+	}
+	return out
+}
+
+// lock takes the archive's cross-process write lock and reloads the index,
+// which another process may have appended to since Open.
+func (a *Archive) lock() (func(), error) {
+	if err := os.MkdirAll(a.dir, 0o700); err != nil {
+		return nil, err
+	}
+	unlock, err := config.Lock(filepath.Join(a.dir, indexFile), true)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := a.loadIndex()
+	if err == nil && (!ok || !a.indexMatchesSegments()) {
+		err = a.rebuild()
+	}
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	return unlock, nil
+}
+
+// Each calls fn for every archived record in append order.
+func (a *Archive) Each(fn func(Record) error) error {
+	for _, seg := range a.idx.Segments {
+		err := readSegment(filepath.Join(a.dir, seg.Name), func(_ int, r Record) error {
+			return fn(r)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Orders returns all archived orders (normalized, with Raw restored).
+func (a *Archive) Orders() ([]provider.Order, error) {
+	out := make([]provider.Order, 0, a.Len())
+	err := a.Each(func(r Record) error {
+		o := r.Order
+		o.Raw = r.Raw
+		out = append(out, o)
+		return nil
+	})
+	return out, err
+}
+
+func (a *Archive) currentSegment() *Segment {
+	if n := len(a.idx.Segments); n > 0 && a.idx.Segments[n-1].Records < maxSegmentRecords {
+		return &a.idx.Segments[n-1]
+	}
+	a.idx.Segments = append(a.idx.Segments, Segment{Name: segmentName(len(a.idx.Segments) + 1)})
+	return &a.idx.Segments[len(a.idx.Segments)-1]
+}
+
+func (a *Archive) appendToSegment(seg *Segment, records []Record) error {
+	f, err := os.OpenFile(filepath.Join(a.dir, seg.Name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, r := range records {
+		b, err := json.Marshal(r)
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		b = append(b, '\n')
+		if _, err := w.Write(b); err != nil {
+			_ = f.Close()
+			return err
+		}
+		seg.Records++
+		a.idx.Orders[r.Key()] = Location{Segment: seg.Name, Line: seg.Records}
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (a *Archive) loadIndex() (bool, error) {
+	a.idx = index{Version: indexVersion, Orders: map[string]Location{}}
+	b, err := os.ReadFile(filepath.Join(a.dir, indexFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	var idx index
+	if err := json.Unmarshal(b, &idx); err != nil || idx.Version != indexVersion {
+		return false, nil
+	}
+	if idx.Orders == nil {
+		idx.Orders = map[string]Location{}
+	}
+	a.idx = idx
+	return true, nil
+}
+
+func (a *Archive) indexMatchesSegments() bool {
+	names, err := a.segmentFiles()
+	if err != nil || len(names) != len(a.idx.Segments) {
+		return false
+	}
+	for i, seg := range a.idx.Segments {
+		if names[i] != seg.Name {
+			return false
+		}
+	}
+	if n := len(a.idx.Segments); n > 0 {
+		last := a.idx.Segments[n-1]
+		path := filepath.Join(a.dir, last.Name)
+		lines, err := countLines(path)
+		if err != nil || lines != last.Records || !endsWithNewline(path) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *Archive) rebuild() error {
+	a.idx = index{Version: indexVersion, Orders: map[string]Location{}, Complete: a.idx.Complete}
+	names, err := a.segmentFiles()
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		if i == len(names)-1 {
+			if err := truncateTornTail(filepath.Join(a.dir, name)); err != nil {
+				return err
+			}
+		}
+		seg := Segment{Name: name}
+		err := readSegment(filepath.Join(a.dir, name), func(line int, r Record) error {
+			seg.Records = line
+			if _, ok := a.idx.Orders[r.Key()]; !ok {
+				a.idx.Orders[r.Key()] = Location{Segment: name, Line: line}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		a.idx.Segments = append(a.idx.Segments, seg)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return a.saveIndex()
+}
+
+func (a *Archive) saveIndex() error {
+	b, err := json.MarshalIndent(a.idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	path := filepath.Join(a.dir, indexFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (a *Archive) segmentFiles() ([]string, error) {
+	entries, err := os.ReadDir(a.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), "orders-") && strings.HasSuffix(e.Name(), ".jsonl") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func segmentName(n int) string { return fmt.Sprintf("orders-%06d.jsonl", n) }
+
+// readSegment calls fn with the 1-based line number of every complete record.
+// A torn trailing line (crash mid-write) is ignored.
+func readSegment(path string, fn func(line int, r Record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	line := 0
+	for {
+		b, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var r Record
+		if err := json.Unmarshal(b, &r); err != nil {
+			return fmt.Errorf("%s:%d: %w", filepath.Base(path), line+1, err)
+		}
+		line++
+		if err := fn(line, r); err != nil {
+			return err
+		}
+	}
+}
+
+func countLines(path string) (int, error) {
+	n := 0
+	err := readSegment(path, func(line int, _ Record) error {
+		n = line
+		return nil
+	})
+	return n, err
+}
+
+func endsWithNewline(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil || st.Size() == 0 {
+		return err == nil
+	}
+	var b [1]byte
+	if _, err := f.ReadAt(b[:], st.Size()-1); err != nil {
+		return false
+	}
+	return b[0] == '\n'
+}
+
+// truncateTornTail drops a partial last line so later appends start cleanly.
+func truncateTornTail(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil || len(b) == 0 || b[len(b)-1] == '\n' {
+		return err
+	}
+	end := 0
+	if i := strings.LastIndexByte(string(b), '\n'); i >= 0 {
+		end = i + 1
+	}
+	return os.Truncate(path, int64(end))
+}
@@ -0,0 +1,162 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func rec(p, id string) Record {
+	return NewRecord(provider.Order{Provider: p, ID: id, Raw: []byte(`{"id":"` + id + `"}`)}, time.Unix(0, 0))
+}
+
+func TestArchive_AppendDedupAndReopen(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	n, err := a.Append([]Record{rec("foodora", "A"), rec("foodora", "B"), rec("foodora", "A")})
+	if err != nil || n != 2 {
+		t.Fatalf("append n=%d err=%v", n, err)
+	}
+	n, err = a.Append([]Record{rec("foodora", "B"), rec("glovo", "B")})
+	if err != nil || n != 1 {
+		t.Fatalf("append2 n=%d err=%v", n, err)
+	}
+
+	b, err := Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if b.Len() != 3 || !b.Has("glovo:B") || b.Has("glovo:A") {
+		t.Fatalf("unexpected index: len=%d", b.Len())
+	}
+	orders, err := b.Orders()
+	if err != nil {
+		t.Fatalf("Orders: %v", err)
+	}
+	if len(orders) != 3 || string(orders[0].Raw) != `{"id":"A"}` {
+		t.Fatalf("unexpected orders: %#v", orders)
+	}
+}
+
+func TestArchive_RebuildsIndexAndDropsTornTail(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := a.Append([]Record{rec("deliveroo", "1")}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	// Simulate a crash: index gone, half-written line at the end.
+	if err := os.Remove(filepath.Join(dir, indexFile)); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, segmentName(1)), os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("open segment: %v", err)
+	}
+	_, _ = f.WriteString(`{"provider":"deliveroo","id":"2"`)
+	_ = f.Close()
+
+	b, err := Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if b.Len() != 1 || !b.Has("deliveroo:1") {
+		t.Fatalf("unexpected len=%d", b.Len())
+	}
+	if _, err := b.Append([]Record{rec("deliveroo", "2")}); err != nil {
+		t.Fatalf("append after repair: %v", err)
+	}
+	c, err := Open(dir)
+	if err != nil || c.Len() != 2 {
+		t.Fatalf("reopen2: len=%d err=%v", c.Len(), err)
+	}
+}
+
+func TestArchive_OpenRebuildsUnderLock(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := a.Append([]Record{rec("glovo", "1")}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, indexFile)); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	// Another process is appending: the rebuild must wait for it.
+	unlock, err := config.Lock(filepath.Join(dir, indexFile), true)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	done := make(chan *Archive)
+	go func() {
+		b, err := Open(dir)
+		if err != nil {
+			t.Errorf("reopen: %v", err)
+		}
+		done <- b
+	}()
+	select {
+	case <-done:
+		unlock()
+		t.Fatalf("Open rebuilt the index while the archive was locked")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, err := os.Stat(filepath.Join(dir, indexFile)); !os.IsNotExist(err) {
+		t.Fatalf("index written under another process's lock: %v", err)
+	}
+	unlock()
+	if b := <-done; b == nil || b.Len() != 1 || !b.Has("glovo:1") {
+		t.Fatalf("unexpected archive after rebuild")
+	}
+}
+
+func TestArchive_AppendSeesOtherProcesses(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	b, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := a.Append([]Record{rec("foodora", "A")}); err != nil {
+		t.Fatalf("append a: %v", err)
+	}
+	// b opened before a appended; its stale index must not drop A or add it twice.
+	if n, err := b.Append([]Record{rec("foodora", "A"), rec("foodora", "B")}); err != nil || n != 1 {
+		t.Fatalf("append b n=%d err=%v", n, err)
+	}
+
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if c.Len() != 2 || !c.Has("foodora:A") || !c.Has("foodora:B") {
+		t.Fatalf("unexpected index: len=%d", c.Len())
+	}
+	if orders, err := c.Orders(); err != nil || len(orders) != 2 {
+		t.Fatalf("orders=%d err=%v", len(orders), err)
+	}
+}
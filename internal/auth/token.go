@@ -0,0 +1,116 @@
+// Package auth holds the bearer-token plumbing shared by the provider
+// clients: where a token comes from and how it is renewed.
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoRefresh is returned by sources that cannot renew their token.
+var ErrNoRefresh = errors.New("token cannot be refreshed")
+
+// Token is an access token plus what is needed to renew it.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresAt is zero when unknown; such tokens are used until rejected.
+	ExpiresAt time.Time
+}
+
+// Source supplies the bearer token for each request.
+type Source interface {
+	// Token returns the token to send, renewing it first if it is expired.
+	Token(ctx context.Context) (string, error)
+	// Refresh is called after the server rejected the token. It returns a
+	// different token, or an error when there is none.
+	Refresh(ctx context.Context, rejected string) (string, error)
+}
+
+// Static is a Source for a fixed token (pasted sessions, env vars).
+type Static string
+
+func (s Static) Token(context.Context) (string, error) { return string(s), nil }
+
+func (s Static) Refresh(context.Context, string) (string, error) { return "", ErrNoRefresh }
+
+// RefreshFunc exchanges the current token for a new one. Results without a
+// refresh token keep the current refresh token.
+type RefreshFunc func(ctx context.Context, current Token) (Token, error)
+
+// expiryMargin renews tokens this long before they expire, matching
+// config.FoodoraConfig.TokenLikelyExpired.
+const expiryMargin = 30 * time.Second
+
+// Refresher is a Source that renews its token with a RefreshFunc. It is safe
+// for concurrent use: refreshes are serialized, and callers that saw the same
+// rejected token share the one refresh.
+type Refresher struct {
+	mu      sync.Mutex
+	tok     Token
+	refresh RefreshFunc
+	onNew   func(Token)
+	now     func() time.Time
+}
+
+// NewRefresher returns a Source starting at tok. onNew (optional) receives
+// every renewed token, e.g. to persist it; it runs under the refresh lock, so
+// it must not call back into the Refresher.
+func NewRefresher(tok Token, refresh RefreshFunc, onNew func(Token)) *Refresher {
+	return &Refresher{tok: tok, refresh: refresh, onNew: onNew, now: time.Now}
+}
+
+// Current returns the token the Refresher holds right now.
+func (r *Refresher) Current() Token {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tok
+}
+
+func (r *Refresher) Token(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tok.AccessToken == "" || (!r.tok.ExpiresAt.IsZero() && !r.tok.ExpiresAt.After(r.now().Add(expiryMargin))) {
+		if err := r.renew(ctx); err != nil {
+			return "", err
+		}
+	}
+	return r.tok.AccessToken, nil
+}
+
+func (r *Refresher) Refresh(ctx context.Context, rejected string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tok.AccessToken != "" && r.tok.AccessToken != rejected {
+		// Someone else refreshed while this request was in flight.
+		return r.tok.AccessToken, nil
+	}
+	if err := r.renew(ctx); err != nil {
+		return "", err
+	}
+	return r.tok.AccessToken, nil
+}
+
+func (r *Refresher) renew(ctx context.Context) error {
+	if r.refresh == nil || strings.TrimSpace(r.tok.RefreshToken) == "" {
+		return ErrNoRefresh
+	}
+	tok, err := r.refresh(ctx, r.tok)
+	if err != nil {
+		return err
+	}
+	if tok.AccessToken == "" {
+		return errors.New("refresh returned no access token")
+	}
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = r.tok.RefreshToken
+	}
+	r.tok = tok
+	if r.onNew != nil {
+		r.onNew(tok)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRefresher_RefreshesOnceForConcurrentRejections(t *testing.T) {
+	var calls atomic.Int32
+	var saved []Token
+	r := NewRefresher(Token{AccessToken: "a1", RefreshToken: "r1"}, func(ctx context.Context, cur Token) (Token, error) {
+		calls.Add(1)
+		if cur.RefreshToken != "r1" {
+			t.Errorf("refresh token %q", cur.RefreshToken)
+		}
+		time.Sleep(10 * time.Millisecond)
+		return Token{AccessToken: "a2"}, nil
+	}, func(tok Token) { saved = append(saved, tok) })
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tok, err := r.Refresh(context.Background(), "a1")
+			if err != nil || tok != "a2" {
+				t.Errorf("Refresh = %q, %v", tok, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("refresh calls = %d, want 1", calls.Load())
+	}
+	if len(saved) != 1 || saved[0].AccessToken != "a2" || saved[0].RefreshToken != "r1" {
+		t.Fatalf("saved = %+v", saved)
+	}
+}
+
+func TestRefresher_TokenRenewsExpired(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRefresher(Token{AccessToken: "old", RefreshToken: "r", ExpiresAt: now.Add(10 * time.Second)}, func(ctx context.Context, cur Token) (Token, error) {
+		return Token{AccessToken: "new", RefreshToken: "r2", ExpiresAt: now.Add(time.Hour)}, nil
+	}, nil)
+	r.now = func() time.Time { return now }
+
+	tok, err := r.Token(context.Background())
+	if err != nil || tok != "new" {
+		t.Fatalf("Token = %q, %v", tok, err)
+	}
+	if got := r.Current(); got.RefreshToken != "r2" {
+		t.Fatalf("Current = %+v", got)
+	}
+	// Valid now; no second refresh.
+	r.refresh = func(context.Context, Token) (Token, error) { return Token{}, errors.New("unexpected refresh") }
+	if tok, err := r.Token(context.Background()); err != nil || tok != "new" {
+		t.Fatalf("Token = %q, %v", tok, err)
+	}
+}
+
+func TestRefresher_NoRefreshToken(t *testing.T) {
+	r := NewRefresher(Token{AccessToken: "a"}, func(context.Context, Token) (Token, error) {
+		t.Fatal("unexpected refresh")
+		return Token{}, nil
+	}, nil)
+	if _, err := r.Refresh(context.Background(), "a"); !errors.Is(err, ErrNoRefresh) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := Static("x").Refresh(context.Background(), "x"); !errors.Is(err, ErrNoRefresh) {
+		t.Fatalf("static err = %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+// Package cassette records HTTP exchanges to a directory and replays them,
+// for offline debugging and regression fixtures. Secrets are redacted before
+// anything is written (see internal/redact).
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/steipete/ordercli/internal/redact"
+)
+
+// Interaction is one recorded request/response pair, stored as
+// <dir>/<seq>-<method>-<host>.json.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitzero"`
+}
+
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitzero"`
+}
+
+// Body is text when it is valid UTF-8, else base64.
+type Body struct {
+	Text   string `json:"text,omitempty"`
+	Base64 string `json:"base64,omitempty"`
+}
+
+func newBody(b []byte) Body {
+	if utf8.Valid(b) {
+		return Body{Text: redact.BodyKeepTypes(b)}
+	}
+	return Body{Base64: base64.StdEncoding.EncodeToString(b)}
+}
+
+func (b Body) bytes() ([]byte, error) {
+	if b.Base64 != "" {
+		return base64.StdEncoding.DecodeString(b.Base64)
+	}
+	return []byte(b.Text), nil
+}
+
+// key is what replay matches on: the method and the redacted URL.
+func key(method, rawURL string) string {
+	return strings.ToUpper(method) + " " + redact.URL(rawURL)
+}
+
+// Recorder is an http.RoundTripper that saves every exchange it forwards.
+type Recorder struct {
+	base http.RoundTripper
+	*tape
+}
+
+// tape is the directory a Recorder and its With copies number files in.
+type tape struct {
+	dir string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewRecorder records into dir (created if missing), continuing after any
+// interactions already there.
+func NewRecorder(dir string, base http.RoundTripper) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	existing, err := files(dir)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	r := &Recorder{base: base, tape: &tape{dir: dir}}
+	for _, name := range existing {
+		r.seq = max(r.seq, seqOf(name))
+	}
+	return r, nil
+}
+
+// With returns a Recorder forwarding to base that records into the same
+// directory, continuing r's numbering.
+func (r *Recorder) With(base http.RoundTripper) *Recorder {
+	return &Recorder{base: base, tape: r.tape}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = b
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	res, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	in := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    redact.URL(req.URL.String()),
+			Header: redact.Header(req.Header),
+			Body:   newBody(reqBody),
+		},
+		Response: Response{
+			Status: res.StatusCode,
+			Header: redact.Header(res.Header),
+			Body:   newBody(resBody),
+		},
+	}
+	if err := r.save(in, req.URL.Hostname()); err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	return res, nil
+}
+
+func (r *tape) save(in Interaction, host string) error {
+	b, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	name := fmt.Sprintf("%04d-%s-%s.json", r.seq, strings.ToLower(in.Request.Method), host)
+	return os.WriteFile(filepath.Join(r.dir, name), append(b, '\n'), 0o600)
+}
+
+// Replayer is an http.RoundTripper that answers from a recorded directory
+// without touching the network. Requests match on method and URL; repeated
+// requests get the recordings in order, and the last one again once they
+// run out (so polling loops keep working).
+type Replayer struct {
+	mu     sync.Mutex
+	byKey  map[string][]Interaction
+	served map[string]int
+}
+
+// ErrNoInteraction is returned for requests the cassette has no recording of.
+var ErrNoInteraction = errors.New("cassette: no recorded interaction")
+
+func NewReplayer(dir string) (*Replayer, error) {
+	names, err := files(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("cassette: no recordings in %s", dir)
+	}
+	r := &Replayer{byKey: map[string][]Interaction{}, served: map[string]int{}}
+	for _, name := range names {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var in Interaction
+		if err := json.Unmarshal(b, &in); err != nil {
+			return nil, fmt.Errorf("cassette %s: %w", name, err)
+		}
+		k := key(in.Request.Method, in.Request.URL)
+		r.byKey[k] = append(r.byKey[k], in)
+	}
+	return r, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	k := key(req.Method, req.URL.String())
+	r.mu.Lock()
+	list := r.byKey[k]
+	i := r.served[k]
+	if i < len(list) {
+		r.served[k] = i + 1
+	} else {
+		i = len(list) - 1
+	}
+	r.mu.Unlock()
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoInteraction, k)
+	}
+
+	in := list[i].Response
+	body, err := in.Body.bytes()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %s: %w", k, err)
+	}
+	header := in.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Del("Content-Length")
+	header.Del("Content-Encoding")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// files lists the interaction files in dir in recording order.
+func files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	var out []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			out = append(out, e.Name())
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return seqOf(out[i]) < seqOf(out[j]) })
+	return out, nil
+}
+
+// seqOf is the number a file name starts with (0 without one).
+func seqOf(name string) int {
+	prefix, _, _ := strings.Cut(name, "-")
+	n, _ := strconv.Atoi(prefix)
+	return n
+}
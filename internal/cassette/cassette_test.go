@@ -0,0 +1,134 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	t.Parallel()
+
+	var n int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		switch r.URL.Path {
+		case "/oauth2/token":
+			_, _ = io.WriteString(w, `{"access_token":"tokSECRET","refresh_token":"refSECRET","expires_in":3600}`)
+		default:
+			_, _ = io.WriteString(w, `{"n":`+strconv.Itoa(n)+`}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := filepath.Join(t.TempDir(), "cassette")
+	rec, err := NewRecorder(dir, http.DefaultTransport)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	c := &http.Client{Transport: rec}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/oauth2/token", strings.NewReader("grant_type=password&username=me&password=pwSECRET&client_secret=csSECRET"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Mfa-Token", "mfaSECRET")
+	res, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "tokSECRET") {
+		t.Fatalf("recorder changed the live response: %s", body)
+	}
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/orders?key=AIzaSECRET", nil)
+		req.Header.Set("Authorization", "Bearer tokSECRET")
+		res, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		res.Body.Close()
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Fatalf("recorded %d files", len(entries))
+	}
+	for _, e := range entries {
+		b, _ := os.ReadFile(filepath.Join(dir, e.Name()))
+		if strings.Contains(string(b), "SECRET") || strings.Contains(string(b), "session=") {
+			t.Fatalf("%s leaks a secret:\n%s", e.Name(), b)
+		}
+	}
+	if !strings.HasPrefix(entries[0].Name(), "0001-post-127.0.0.1") {
+		t.Fatalf("name: %s", entries[0].Name())
+	}
+
+	rp, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatalf("NewReplayer: %v", err)
+	}
+	c = &http.Client{Transport: rp}
+	srv.Close() // replay must not need the network
+
+	get := func(path string) string {
+		t.Helper()
+		res, err := c.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("Get %s: %v", path, err)
+		}
+		defer res.Body.Close()
+		b, _ := io.ReadAll(res.Body)
+		return string(b)
+	}
+	// A different API key still matches: query secrets are compared redacted.
+	if got := get("/orders?key=other"); got != `{"n":2}` {
+		t.Fatalf("first replay: %s", got)
+	}
+	if got := get("/orders?key=other"); got != `{"n":3}` {
+		t.Fatalf("second replay: %s", got)
+	}
+	if got := get("/orders?key=other"); got != `{"n":3}` {
+		t.Fatalf("exhausted replay repeats the last: %s", got)
+	}
+	res, err = c.Post(srv.URL+"/oauth2/token", "application/x-www-form-urlencoded", strings.NewReader("x=1"))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 200 || !strings.Contains(string(body), `"access_token":"***"`) || !strings.Contains(string(body), `"expires_in":3600`) {
+		t.Fatalf("token replay: %d %s", res.StatusCode, body)
+	}
+
+	if _, err := c.Get(srv.URL + "/missing"); !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestRecorderContinuesNumbering(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "0007-get-example.com.json"), []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := NewRecorder(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.seq != 7 {
+		t.Fatalf("seq=%d", rec.seq)
+	}
+	if _, err := NewReplayer(t.TempDir()); err == nil || !strings.Contains(err.Error(), "no recordings") {
+		t.Fatalf("err=%v", err)
+	}
+}
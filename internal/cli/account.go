@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newAccountCmd(st *state) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "account",
+		Short: "Manage named accounts per provider (see also --account)",
+	}
+	cmd.AddCommand(newAccountAddCmd(st))
+	cmd.AddCommand(newAccountListCmd(st))
+	cmd.AddCommand(newAccountUseCmd(st))
+	cmd.AddCommand(newAccountRemoveCmd(st))
+	return cmd
+}
+
+func newAccountAddCmd(st *state) *cobra.Command {
+	var country string
+	var clientID string
+	var use bool
+
+	cmd := &cobra.Command{
+		Use:   "add <provider> <name>",
+		Short: "Add a named account (own tokens, device id, cookies and country)",
+		Example: "  ordercli account add foodora work --country HU --client-id corp_android\n" +
+			"  ordercli --account work foodora login --email you@example.com --password-stdin",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prov, name := strings.ToLower(args[0]), strings.TrimSpace(args[1])
+			if country != "" && prov != "foodora" {
+				return errors.New("--country is only supported for foodora")
+			}
+			if clientID != "" && prov != "foodora" {
+				return errors.New("--client-id is only supported for foodora")
+			}
+			var preset countryPreset
+			if country != "" {
+				p, ok := findPreset(strings.ToUpper(country))
+				if !ok {
+					return fmt.Errorf("unknown country preset %q (see `ordercli foodora countries`)", country)
+				}
+				preset = p
+			}
+			if err := st.cfg.AddAccount(prov, name); err != nil {
+				return err
+			}
+			if prov == "foodora" {
+				cfg := st.cfg.FoodoraAccount(name)
+				if preset.Code != "" {
+					cfg.BaseURL = preset.BaseURL
+					cfg.GlobalEntityID = preset.GlobalEntityID
+					cfg.TargetCountryISO = preset.TargetISO
+				}
+				cfg.OAuthClientID = clientID
+			}
+			if use {
+				if err := st.cfg.UseAccount(prov, name); err != nil {
+					return err
+				}
+			}
+			st.markDirty()
+			fmt.Fprintf(cmd.OutOrStdout(), "added %s account %s\n", prov, name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&country, "country", "", "foodora country preset (HU, SK, DL, AT, ...)")
+	cmd.Flags().StringVar(&clientID, "client-id", "", "foodora OAuth client_id for this account (e.g. corp_android)")
+	cmd.Flags().BoolVar(&use, "use", false, "make it the current account")
+	return cmd
+}
+
+func newAccountListCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list [provider]",
+		Short: "List accounts (* marks the current one)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries := providerRegistry
+			if len(args) == 1 {
+				p, ok := findProvider(args[0])
+				if !ok {
+					return fmt.Errorf("unknown provider %q (known: %s)", args[0], strings.Join(providerNames(), ", "))
+				}
+				entries = []providerEntry{p}
+			}
+
+			r := newRecords("provider", "account", "current", "logged_in", "country")
+			type row struct {
+				provider, account, country string
+				current, loggedIn          bool
+			}
+			var rows []row
+			for _, p := range entries {
+				current := st.accountFor(p.name)
+				for _, name := range st.cfg.AccountNames(p.name) {
+					_ = st.inAccount(name, func() error {
+						rows = append(rows, row{provider: p.name, account: name, country: accountCountry(st, p.name), current: name == current, loggedIn: p.configured(st)})
+						return nil
+					})
+				}
+			}
+			for _, rw := range rows {
+				r.add(rw.provider, rw.account, rw.current, rw.loggedIn, rw.country)
+			}
+			return st.renderList(cmd.OutOrStdout(), r, func() error {
+				for _, rw := range rows {
+					mark := " "
+					if rw.current {
+						mark = "*"
+					}
+					status := "logged out"
+					if rw.loggedIn {
+						status = "logged in"
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "%s %s\t%s\t%s\t%s\n", mark, rw.provider, rw.account, status, rw.country)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+func accountCountry(st *state, provider string) string {
+	switch provider {
+	case "foodora":
+		return st.foodora().TargetCountryISO
+	case "deliveroo":
+		return st.deliveroo().Market
+	case "glovo":
+		return st.glovo().CountryCode
+	}
+	return ""
+}
+
+func newAccountUseCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <provider> <name>",
+		Short: "Make an account the current one for a provider",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prov := strings.ToLower(args[0])
+			if err := st.cfg.UseAccount(prov, args[1]); err != nil {
+				return err
+			}
+			st.markDirty()
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: using account %s\n", prov, args[1])
+			return nil
+		},
+	}
+}
+
+func newAccountRemoveCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <provider> <name>",
+		Short: "Remove a named account and its tokens",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prov := strings.ToLower(args[0])
+			if err := st.cfg.RemoveAccount(prov, args[1]); err != nil {
+				return err
+			}
+			st.markDirty()
+			fmt.Fprintf(cmd.OutOrStdout(), "removed %s account %s\n", prov, args[1])
+			return nil
+		},
+	}
+}
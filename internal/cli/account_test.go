@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/ordercli/internal/config"
+)
+
+func TestAccounts_CrossAccountHistory(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+
+	glovoServer := func(id, vendor string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"orders":[{"orderId":` + id + `,"content":{"title":"` + vendor + `"},"layoutType":"INACTIVE_ORDER"}]}`))
+		}))
+	}
+	personal := glovoServer("7", "Home Place")
+	defer personal.Close()
+	work := glovoServer("8", "Office Place")
+	defer work.Close()
+
+	cfg := config.New()
+	cfg.Glovo().BaseURL = personal.URL
+	cfg.Glovo().AccessToken = "personal-token"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"account", "add", "glovo", "work"}, ""); err != nil {
+		t.Fatalf("account add: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"account", "add", "glovo", "work"}, ""); err == nil {
+		t.Fatalf("expected duplicate account error")
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	cfg.GlovoAccount("work").BaseURL = work.URL
+	cfg.GlovoAccount("work").AccessToken = "work-token"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	// Cross-provider views visit every account.
+	out, errOut, err := runCLI(cfgPath, []string{"history", "--provider", "glovo"}, "")
+	if err != nil {
+		t.Fatalf("history: %v %s", err, errOut)
+	}
+	if !strings.Contains(out, "glovo\t7\tHome Place") || !strings.Contains(out, "glovo/work\t8\tOffice Place") {
+		t.Fatalf("unexpected history: %q", out)
+	}
+	out, _, err = runCLI(cfgPath, []string{"history", "--provider", "glovo", "--json"}, "")
+	if err != nil {
+		t.Fatalf("history json: %v", err)
+	}
+	if !strings.Contains(out, `"account": "work"`) {
+		t.Fatalf("expected account in json: %s", out)
+	}
+
+	// --account narrows everything to one account.
+	out, _, err = runCLI(cfgPath, []string{"--account", "work", "history"}, "")
+	if err != nil {
+		t.Fatalf("history --account: %v", err)
+	}
+	if strings.Contains(out, "Home Place") || !strings.Contains(out, "Office Place") {
+		t.Fatalf("unexpected --account history: %q", out)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"--account", "nope", "history"}, ""); err == nil || !strings.Contains(err.Error(), "unknown account") {
+		t.Fatalf("expected unknown account error, got %v", err)
+	}
+
+	// `account use` switches provider commands.
+	if _, _, err := runCLI(cfgPath, []string{"account", "use", "glovo", "work"}, ""); err != nil {
+		t.Fatalf("account use: %v", err)
+	}
+	out, _, err = runCLI(cfgPath, []string{"glovo", "history"}, "")
+	if err != nil {
+		t.Fatalf("glovo history: %v", err)
+	}
+	if strings.Contains(out, "Home Place") || !strings.Contains(out, "Office Place") {
+		t.Fatalf("unexpected glovo history: %q", out)
+	}
+	out, _, err = runCLI(cfgPath, []string{"account", "list", "glovo"}, "")
+	if err != nil {
+		t.Fatalf("account list: %v", err)
+	}
+	if !strings.Contains(out, "  glovo\tdefault\tlogged in") || !strings.Contains(out, "* glovo\twork\tlogged in") {
+		t.Fatalf("unexpected account list: %q", out)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"account", "remove", "glovo", "default"}, ""); err == nil {
+		t.Fatalf("expected error removing default account")
+	}
+	if _, _, err := runCLI(cfgPath, []string{"account", "remove", "glovo", "work"}, ""); err != nil {
+		t.Fatalf("account remove: %v", err)
+	}
+	cfg, err = config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.Accounts) != 0 || cfg.ActiveAccount("glovo") != config.DefaultAccount {
+		t.Fatalf("expected account gone: %#v %#v", cfg.Accounts, cfg.CurrentAccount)
+	}
+}
+
+func TestAccounts_FoodoraPresetAndDeviceID(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	if _, _, err := runCLI(cfgPath, []string{"account", "add", "foodora", "work", "--country", "HU", "--client-id", "corp_android"}, ""); err != nil {
+		t.Fatalf("account add: %v", err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	w := cfg.FoodoraAccount("work")
+	if w.TargetCountryISO != "HU" || w.OAuthClientID != "corp_android" || w.DeviceID == "" {
+		t.Fatalf("unexpected work account: %#v", w)
+	}
+	if w.DeviceID == cfg.Foodora().DeviceID {
+		t.Fatalf("expected separate device ids")
+	}
+}
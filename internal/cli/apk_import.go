@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/config"
+)
+
+func newAPKImportCmd(st *state) *cobra.Command {
+	var name string
+	var use bool
+
+	cmd := &cobra.Command{
+		Use:   "apk-import <file.apk|.xapk|.apkm>",
+		Short: "Import Firebase config and app headers from an Android app build",
+		Long: "Read the Firebase API key, project and app ID, the signing certificate SHA1 and\n" +
+			"the version from an APK (or the base APK of an XAPK/APKM bundle) and save them as\n" +
+			"an app profile. Select it with --use or `foodora config set --app-profile`; it then\n" +
+			"replaces the built-in HU/AT values for the client secret fetch and app headers.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info, err := inspectAPK(args[0])
+			if err != nil {
+				return err
+			}
+			if missing := info.Missing(); len(missing) > 0 {
+				return fmt.Errorf("%s has no complete Firebase config (missing %s)", filepath.Base(args[0]), strings.Join(missing, ", "))
+			}
+			if name == "" {
+				name = info.PackageName
+			}
+			if name == "" {
+				return fmt.Errorf("%s has no package name; pass --name", filepath.Base(args[0]))
+			}
+
+			p := &config.AppProfile{
+				PackageName:        info.PackageName,
+				VersionName:        info.VersionName,
+				VersionCode:        info.VersionCode,
+				FirebaseAPIKey:     info.APIKey,
+				FirebaseProjectID:  info.ProjectID,
+				FirebaseProjectNum: info.ProjectNum,
+				FirebaseAppID:      info.AppID,
+				CertSHA1:           info.CertSHA1,
+				Source:             filepath.Base(args[0]),
+				ImportedAt:         time.Now().UTC(),
+			}
+			if st.cfg.AppProfiles == nil {
+				st.cfg.AppProfiles = map[string]*config.AppProfile{}
+			}
+			st.cfg.AppProfiles[name] = p
+			if use {
+				st.ensureFoodora().AppProfile = name
+			}
+			st.markDirty()
+
+			out := cmd.OutOrStdout()
+			r := newRecords("name", "package_name", "version_name", "version_code", "user_agent", "firebase_project_id", "firebase_project_number", "firebase_app_id", "cert_sha1", "selected")
+			r.add(name, p.PackageName, p.VersionName, p.VersionCode, p.UserAgent(), p.FirebaseProjectID, p.FirebaseProjectNum, p.FirebaseAppID, p.CertSHA1, st.foodora().AppProfile == name)
+			return st.renderDetail(out, r, func() error {
+				fmt.Fprintf(out, "imported app profile %q\n", name)
+				fmt.Fprintf(out, "package_name=%s\n", p.PackageName)
+				fmt.Fprintf(out, "version=%s (%d)\n", p.VersionName, p.VersionCode)
+				fmt.Fprintf(out, "firebase_project=%s (%s)\n", p.FirebaseProjectID, p.FirebaseProjectNum)
+				fmt.Fprintf(out, "firebase_app_id=%s\n", p.FirebaseAppID)
+				fmt.Fprintf(out, "cert_sha1=%s\n", p.CertSHA1)
+				if !use {
+					fmt.Fprintf(out, "select it with `ordercli foodora config set --app-profile %s`\n", name)
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "profile name (default: the package name)")
+	cmd.Flags().BoolVar(&use, "use", false, "select the profile for the current foodora account")
+	return cmd
+}
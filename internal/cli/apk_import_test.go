@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/ordercli/internal/apk"
+	"github.com/steipete/ordercli/internal/config"
+)
+
+func stubInspectAPK(t *testing.T, info apk.Info) {
+	t.Helper()
+	old := inspectAPK
+	inspectAPK = func(string) (apk.Info, error) { return info, nil }
+	t.Cleanup(func() { inspectAPK = old })
+}
+
+func TestAPKImport_SavesAndSelectsProfile(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	stubInspectAPK(t, apk.Info{
+		PackageName: "pl.pyszne",
+		VersionCode: 251000200,
+		VersionName: "25.10.0",
+		APIKey:      "AIzaKey",
+		ProjectID:   "pyszne-1",
+		ProjectNum:  "111",
+		AppID:       "1:111:android:abc",
+		CertSHA1:    "AABB",
+	})
+
+	out, _, err := runCLI(cfgPath, []string{"--output", "json", "foodora", "apk-import", "/tmp/pyszne.xapk"}, "")
+	if err != nil {
+		t.Fatalf("apk-import: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("json: %v\n%s", err, out)
+	}
+	if got["name"] != "pl.pyszne" || got["user_agent"] != "Android-app-25.10.0(251000200)" || got["selected"] != false {
+		t.Fatalf("unexpected: %v", got)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "config", "set", "--app-profile", "nope"}, ""); err == nil || !strings.Contains(err.Error(), "unknown app profile") {
+		t.Fatalf("err=%v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "config", "set", "--app-profile", "pl.pyszne"}, ""); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	out, _, err = runCLI(cfgPath, []string{"foodora", "config", "show"}, "")
+	if err != nil || !strings.Contains(out, "app_profile=pl.pyszne\n") {
+		t.Fatalf("config show: %v\n%s", err, out)
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	st := &state{cfg: cfg}
+	if h := st.appHeaders(); h.AppName != "pl.pyszne" || h.UserAgent != "Android-app-25.10.0(251000200)" {
+		t.Fatalf("headers: %#v", h)
+	}
+	if fb := st.firebaseConfig(st.foodora()); fb.APIKey != "AIzaKey" || fb.ProjectNum != "111" || fb.AppID != "1:111:android:abc" || fb.CertSHA1 != "AABB" || fb.PackageName != "pl.pyszne" {
+		t.Fatalf("firebase: %#v", fb)
+	}
+
+	// Clearing falls back to the built-in values.
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "config", "set", "--app-profile", ""}, ""); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	cfg, _ = config.Load(cfgPath)
+	if p := cfg.FoodoraAccount(config.DefaultAccount).AppProfile; p != "" {
+		t.Fatalf("app_profile=%q", p)
+	}
+}
+
+func TestAPKImport_IncompleteFirebaseConfig(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	stubInspectAPK(t, apk.Info{PackageName: "x.y", APIKey: "k"})
+	_, _, err := runCLI(cfgPath, []string{"foodora", "apk-import", "x.apk"}, "")
+	if err == nil || !strings.Contains(err.Error(), "missing project_id, gcm_defaultSenderId, google_app_id") {
+		t.Fatalf("err=%v", err)
+	}
+}
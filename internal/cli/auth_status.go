@@ -0,0 +1,351 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// Auth status levels, from best to worst.
+const (
+	authNone   = "none"
+	authOK     = "ok"
+	authWarn   = "warn"
+	authBroken = "broken"
+)
+
+// authStatus is what `auth status` knows about one provider account.
+type authStatus struct {
+	Provider string
+	Account  string
+	// Source is where the token comes from: config or env ("" without one).
+	Source        string
+	ExpiresAt     time.Time
+	RefreshNeeded bool
+	ClientID      string
+	SecretSource  string
+	CookiesAt     map[string]time.Time // host -> captured at (zero if unknown)
+	PendingMfa    string
+	PendingMfaAt  time.Time
+	Check         string
+	Problems      []string
+	Warnings      []string
+}
+
+func (a *authStatus) problem(format string, args ...any) {
+	a.Problems = append(a.Problems, fmt.Sprintf(format, args...))
+}
+
+func (a *authStatus) warn(format string, args ...any) {
+	a.Warnings = append(a.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (a authStatus) level() string {
+	switch {
+	case len(a.Problems) > 0:
+		return authBroken
+	case len(a.Warnings) > 0:
+		return authWarn
+	case a.Source == "":
+		return authNone
+	default:
+		return authOK
+	}
+}
+
+// useToken records where token comes from and returns it with a secret
+// reference resolved ("" and a problem when that fails).
+func (a *authStatus) useToken(ctx context.Context, st *state, source, token string) string {
+	a.Source = source
+	if kind := secretRefKind(token); kind != "" {
+		a.Source += " (" + kind + ":)"
+	}
+	v, err := st.resolveSecret(ctx, token)
+	if err != nil {
+		a.problem("%v", err)
+		return ""
+	}
+	return v
+}
+
+// setExpiry takes exp, else the token's JWT expiry, and flags tokens that
+// expired or will within the deliveroo warning window unless they can be
+// refreshed.
+func (a *authStatus) setExpiry(exp time.Time, token string, now time.Time, canRefresh bool) {
+	if exp.IsZero() {
+		exp, _ = config.AccessTokenExpiresAt(token)
+	}
+	a.ExpiresAt = exp
+	if exp.IsZero() {
+		return
+	}
+	// Same margin as FoodoraConfig.TokenLikelyExpired.
+	a.RefreshNeeded = !exp.After(now.Add(30 * time.Second))
+	if canRefresh {
+		return
+	}
+	switch left := exp.Sub(now); {
+	case left <= 0:
+		a.problem("token expired and cannot be refreshed")
+	case left < deliverooExpiryWarning:
+		a.warn("token expires in %s and cannot be refreshed", left.Round(time.Minute))
+	}
+}
+
+func foodoraAuthStatus(ctx context.Context, st *state, now time.Time) authStatus {
+	cfg := st.foodora()
+	a := authStatus{ClientID: oauthClientID(cfg, "")}
+	if cfg.AccessToken != "" {
+		token := a.useToken(ctx, st, "config", cfg.AccessToken)
+		a.setExpiry(cfg.ExpiresAt, token, now, cfg.RefreshToken != "")
+	}
+	if sec, ok, err := st.localClientSecret(ctx, cfg, a.ClientID); err != nil {
+		a.SecretSource = "error"
+		a.problem("client secret: %v", err)
+	} else if ok {
+		a.SecretSource = sec.Source()
+	} else {
+		a.SecretSource = "remote"
+	}
+	if len(cfg.CookiesByHost) > 0 {
+		a.CookiesAt = map[string]time.Time{}
+		for host := range cfg.CookiesByHost {
+			a.CookiesAt[host] = cfg.CookiesSavedAt[host]
+		}
+	}
+	if cfg.PendingMfaToken != "" {
+		a.PendingMfa = cfg.PendingMfaChannel
+		if a.PendingMfa == "" {
+			a.PendingMfa = "unknown"
+		}
+		a.PendingMfaAt = cfg.PendingMfaCreatedAt
+		a.warn("login waiting for an MFA code (rerun `ordercli foodora login --otp ...`)")
+	}
+	return a
+}
+
+func deliverooAuthStatus(ctx context.Context, st *state, now time.Time) authStatus {
+	cfg := st.deliveroo()
+	var a authStatus
+	token, source := cfg.BearerToken, "config"
+	// Same precedence as newDeliverooClient: the env token wins.
+	if st.accountFor("deliveroo") == config.DefaultAccount {
+		if v := strings.TrimSpace(os.Getenv("DELIVEROO_BEARER_TOKEN")); v != "" {
+			token, source = v, "env"
+		}
+	}
+	if token == "" {
+		return a
+	}
+	token = a.useToken(ctx, st, source, token)
+	a.setExpiry(time.Time{}, token, now, false)
+	return a
+}
+
+func glovoAuthStatus(ctx context.Context, st *state, now time.Time) authStatus {
+	cfg := st.glovo()
+	var a authStatus
+	if !cfg.HasSession() {
+		return a
+	}
+	token := a.useToken(ctx, st, "config", cfg.AccessToken)
+	if cfg.AccessToken == "" {
+		a.RefreshNeeded = true
+		return a
+	}
+	a.setExpiry(cfg.ExpiresAt, token, now, cfg.CanRefresh())
+	return a
+}
+
+func newAuthCmd(st *state) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Inspect stored sessions across providers",
+	}
+	cmd.AddCommand(newAuthStatusCmd(st))
+	return cmd
+}
+
+func newAuthStatusCmd(st *state) *cobra.Command {
+	var check bool
+	var strict bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "status [provider...]",
+		Short: "Report session, expiry, secrets, cookies and pending MFA per provider and account",
+		Long: "Report session, expiry, secrets, cookies and pending MFA per provider and account.\n\n" +
+			"Exits non-zero when a session is broken (expired without a way to refresh, or a\n" +
+			"failed --check), so cron jobs can alert. Providers named as arguments must have a session.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries := providerRegistry
+			if len(args) > 0 {
+				entries = nil
+				for _, name := range args {
+					p, ok := findProvider(name)
+					if !ok {
+						return fmt.Errorf("unknown provider %q (known: %s)", name, strings.Join(providerNames(), ", "))
+					}
+					entries = append(entries, p)
+				}
+			}
+
+			now := time.Now()
+			var rows []authStatus
+			for _, p := range entries {
+				for _, account := range st.accountsFor(p.name) {
+					_ = st.inAccount(account, func() error {
+						a := p.authStatus(cmd.Context(), st, now)
+						a.Provider, a.Account = p.name, account
+						if a.Source == "" && len(args) > 0 {
+							a.problem("no session")
+						}
+						if check && a.Source != "" {
+							ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+							defer cancel()
+							if err := liveAuthCheck(ctx, st, p); err != nil {
+								a.Check = err.Error()
+								a.problem("live check failed")
+							} else {
+								a.Check = "ok"
+							}
+						}
+						rows = append(rows, a)
+						return nil
+					})
+				}
+			}
+
+			r := newRecords("provider", "account", "status", "session", "expires_at", "refresh_needed", "client_id", "secret_source", "cookies", "pending_mfa", "pending_mfa_since", "check", "problems", "warnings")
+			for _, a := range rows {
+				r.add(a.Provider, a.Account, a.level(), a.Source, a.ExpiresAt, a.RefreshNeeded, a.ClientID, a.SecretSource, cookieAges(a.CookiesAt, now), a.PendingMfa, a.PendingMfaAt, a.Check, a.Problems, a.Warnings)
+			}
+			out := cmd.OutOrStdout()
+			if err := st.renderList(out, r, func() error {
+				for _, a := range rows {
+					printAuthStatus(out, a, now)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			var failed int
+			for _, a := range rows {
+				if l := a.level(); l == authBroken || (strict && l == authWarn) {
+					failed++
+				}
+			}
+			if failed > 0 {
+				// Returning an error skips the post-run save; keep tokens the
+				// live checks refreshed.
+				if err := st.save(); err != nil {
+					return err
+				}
+				return fmt.Errorf("%d of %d sessions need attention", failed, len(rows))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&check, "check", false, "make one authenticated API call per session")
+	cmd.Flags().BoolVar(&strict, "strict", false, "also exit non-zero on warnings (token expiring soon, pending MFA)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 20*time.Second, "timeout per live check")
+	return cmd
+}
+
+// liveAuthCheck opens the provider (refreshing its token if due) and makes
+// the cheapest authenticated call it has.
+func liveAuthCheck(ctx context.Context, st *state, p providerEntry) error {
+	prov, err := p.open(ctx, st)
+	if err != nil {
+		return err
+	}
+	if p.caps.Profile {
+		_, err = prov.Profile(ctx)
+		return err
+	}
+	if p.caps.ActiveOrders {
+		_, err = prov.ActiveOrders(ctx)
+		return err
+	}
+	_, err = prov.History(ctx, provider.HistoryRequest{Limit: 1})
+	return err
+}
+
+// cookieAges renders "host=age" pairs sorted by host.
+func cookieAges(at map[string]time.Time, now time.Time) []string {
+	hosts := make([]string, 0, len(at))
+	for h := range at {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	out := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		age := "unknown"
+		if t := at[h]; !t.IsZero() {
+			age = formatAge(now.Sub(t))
+		}
+		out = append(out, h+"="+age)
+	}
+	return out
+}
+
+// formatAge prints d in its largest sensible unit (45m, 5h, 12d).
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func printAuthStatus(out io.Writer, a authStatus, now time.Time) {
+	fmt.Fprintf(out, "%s/%s: %s\n", a.Provider, a.Account, a.level())
+	if a.Source == "" {
+		fmt.Fprintln(out, "  session=none")
+	} else {
+		fmt.Fprintf(out, "  session=%s\n", a.Source)
+		if a.ExpiresAt.IsZero() {
+			fmt.Fprintln(out, "  expires_at=unknown")
+		} else if left := a.ExpiresAt.Sub(now); left > 0 {
+			fmt.Fprintf(out, "  expires_at=%s (in %s)\n", a.ExpiresAt.Local().Format(time.RFC3339), formatAge(left))
+		} else {
+			fmt.Fprintf(out, "  expires_at=%s (%s ago)\n", a.ExpiresAt.Local().Format(time.RFC3339), formatAge(-left))
+		}
+		fmt.Fprintf(out, "  refresh_needed=%t\n", a.RefreshNeeded)
+	}
+	if a.ClientID != "" {
+		fmt.Fprintf(out, "  client_id=%s\n", a.ClientID)
+		fmt.Fprintf(out, "  client_secret=%s\n", a.SecretSource)
+	}
+	for _, c := range cookieAges(a.CookiesAt, now) {
+		fmt.Fprintf(out, "  cookies %s\n", c)
+	}
+	if a.PendingMfa != "" {
+		since := "unknown"
+		if !a.PendingMfaAt.IsZero() {
+			since = formatAge(now.Sub(a.PendingMfaAt)) + " ago"
+		}
+		fmt.Fprintf(out, "  pending_mfa=%s (since %s)\n", a.PendingMfa, since)
+	}
+	if a.Check != "" {
+		fmt.Fprintf(out, "  check=%s\n", a.Check)
+	}
+	for _, p := range a.Problems {
+		fmt.Fprintf(out, "  problem: %s\n", p)
+	}
+	for _, w := range a.Warnings {
+		fmt.Fprintf(out, "  warning: %s\n", w)
+	}
+}
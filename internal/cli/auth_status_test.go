@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+)
+
+func TestAuthStatus(t *testing.T) {
+	t.Setenv("DELIVEROO_BEARER_TOKEN", "")
+	t.Setenv("FOODORA_CLIENT_SECRET", "")
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	now := time.Now()
+
+	cfg := config.New()
+	f := cfg.Foodora()
+	f.BaseURL = "https://hu.fd-api.com/api/v5/"
+	f.AccessToken = "at"
+	f.RefreshToken = "rt"
+	f.ExpiresAt = now.Add(-time.Hour)
+	f.OAuthClientID = "android"
+	f.ClientSecret = "sec"
+	f.SetCookies("hu.fd-api.com", "cf_clearance=x", now.Add(-72*time.Hour))
+	f.PendingMfaToken = "mfa"
+	f.PendingMfaChannel = "sms"
+	f.PendingMfaCreatedAt = now.Add(-5 * time.Minute)
+	cfg.Deliveroo().BearerToken = deliverooJWT(now.Add(-time.Minute))
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out, _, err := runCLI(cfgPath, []string{"auth", "status"}, "")
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 sessions need attention") {
+		t.Fatalf("expected broken deliveroo session, got %v", err)
+	}
+	for _, want := range []string{
+		"foodora/default: warn",
+		"refresh_needed=true",
+		"client_id=android",
+		"client_secret=config",
+		"cookies hu.fd-api.com=3d",
+		"pending_mfa=sms (since 5m ago)",
+		"deliveroo/default: broken",
+		"problem: token expired and cannot be refreshed",
+		"glovo/default: none",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	// --strict also fails on the pending MFA warning.
+	if _, _, err := runCLI(cfgPath, []string{"auth", "status", "foodora"}, ""); err != nil {
+		t.Fatalf("foodora only: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"auth", "status", "--strict", "foodora"}, ""); err == nil {
+		t.Fatalf("expected --strict failure")
+	}
+	// Named providers must have a session.
+	if _, _, err := runCLI(cfgPath, []string{"auth", "status", "glovo"}, ""); err == nil {
+		t.Fatalf("expected failure for glovo without session")
+	}
+
+	out, _, _ = runCLI(cfgPath, []string{"-o", "json", "auth", "status", "foodora"}, "")
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatalf("json: %v\n%s", err, out)
+	}
+	if len(rows) != 1 || rows[0]["status"] != "warn" || rows[0]["secret_source"] != "config" || rows[0]["pending_mfa"] != "sms" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+}
+
+func TestAuthStatus_Check(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	ok := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":1,"name":"Test User"}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.New()
+	g := cfg.Glovo()
+	g.BaseURL = srv.URL
+	g.AccessToken = "tok"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out, _, err := runCLI(cfgPath, []string{"auth", "status", "--check", "glovo"}, "")
+	if err != nil || !strings.Contains(out, "glovo/default: ok") || !strings.Contains(out, "check=ok") {
+		t.Fatalf("check: %v\n%s", err, out)
+	}
+	ok = false
+	out, _, err = runCLI(cfgPath, []string{"auth", "status", "--check", "glovo"}, "")
+	if err == nil || !strings.Contains(out, "problem: live check failed") || !strings.Contains(out, "HTTP 401") {
+		t.Fatalf("expected failed check: %v\n%s", err, out)
+	}
+}
+
+func TestAuthStatus_CheckFailureKeepsRefreshedToken(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/oauth/refresh" {
+			_, _ = w.Write([]byte(`{"accessToken":"at-2","refreshToken":"rt-2","expiresIn":3600}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	cfg := config.New()
+	g := cfg.Glovo()
+	g.BaseURL = srv.URL
+	g.AccessToken = "at-1"
+	g.RefreshToken = "rt-1"
+	g.ExpiresAt = time.Now().Add(-time.Hour)
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out, _, err := runCLI(cfgPath, []string{"auth", "status", "--check", "glovo"}, "")
+	if err == nil || !strings.Contains(out, "problem: live check failed") {
+		t.Fatalf("expected failed check: %v\n%s", err, out)
+	}
+	cfg, err = config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if g := cfg.Glovo(); g.AccessToken != "at-2" || g.RefreshToken != "rt-2" {
+		t.Fatalf("refreshed token not saved: %+v", g)
+	}
+}
+
+func TestAuthStatus_CheckKeepsSecretReferences(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv("ORDERCLI_TEST_GLOVO_REFRESH", "rt-1")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/oauth/refresh" {
+			_, _ = w.Write([]byte(`{"accessToken":"at-2","refreshToken":"rt-2","expiresIn":3600}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer at-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1,"name":"Test User"}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.New()
+	g := cfg.Glovo()
+	g.BaseURL = srv.URL
+	g.AccessToken = "at-1"
+	g.RefreshToken = "env:ORDERCLI_TEST_GLOVO_REFRESH"
+	g.ExpiresAt = time.Now().Add(-time.Hour)
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out, errOut, err := runCLI(cfgPath, []string{"auth", "status", "--check", "glovo"}, "")
+	if err != nil || !strings.Contains(out, "check=ok") {
+		t.Fatalf("check: %v\n%s%s", err, out, errOut)
+	}
+	if !strings.Contains(errOut, "token refreshed for this run only") {
+		t.Fatalf("expected warning, stderr:\n%s", errOut)
+	}
+	cfg, err = config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if g := cfg.Glovo(); g.AccessToken != "at-1" || g.RefreshToken != "env:ORDERCLI_TEST_GLOVO_REFRESH" {
+		t.Fatalf("secret reference overwritten: %+v", g)
+	}
+}
@@ -29,17 +29,24 @@ func newConfigShowCmd(st *state) *cobra.Command {
 			fmt.Fprintf(cmd.OutOrStdout(), "target_country_iso=%s\n", cfg.TargetCountryISO)
 			fmt.Fprintf(cmd.OutOrStdout(), "device_id=%s\n", cfg.DeviceID)
 			if cfg.AccessToken != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "access_token=***\n")
+				fmt.Fprintf(cmd.OutOrStdout(), "access_token=%s\n", secretLabel(cfg.AccessToken))
 			}
 			if cfg.RefreshToken != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "refresh_token=***\n")
+				fmt.Fprintf(cmd.OutOrStdout(), "refresh_token=%s\n", secretLabel(cfg.RefreshToken))
 			}
 			if cfg.ClientSecret != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "client_secret=*** (stored)\n")
+				fmt.Fprintf(cmd.OutOrStdout(), "client_secret=%s (stored)\n", secretLabel(cfg.ClientSecret))
 			}
 			if cfg.OAuthClientID != "" {
 				fmt.Fprintf(cmd.OutOrStdout(), "oauth_client_id=%s\n", cfg.OAuthClientID)
 			}
+			if cfg.AppProfile != "" {
+				if _, ok := st.cfg.AppProfiles[cfg.AppProfile]; ok {
+					fmt.Fprintf(cmd.OutOrStdout(), "app_profile=%s\n", cfg.AppProfile)
+				} else {
+					fmt.Fprintf(cmd.OutOrStdout(), "app_profile=%s (not imported; using built-in values)\n", cfg.AppProfile)
+				}
+			}
 			if cfg.HTTPUserAgent != "" {
 				fmt.Fprintf(cmd.OutOrStdout(), "http_user_agent=%s\n", cfg.HTTPUserAgent)
 			}
@@ -49,6 +56,7 @@ func newConfigShowCmd(st *state) *cobra.Command {
 			if cfg.PendingMfaToken != "" {
 				fmt.Fprintf(cmd.OutOrStdout(), "pending_mfa=*** (%s, %s)\n", cfg.PendingMfaChannel, cfg.PendingMfaEmail)
 			}
+			printNetworkConfig(cmd.OutOrStdout(), cfg.Network)
 		},
 	}
 }
@@ -58,12 +66,30 @@ func newConfigSetCmd(st *state) *cobra.Command {
 	var baseURL string
 	var globalEntityID string
 	var targetISO string
+	var appProfile string
+	var network networkConfigFlags
 
 	cmd := &cobra.Command{
 		Use:   "set",
-		Short: "Update base URL / country",
+		Short: "Update base URL / country / app profile / network settings",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := st.foodora()
+			cfg := st.ensureFoodora()
+			netChanged, err := network.apply(st, cmd, &cfg.Network)
+			if err != nil {
+				return err
+			}
+			if netChanged {
+				st.markDirty()
+			}
+			if cmd.Flags().Changed("app-profile") {
+				if appProfile != "" {
+					if _, ok := st.cfg.AppProfiles[appProfile]; !ok {
+						return fmt.Errorf("unknown app profile %q (import one with `ordercli foodora apk-import`)", appProfile)
+					}
+				}
+				cfg.AppProfile = appProfile
+				st.markDirty()
+			}
 			if country != "" {
 				country = strings.ToUpper(country)
 				p, ok := findPreset(country)
@@ -78,7 +104,10 @@ func newConfigSetCmd(st *state) *cobra.Command {
 			}
 
 			if baseURL == "" && globalEntityID == "" && targetISO == "" {
-				return errors.New("nothing to set (use --country or --base-url/--global-entity-id/--target-iso)")
+				if cmd.Flags().Changed("app-profile") || netChanged {
+					return nil
+				}
+				return errors.New("nothing to set (use --country, --base-url/--global-entity-id/--target-iso, --app-profile or --proxy/--ca-file/--http-timeout/--insecure-skip-verify)")
 			}
 			if baseURL != "" {
 				cfg.BaseURL = baseURL
@@ -98,5 +127,7 @@ func newConfigSetCmd(st *state) *cobra.Command {
 	cmd.Flags().StringVar(&baseURL, "base-url", "", "API base URL (e.g. https://hu.fd-api.com/api/v5/)")
 	cmd.Flags().StringVar(&globalEntityID, "global-entity-id", "", "X-Global-Entity-ID (e.g. NP_HU)")
 	cmd.Flags().StringVar(&targetISO, "target-iso", "", "X-Target-Country-Code-ISO (e.g. HU)")
+	cmd.Flags().StringVar(&appProfile, "app-profile", "", "imported app profile to use (\"\" = built-in values)")
+	addNetworkConfigFlags(cmd, &network)
 	return cmd
 }
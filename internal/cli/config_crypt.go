@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// newRootConfigCmd holds provider-independent config maintenance; provider
+// settings stay under `ordercli <provider> config`.
+func newRootConfigCmd(st *state) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Config file maintenance (encryption, format upgrades)",
+	}
+	cmd.AddCommand(newConfigMigrateCmd(st))
+	cmd.AddCommand(newConfigEncryptCmd(st))
+	cmd.AddCommand(newConfigDecryptCmd(st))
+	cmd.AddCommand(newConfigRekeyCmd(st))
+	return cmd
+}
+
+func newConfigEncryptCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt tokens, secrets and cookies in the config with a passphrase",
+		Long: "Encrypt tokens, secrets and cookies in the config with a passphrase.\n\n" +
+			"The passphrase is read from " + envNewPassphrase + " or " + envPassphrase + ", a terminal prompt, or the first line of stdin.\n" +
+			"Later runs need it in " + envPassphrase + ", on the terminal or on stdin.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if st.cfg.Encrypted() {
+				return errors.New("config is already encrypted (use `ordercli config rekey` to change the passphrase)")
+			}
+			pass, err := st.newPassphrase(envNewPassphrase, envPassphrase)
+			if err != nil {
+				return err
+			}
+			if err := st.cfg.SetPassphrase(pass); err != nil {
+				return err
+			}
+			st.markDirty()
+			fmt.Fprintf(cmd.OutOrStdout(), "encrypted secrets in %s\n", st.configPath)
+			return nil
+		},
+	}
+}
+
+func newConfigDecryptCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "decrypt",
+		Short: "Store config secrets in plaintext again",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !st.cfg.Encrypted() {
+				return errors.New("config is not encrypted")
+			}
+			if err := st.cfg.RemovePassphrase(); err != nil {
+				return err
+			}
+			st.markDirty()
+			fmt.Fprintf(cmd.OutOrStdout(), "decrypted secrets in %s\n", st.configPath)
+			return nil
+		},
+	}
+}
+
+func newConfigRekeyCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rekey",
+		Short: "Change the config passphrase",
+		Long: "Change the config passphrase.\n\n" +
+			"The current passphrase comes from " + envPassphrase + " (or the prompt / first stdin line),\n" +
+			"the new one from " + envNewPassphrase + " (or a second prompt / the next stdin line).",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !st.cfg.Encrypted() {
+				return errors.New("config is not encrypted (use `ordercli config encrypt`)")
+			}
+			pass, err := st.newPassphrase(envNewPassphrase)
+			if err != nil {
+				return err
+			}
+			if err := st.cfg.SetPassphrase(pass); err != nil {
+				return err
+			}
+			st.markDirty()
+			fmt.Fprintf(cmd.OutOrStdout(), "re-encrypted secrets in %s\n", st.configPath)
+			return nil
+		},
+	}
+}
+
+// newPassphrase reads a new passphrase from the first set env var, else
+// prompts twice on a terminal, else reads a line of stdin.
+func (s *state) newPassphrase(envs ...string) ([]byte, error) {
+	for _, env := range envs {
+		if v := os.Getenv(env); v != "" {
+			return []byte(v), nil
+		}
+	}
+	pass, err := s.readPassphrase("New passphrase: ", envs[0])
+	if err != nil {
+		return nil, err
+	}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		again, err := s.readPassphrase("Repeat passphrase: ", envs[0])
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(pass, again) {
+			return nil, errors.New("passphrases don't match")
+		}
+	}
+	return pass, nil
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/ordercli/internal/config"
+)
+
+func TestConfigEncryptDecryptRekey(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.New()
+	cfg.Foodora().AccessToken = "tok-secret"
+	cfg.Foodora().RefreshToken = "ref-secret"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	fileHas := func(s string) bool {
+		b, err := os.ReadFile(cfgPath)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return strings.Contains(string(b), s)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"config", "encrypt"}, "pw1\n"); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if fileHas("tok-secret") || !fileHas(`"encryption"`) {
+		t.Fatalf("expected encrypted config")
+	}
+
+	// Transparent decryption on load (passphrase on stdin).
+	out, _, err := runCLI(cfgPath, []string{"foodora", "config", "show"}, "pw1\n")
+	if err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	if !strings.Contains(out, "access_token=***") {
+		t.Fatalf("unexpected show output: %s", out)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "config", "show"}, "nope\n"); err == nil || !strings.Contains(err.Error(), "wrong config passphrase") {
+		t.Fatalf("expected wrong passphrase error, got %v", err)
+	}
+
+	// Changes made while unlocked are encrypted again on save.
+	setEnv(t, envPassphrase, "pw1")
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "config", "set", "--base-url", "https://example.com/"}, ""); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if fileHas("tok-secret") || !fileHas("https://example.com/") {
+		t.Fatalf("expected secrets to stay encrypted after save")
+	}
+
+	// Old passphrase from env, new one on stdin.
+	if _, _, err := runCLI(cfgPath, []string{"config", "rekey"}, "pw2\n"); err != nil {
+		t.Fatalf("rekey: %v", err)
+	}
+	setEnv(t, envPassphrase, "pw2")
+	if _, _, err := runCLI(cfgPath, []string{"config", "decrypt"}, ""); err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if !fileHas("tok-secret") || fileHas(`"encryption"`) {
+		t.Fatalf("expected plaintext config")
+	}
+}
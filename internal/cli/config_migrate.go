@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/config"
+)
+
+func newConfigMigrateCmd(st *state) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade the config file to the current format (backs it up first)",
+		Long: "Upgrade the config file to the current format.\n\n" +
+			"Any command upgrades an old config automatically; this one does it explicitly.\n" +
+			"A copy is written to <config>.bak-<timestamp> before the file changes.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			orig, err := os.ReadFile(st.configPath)
+			if errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintf(out, "no config file at %s\n", st.configPath)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			upgraded, from, err := config.Upgrade(orig)
+			if err != nil {
+				return err
+			}
+			if from == config.CurrentVersion {
+				fmt.Fprintf(out, "config is up to date (version %d)\n", from)
+				return nil
+			}
+			for _, m := range config.Pending(from) {
+				fmt.Fprintf(out, "migration %s\n", m)
+			}
+
+			if dryRun {
+				before, err := indentJSON(orig)
+				if err != nil {
+					return err
+				}
+				writeLineDiff(out, before, string(upgraded), 2)
+				// Write nothing, not even the upgrade state.load queued.
+				st.upgraded = false
+				st.dirty = false
+				return nil
+			}
+
+			backup, err := config.Backup(st.configPath, time.Now())
+			if err != nil {
+				return err
+			}
+			if err := config.Save(st.configPath, st.cfg); err != nil {
+				return err
+			}
+			st.upgraded = false
+			st.dirty = false
+			fmt.Fprintf(out, "upgraded config from version %d to %d (backup: %s)\n", from, config.CurrentVersion, backup)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the pending migrations and a diff without writing")
+	return cmd
+}
+
+// indentJSON re-encodes b the way config.Upgrade does (sorted keys, two
+// spaces), so diffs only show real changes.
+func indentJSON(b []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
+
+// writeLineDiff prints a line diff of a and b ("- " removed, "+ " added) with
+// context unchanged lines around each change.
+func writeLineDiff(w io.Writer, a, b string, context int) {
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+
+	// lcs[i][j] is the LCS length of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type op struct {
+		prefix, line string
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			ops = append(ops, op{"  ", x[i]})
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{"- ", x[i]})
+			i++
+		default:
+			ops = append(ops, op{"+ ", y[j]})
+			j++
+		}
+	}
+
+	show := make([]bool, len(ops))
+	for k, o := range ops {
+		if o.prefix == "  " {
+			continue
+		}
+		for c := max(0, k-context); c <= min(len(ops)-1, k+context); c++ {
+			show[c] = true
+		}
+	}
+	gap := false
+	for k, o := range ops {
+		if !show[k] {
+			gap = true
+			continue
+		}
+		if gap {
+			fmt.Fprintln(w, "  ...")
+			gap = false
+		}
+		fmt.Fprintln(w, o.prefix+o.line)
+	}
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigMigrate_DryRunAndUpgrade(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.json")
+	legacy := `{"base_url":"https://hu.fd-api.com/api/v5/","device_id":"dev-1"}` + "\n"
+	if err := os.WriteFile(cfgPath, []byte(legacy), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	out, _, err := runCLI(cfgPath, []string{"config", "migrate", "--dry-run"}, "")
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	for _, want := range []string{"migration 0->1", `-   "base_url"`, `+   "providers": {`, `+   "version": 1`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("dry run output missing %q:\n%s", want, out)
+		}
+	}
+	if b, _ := os.ReadFile(cfgPath); string(b) != legacy {
+		t.Fatalf("dry run wrote the config:\n%s", b)
+	}
+	if m, _ := filepath.Glob(cfgPath + ".bak-*"); len(m) != 0 {
+		t.Fatalf("dry run wrote a backup: %v", m)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"config", "migrate"}, "")
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if !strings.Contains(out, "upgraded config from version 0 to 1") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	backups, _ := filepath.Glob(cfgPath + ".bak-*")
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v", backups)
+	}
+	if b, _ := os.ReadFile(backups[0]); string(b) != legacy {
+		t.Fatalf("backup should hold the old file:\n%s", b)
+	}
+	if b, _ := os.ReadFile(cfgPath); !strings.Contains(string(b), `"providers"`) || !strings.Contains(string(b), "dev-1") {
+		t.Fatalf("unexpected upgraded config:\n%s", b)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"config", "migrate"}, "")
+	if err != nil || !strings.Contains(out, "up to date") {
+		t.Fatalf("expected up to date, got %q err=%v", out, err)
+	}
+}
+
+func TestConfigAutoUpgradeWritesBackup(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(cfgPath, []byte(`{"base_url":"https://hu.fd-api.com/api/v5/"}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "config", "show"}, ""); err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	if m, _ := filepath.Glob(cfgPath + ".bak-*"); len(m) != 1 {
+		t.Fatalf("expected a backup before the upgrade, got %v", m)
+	}
+	if b, _ := os.ReadFile(cfgPath); !strings.Contains(string(b), `"version": 1`) {
+		t.Fatalf("expected upgraded config:\n%s", b)
+	}
+}
@@ -32,7 +32,7 @@ func newCookiesChromeCmd(st *state) *cobra.Command {
 		Use:   "chrome",
 		Short: "Import cookies from local Chrome into config (for base_url host)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := st.foodora()
+			cfg := st.ensureFoodora()
 			if cfg.BaseURL == "" {
 				return errors.New("missing base_url (run `ordercli foodora config set --country ...`)")
 			}
@@ -69,10 +69,7 @@ func newCookiesChromeCmd(st *state) *cobra.Command {
 				return errors.New("no cookies found (are you logged in in Chrome? try --profile \"Default\" / \"Profile 1\" or --cookie-path)")
 			}
 
-			if cfg.CookiesByHost == nil {
-				cfg.CookiesByHost = map[string]string{}
-			}
-			cfg.CookiesByHost[strings.ToLower(host)] = res.CookieHeader
+			cfg.SetCookies(host, res.CookieHeader, time.Now())
 			st.markDirty()
 
 			fmt.Fprintf(cmd.OutOrStdout(), "ok host=%s cookies=%d\n", host, res.CookieCount)
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -11,23 +12,31 @@ type countryPreset struct {
 	BaseURL        string
 	GlobalEntityID string
 	TargetISO      string
+	Currency       string
 }
 
 var presets = []countryPreset{
-	{Code: "HU", BaseURL: "https://hu.fd-api.com/api/v5/", GlobalEntityID: "NP_HU", TargetISO: "HU"},
-	{Code: "SK", BaseURL: "https://sk.fd-api.com/api/v5/", GlobalEntityID: "FP_SK", TargetISO: "SK"},
-	{Code: "DL", BaseURL: "https://dl.fd-api.com/api/v5/", GlobalEntityID: "FP_DE", TargetISO: "DE"},
-	{Code: "AT", BaseURL: "https://mj.fd-api.com/api/v5/", GlobalEntityID: "MJM_AT", TargetISO: "AT"},
+	{Code: "HU", BaseURL: "https://hu.fd-api.com/api/v5/", GlobalEntityID: "NP_HU", TargetISO: "HU", Currency: "HUF"},
+	{Code: "SK", BaseURL: "https://sk.fd-api.com/api/v5/", GlobalEntityID: "FP_SK", TargetISO: "SK", Currency: "EUR"},
+	{Code: "DL", BaseURL: "https://dl.fd-api.com/api/v5/", GlobalEntityID: "FP_DE", TargetISO: "DE", Currency: "EUR"},
+	{Code: "AT", BaseURL: "https://mj.fd-api.com/api/v5/", GlobalEntityID: "MJM_AT", TargetISO: "AT", Currency: "EUR"},
 }
 
 func newCountriesCmd(st *state) *cobra.Command {
 	return &cobra.Command{
 		Use:   "countries",
 		Short: "List bundled country presets (from the APK)",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r := newRecords("code", "global_entity_id", "base_url", "target_country_iso", "currency")
 			for _, p := range presets {
-				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\n", p.Code, p.GlobalEntityID, p.BaseURL)
+				r.add(p.Code, p.GlobalEntityID, p.BaseURL, p.TargetISO, p.Currency)
 			}
+			return st.renderList(cmd.OutOrStdout(), r, func() error {
+				for _, p := range presets {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\n", p.Code, p.GlobalEntityID, p.BaseURL)
+				}
+				return nil
+			})
 		},
 	}
 }
@@ -40,3 +49,14 @@ func findPreset(code string) (countryPreset, bool) {
 	}
 	return countryPreset{}, false
 }
+
+// currencyForTargetISO maps a configured target country to its preset currency.
+// fd-api history items carry amounts without a currency code.
+func currencyForTargetISO(iso string) string {
+	for _, p := range presets {
+		if strings.EqualFold(p.TargetISO, iso) {
+			return p.Currency
+		}
+	}
+	return ""
+}
@@ -25,6 +25,7 @@ func newDeliverooConfigShowCmd(st *state) *cobra.Command {
 			cfg := st.deliveroo()
 			fmt.Fprintf(cmd.OutOrStdout(), "market=%s\n", cfg.Market)
 			fmt.Fprintf(cmd.OutOrStdout(), "base_url=%s\n", cfg.BaseURL)
+			printNetworkConfig(cmd.OutOrStdout(), cfg.Network)
 		},
 	}
 }
@@ -32,14 +33,19 @@ func newDeliverooConfigShowCmd(st *state) *cobra.Command {
 func newDeliverooConfigSetCmd(st *state) *cobra.Command {
 	var market string
 	var baseURL string
+	var network networkConfigFlags
 
 	cmd := &cobra.Command{
 		Use:   "set",
-		Short: "Update market/base url",
+		Short: "Update market/base url/network settings",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := st.deliveroo()
-			if strings.TrimSpace(market) == "" && strings.TrimSpace(baseURL) == "" {
-				return fmt.Errorf("nothing to set (use --market and/or --base-url)")
+			cfg := st.ensureDeliveroo()
+			netChanged, err := network.apply(st, cmd, &cfg.Network)
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(market) == "" && strings.TrimSpace(baseURL) == "" && !netChanged {
+				return fmt.Errorf("nothing to set (use --market, --base-url or --proxy/--ca-file/--http-timeout/--insecure-skip-verify)")
 			}
 			if strings.TrimSpace(market) != "" {
 				cfg.Market = strings.ToLower(strings.TrimSpace(market))
@@ -54,5 +60,6 @@ func newDeliverooConfigSetCmd(st *state) *cobra.Command {
 
 	cmd.Flags().StringVar(&market, "market", "", "market (e.g. uk)")
 	cmd.Flags().StringVar(&baseURL, "base-url", "", "API base url (default derived from market)")
+	addNetworkConfigFlags(cmd, &network)
 	return cmd
 }
@@ -1,7 +1,7 @@
 package cli
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -10,7 +10,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/steipete/ordercli/internal/config"
 	"github.com/steipete/ordercli/internal/deliveroo"
+	"github.com/steipete/ordercli/internal/provider"
 )
 
 func newDeliverooHistoryCmd(st *state) *cobra.Command {
@@ -26,38 +28,9 @@ func newDeliverooHistoryCmd(st *state) *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "history",
-		Short: "List past orders (requires DELIVEROO_BEARER_TOKEN)",
+		Short: "List past orders (needs a session, see `deliveroo session`)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := st.deliveroo()
-
-			m := strings.TrimSpace(market)
-			if m == "" {
-				m = strings.TrimSpace(cfg.Market)
-			}
-			b := strings.TrimSpace(bearerToken)
-			if b == "" {
-				b = strings.TrimSpace(os.Getenv("DELIVEROO_BEARER_TOKEN"))
-			}
-			if b == "" {
-				return errors.New("missing bearer token (set DELIVEROO_BEARER_TOKEN or pass --bearer-token)")
-			}
-			c := strings.TrimSpace(cookie)
-			if c == "" {
-				c = strings.TrimSpace(os.Getenv("DELIVEROO_COOKIE"))
-			}
-
-			u := strings.TrimSpace(baseURL)
-			if u == "" {
-				u = strings.TrimSpace(cfg.BaseURL)
-			}
-
-			cl, err := deliveroo.NewClient(deliveroo.ClientOptions{
-				BaseURL:     u,
-				Market:      m,
-				BearerToken: b,
-				Cookie:      c,
-				Timeout:     20 * time.Second,
-			})
+			cl, err := newDeliverooClient(cmd.Context(), st, market, baseURL, bearerToken, cookie)
 			if err != nil {
 				return err
 			}
@@ -72,21 +45,24 @@ func newDeliverooHistoryCmd(st *state) *cobra.Command {
 				return err
 			}
 
-			if asJSON {
-				enc := json.NewEncoder(cmd.OutOrStdout())
-				enc.SetIndent("", "  ")
-				return enc.Encode(resp)
-			}
-
 			out := cmd.OutOrStdout()
-			if len(resp.Orders) == 0 {
-				fmt.Fprintln(out, "no orders")
-				return nil
-			}
+			orders := make([]provider.Order, 0, len(resp.Orders))
 			for _, o := range resp.Orders {
-				fmt.Fprintln(out, o.Summary())
+				orders = append(orders, provider.DeliverooOrder(o))
 			}
-			return nil
+			if asJSON {
+				return st.writeOrdersJSON(out, orders, resp)
+			}
+			return st.renderList(out, orderRecords(orders), func() error {
+				if len(resp.Orders) == 0 {
+					fmt.Fprintln(out, "no orders")
+					return nil
+				}
+				for _, o := range resp.Orders {
+					fmt.Fprintln(out, o.Summary())
+				}
+				return nil
+			})
 		},
 	}
 
@@ -98,10 +74,75 @@ func newDeliverooHistoryCmd(st *state) *cobra.Command {
 	cmd.Flags().IntVar(&limit, "limit", 10, "paging limit")
 	cmd.Flags().BoolVar(&includeUgc, "include-ugc", false, "include UGC in response")
 	cmd.Flags().StringVar(&state, "state", "", "state filter (provider-specific)")
-	cmd.Flags().BoolVar(&asJSON, "json", false, "print raw JSON")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON (see --schema)")
 	return cmd
 }
 
+// deliverooExpiryWarning is how early a stored session's expiry is announced.
+const deliverooExpiryWarning = 24 * time.Hour
+
+// newDeliverooClient builds a client from flags, env and the stored session,
+// in that order of precedence.
+func newDeliverooClient(ctx context.Context, st *state, market, baseURL, bearerToken, cookie string) (*deliveroo.Client, error) {
+	cfg := st.deliveroo()
+
+	m := strings.TrimSpace(market)
+	if m == "" {
+		m = strings.TrimSpace(cfg.Market)
+	}
+	b := strings.TrimSpace(bearerToken)
+	if b == "" {
+		b = strings.TrimSpace(os.Getenv("DELIVEROO_BEARER_TOKEN"))
+	}
+	c := strings.TrimSpace(cookie)
+	if c == "" {
+		c = strings.TrimSpace(os.Getenv("DELIVEROO_COOKIE"))
+	}
+	stored := b == "" && cfg.HasSession()
+	if stored {
+		b = cfg.BearerToken
+		if c == "" {
+			c = cfg.Cookie
+		}
+	}
+	if b == "" {
+		return nil, errors.New("missing bearer token (run `ordercli deliveroo session set|chrome`, set DELIVEROO_BEARER_TOKEN or pass --bearer-token)")
+	}
+	var err error
+	if b, err = st.resolveSecret(ctx, b); err != nil {
+		return nil, err
+	}
+	if c, err = st.resolveSecret(ctx, c); err != nil {
+		return nil, err
+	}
+	if exp, ok := config.AccessTokenExpiresAt(b); ok && stored {
+		left := time.Until(exp)
+		if left <= 0 {
+			return nil, fmt.Errorf("deliveroo session expired %s (run `ordercli deliveroo session chrome` or `session set`)", exp.Local().Format(time.RFC3339))
+		}
+		if left < deliverooExpiryWarning {
+			st.warnf("deliveroo session expires in %s (run `ordercli deliveroo session chrome` to renew)", left.Round(time.Minute))
+		}
+	}
+
+	u := strings.TrimSpace(baseURL)
+	if u == "" {
+		u = strings.TrimSpace(cfg.BaseURL)
+	}
+
+	httpOpts, err := st.httpOptions(cfg.Network)
+	if err != nil {
+		return nil, err
+	}
+	return deliveroo.NewClient(deliveroo.ClientOptions{
+		BaseURL:     u,
+		Market:      m,
+		BearerToken: b,
+		Cookie:      c,
+		HTTP:        httpOpts,
+	})
+}
+
 func newDeliverooOrdersCmd(st *state) *cobra.Command {
 	var interval time.Duration
 	var once bool
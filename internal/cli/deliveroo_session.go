@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/chromecookies"
+	"github.com/steipete/ordercli/internal/config"
+)
+
+// deliverooSites maps markets to the consumer website that holds the session
+// cookies in Chrome.
+var deliverooSites = map[string]string{
+	"uk": "https://deliveroo.co.uk/",
+	"ie": "https://deliveroo.ie/",
+	"fr": "https://deliveroo.fr/",
+	"be": "https://deliveroo.be/",
+	"nl": "https://deliveroo.nl/",
+	"it": "https://deliveroo.it/",
+	"hk": "https://deliveroo.hk/",
+	"sg": "https://deliveroo.com.sg/",
+	"ae": "https://deliveroo.ae/",
+	"kw": "https://deliveroo.com.kw/",
+	"qa": "https://deliveroo.qa/",
+}
+
+func newDeliverooSessionCmd(st *state) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "session",
+		Short: "Store, import and inspect the Deliveroo session",
+	}
+	cmd.AddCommand(newDeliverooSessionSetCmd(st))
+	cmd.AddCommand(newDeliverooSessionChromeCmd(st))
+	cmd.AddCommand(newDeliverooSessionShowCmd(st))
+	cmd.AddCommand(newDeliverooSessionClearCmd(st))
+	return cmd
+}
+
+func newDeliverooSessionSetCmd(st *state) *cobra.Command {
+	var token string
+	var tokenStdin bool
+	var cookie string
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Store a bearer token (and cookie header) in config",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if tokenStdin {
+				// The passphrase prompt may already have buffered stdin.
+				var in io.Reader = os.Stdin
+				if st.stdin != nil {
+					in = st.stdin
+				}
+				b, err := io.ReadAll(in)
+				if err != nil {
+					return err
+				}
+				token = string(b)
+			}
+			token = strings.TrimPrefix(strings.TrimSpace(token), "Bearer ")
+			if token == "" {
+				return errors.New("missing token (use --token or --token-stdin)")
+			}
+			cfg := st.ensureDeliveroo()
+			cfg.BearerToken = token
+			if c := strings.TrimSpace(cookie); c != "" {
+				cfg.Cookie = c
+			}
+			st.markDirty()
+			printDeliverooSession(cmd.OutOrStdout(), *cfg, time.Now())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&token, "token", "", "bearer token (Authorization header without \"Bearer \")")
+	cmd.Flags().BoolVar(&tokenStdin, "token-stdin", false, "read the bearer token from stdin")
+	cmd.Flags().StringVar(&cookie, "cookie", "", "cookie header to send along (optional)")
+	return cmd
+}
+
+func newDeliverooSessionChromeCmd(st *state) *cobra.Command {
+	var siteURL string
+	var profile string
+	var cookiePath string
+	var tokenCookie string
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "chrome",
+		Short: "Import the session from Chrome cookies (no browser run)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := st.ensureDeliveroo()
+			if strings.TrimSpace(siteURL) == "" {
+				market := strings.ToLower(strings.TrimSpace(cfg.Market))
+				if market == "" {
+					market = "uk"
+				}
+				u, ok := deliverooSites[market]
+				if !ok {
+					return fmt.Errorf("no known website for market %q; pass --url", market)
+				}
+				siteURL = u
+			}
+
+			cacheDir := filepath.Join(filepath.Dir(st.configPath), "chrome-cookies")
+			res, err := chromeLoadCookieHeader(cmd.Context(), chromecookies.Options{
+				TargetURL:          strings.TrimSpace(siteURL),
+				ChromeProfile:      profile,
+				ExplicitCookiePath: cookiePath,
+				Timeout:            timeout,
+				CacheDir:           cacheDir,
+				LogWriter:          cmd.ErrOrStderr(),
+			})
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(res.CookieHeader) == "" {
+				return errors.New("no cookies found (are you logged in in Chrome? try --profile \"Default\" / \"Profile 1\" or --cookie-path)")
+			}
+
+			token, name, err := deliverooTokenFromCookies(res.CookieHeader, tokenCookie)
+			if err != nil {
+				return err
+			}
+			cfg.BearerToken = token
+			cfg.Cookie = res.CookieHeader
+			st.markDirty()
+			fmt.Fprintf(cmd.OutOrStdout(), "imported token from cookie %s (%d cookies)\n", name, res.CookieCount)
+			printDeliverooSession(cmd.OutOrStdout(), *cfg, time.Now())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&siteURL, "url", "", "site URL that holds the cookies (default: from market, e.g. https://deliveroo.co.uk/)")
+	cmd.Flags().StringVar(&profile, "profile", "", "Chrome profile name (Default, Profile 1, ...) or path to profile dir")
+	cmd.Flags().StringVar(&cookiePath, "cookie-path", "", "explicit Cookies DB path or profile dir (overrides --profile)")
+	cmd.Flags().StringVar(&tokenCookie, "token-cookie", "", "cookie that holds the bearer token (default: the first JWT with an expiry)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "cookie read timeout (keychain prompts may need longer)")
+	return cmd
+}
+
+// deliverooTokenFromCookies returns the named cookie, or else the cookie that
+// looks like a JWT with an expiry (names sorted for a stable pick).
+func deliverooTokenFromCookies(header, name string) (string, string, error) {
+	kv := parseCookieHeader(header)
+	if name = strings.TrimSpace(name); name != "" {
+		if v := kv[name]; v != "" {
+			return v, name, nil
+		}
+		return "", "", fmt.Errorf("cookie %q not found", name)
+	}
+	names := make([]string, 0, len(kv))
+	for k := range kv {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		if _, ok := config.AccessTokenExpiresAt(kv[k]); ok {
+			return kv[k], k, nil
+		}
+	}
+	return "", "", errors.New("no JWT cookie found (pass --token-cookie, or copy the Authorization header into `ordercli deliveroo session set`)")
+}
+
+func newDeliverooSessionShowCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show the stored session (redacted) and its expiry",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			printDeliverooSession(cmd.OutOrStdout(), *st.deliveroo(), time.Now())
+		},
+	}
+}
+
+func newDeliverooSessionClearCmd(st *state) *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Forget the stored token and cookie",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg := st.deliveroo()
+			cfg.BearerToken = ""
+			cfg.Cookie = ""
+			st.markDirty()
+			fmt.Fprintln(cmd.OutOrStdout(), "ok")
+		},
+	}
+}
+
+func printDeliverooSession(out io.Writer, cfg config.DeliverooConfig, now time.Time) {
+	if !cfg.HasSession() {
+		fmt.Fprintln(out, "session=none")
+		return
+	}
+	fmt.Fprintf(out, "bearer_token=%s\n", secretLabel(cfg.BearerToken))
+	if secretRefKind(cfg.BearerToken) != "" {
+		fmt.Fprintln(out, "expires_at=unknown (reference is resolved at use)")
+	} else if exp, ok := cfg.TokenExpiresAt(); ok {
+		left := exp.Sub(now)
+		switch {
+		case left <= 0:
+			fmt.Fprintf(out, "expires_at=%s (expired)\n", exp.Local().Format(time.RFC3339))
+		case left < deliverooExpiryWarning:
+			fmt.Fprintf(out, "expires_at=%s (in %s, renew soon)\n", exp.Local().Format(time.RFC3339), left.Round(time.Minute))
+		default:
+			fmt.Fprintf(out, "expires_at=%s (in %s)\n", exp.Local().Format(time.RFC3339), left.Round(time.Hour))
+		}
+	} else {
+		fmt.Fprintln(out, "expires_at=unknown (token is not a JWT)")
+	}
+	if secretRefKind(cfg.Cookie) != "" {
+		fmt.Fprintf(out, "cookie=%s\n", secretLabel(cfg.Cookie))
+	} else if cfg.Cookie != "" {
+		fmt.Fprintf(out, "cookie=*** (%d)\n", len(parseCookieHeader(cfg.Cookie)))
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/chromecookies"
+)
+
+func deliverooJWT(exp time.Time) string {
+	b, _ := json.Marshal(map[string]any{"exp": exp.Unix()})
+	return "h." + base64.RawURLEncoding.EncodeToString(b) + ".s"
+}
+
+func TestDeliverooSession_SetShowUseClear(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	token := deliverooJWT(time.Now().Add(2 * time.Hour))
+
+	var gotAuth, gotCookie string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotCookie = r.Header.Get("Authorization"), r.Header.Get("Cookie")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"orders":[{"id":"o1","status":"delivered","restaurant":{"name":"R"}}]}`))
+	}))
+	defer srv.Close()
+
+	if _, _, err := runCLI(cfgPath, []string{"deliveroo", "config", "set", "--market", "uk", "--base-url", srv.URL}, ""); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	out, _, err := runCLI(cfgPath, []string{"deliveroo", "session", "set", "--token-stdin", "--cookie", "roo_guid=g"}, "Bearer "+token+"\n")
+	if err != nil {
+		t.Fatalf("session set: %v", err)
+	}
+	if !strings.Contains(out, "bearer_token=***") || !strings.Contains(out, "renew soon") {
+		t.Fatalf("unexpected set output: %s", out)
+	}
+	if b, _ := os.ReadFile(cfgPath); !strings.Contains(string(b), `"bearer_token"`) {
+		t.Fatalf("token not stored:\n%s", b)
+	}
+
+	out, errOut, err := runCLI(cfgPath, []string{"deliveroo", "history"}, "")
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if !strings.Contains(out, "id=o1") || gotAuth != "Bearer "+token || gotCookie != "roo_guid=g" {
+		t.Fatalf("stored session not used: out=%s auth=%q cookie=%q", out, gotAuth, gotCookie)
+	}
+	if !strings.Contains(errOut, "warning: deliveroo session expires in") {
+		t.Fatalf("expected expiry warning, got %q", errOut)
+	}
+
+	// Deliveroo counts as configured for cross-provider commands now.
+	out, _, err = runCLI(cfgPath, []string{"history", "--provider", "deliveroo"}, "")
+	if err != nil || !strings.Contains(out, "deliveroo\to1") {
+		t.Fatalf("timeline: %v %s", err, out)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"deliveroo", "session", "clear"}, "")
+	if err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	out, _, _ = runCLI(cfgPath, []string{"deliveroo", "session", "show"}, "")
+	if strings.TrimSpace(out) != "session=none" {
+		t.Fatalf("unexpected show after clear: %q", out)
+	}
+}
+
+func TestDeliverooSession_Expired(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	if _, _, err := runCLI(cfgPath, []string{"deliveroo", "session", "set", "--token", deliverooJWT(time.Now().Add(-time.Hour))}, ""); err != nil {
+		t.Fatalf("session set: %v", err)
+	}
+	out, _, _ := runCLI(cfgPath, []string{"deliveroo", "session", "show"}, "")
+	if !strings.Contains(out, "(expired)") {
+		t.Fatalf("unexpected show: %s", out)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"deliveroo", "history"}, ""); err == nil || !strings.Contains(err.Error(), "session expired") {
+		t.Fatalf("expected expired error, got %v", err)
+	}
+}
+
+func TestDeliverooSession_Chrome(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	token := deliverooJWT(time.Now().Add(30 * 24 * time.Hour))
+
+	orig := chromeLoadCookieHeader
+	defer func() { chromeLoadCookieHeader = orig }()
+	var gotURL string
+	chromeLoadCookieHeader = func(ctx context.Context, opts chromecookies.Options) (chromecookies.Result, error) {
+		gotURL = opts.TargetURL
+		return chromecookies.Result{CookieHeader: "roo_guid=g; auth=" + token + "; other=x", CookieCount: 3}, nil
+	}
+
+	out, _, err := runCLI(cfgPath, []string{"deliveroo", "session", "chrome"}, "")
+	if err != nil {
+		t.Fatalf("session chrome: %v", err)
+	}
+	if gotURL != "https://deliveroo.co.uk/" {
+		t.Fatalf("unexpected site url %q", gotURL)
+	}
+	if !strings.Contains(out, "imported token from cookie auth (3 cookies)") || !strings.Contains(out, "cookie=*** (3)") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"deliveroo", "session", "chrome", "--token-cookie", "missing"}, ""); err == nil {
+		t.Fatalf("expected missing cookie error")
+	}
+}
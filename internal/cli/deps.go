@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 
+	"github.com/steipete/ordercli/internal/apk"
 	"github.com/steipete/ordercli/internal/browserauth"
 	"github.com/steipete/ordercli/internal/chromecookies"
 	"github.com/steipete/ordercli/internal/foodora"
@@ -10,6 +11,8 @@ import (
 
 var chromeLoadCookieHeader = chromecookies.LoadCookieHeader
 
+var inspectAPK = apk.Inspect
+
 var browserOAuthTokenPassword = func(ctx context.Context, req foodora.OAuthPasswordRequest, opts browserauth.PasswordOptions) (foodora.AuthToken, *foodora.MfaChallenge, browserauth.Session, error) {
 	return browserauth.OAuthTokenPassword(ctx, req, opts)
 }
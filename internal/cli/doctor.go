@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/drift"
+	"github.com/steipete/ordercli/internal/foodora"
+)
+
+func newDoctorCmd(st *state) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnostics",
+	}
+	cmd.AddCommand(newDoctorAPIDriftCmd(st))
+	return cmd
+}
+
+// driftRecorder is the drift log foodora clients record into (nil during
+// --replay: recorded responses say nothing about the live API).
+func (s *state) driftRecorder() foodora.DriftRecorder {
+	if s.replaying {
+		return nil
+	}
+	return drift.Open(drift.DefaultDir(s.configPath))
+}
+
+func newDoctorAPIDriftCmd(st *state) *cobra.Command {
+	var dir string
+	var keep bool
+
+	cmd := &cobra.Command{
+		Use:   "api-drift",
+		Short: "Report fd-api fields that appeared, vanished or changed type since the last run",
+		Long: "Every decoded foodora response is compared with the Go model it fills and logged to drift/ next to the config.\n\n" +
+			"This report lists, per endpoint called since the last report: new fields, fields that vanished and fields whose JSON type changed, " +
+			"plus fields the models don't declare, declared fields the API never sent, and fields decoded from another JSON type (e.g. by FlexibleInt/FlexibleString).\n" +
+			"The fields seen become the baseline for the next report unless --keep is given.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dir == "" {
+				dir = drift.DefaultDir(st.configPath)
+			}
+			log := drift.Open(dir)
+			rep, err := log.Report()
+			if err != nil {
+				return err
+			}
+
+			r := newRecords("endpoint", "model", "responses", "since", "change", "path", "was", "now")
+			for _, e := range rep.Endpoints {
+				add := func(change, path, was, now string) {
+					r.add(e.Endpoint, e.Model, e.Responses, e.Since, change, path, was, now)
+				}
+				for _, f := range e.New {
+					add("new", f.Path, "", kinds(f.Kinds))
+				}
+				for _, f := range e.Vanished {
+					add("vanished", f.Path, kinds(f.Kinds), "")
+				}
+				for _, t := range e.TypeChanges {
+					add("type", t.Path, kinds(t.Was), kinds(t.Now))
+				}
+				for _, p := range e.Unknown {
+					add("unknown", p, "", "")
+				}
+				for _, p := range e.Missing {
+					add("missing", p, "", "")
+				}
+				for _, m := range e.Mismatched {
+					add("mismatch", m.Path, m.Model, m.JSON)
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			if err := st.renderList(out, r, func() error {
+				printDriftReport(out, rep, dir)
+				return nil
+			}); err != nil {
+				return err
+			}
+			if keep || len(rep.Endpoints) == 0 {
+				return nil
+			}
+			return log.Commit(rep)
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "", "drift dir (default: drift/ next to config)")
+	cmd.Flags().BoolVar(&keep, "keep", false, "don't make this run the baseline for the next report")
+	return cmd
+}
+
+func printDriftReport(out io.Writer, rep drift.Report, dir string) {
+	if len(rep.Endpoints) == 0 {
+		fmt.Fprintf(out, "no foodora responses logged in %s since the last report\n", dir)
+		return
+	}
+	for _, e := range rep.Endpoints {
+		since := "first report"
+		if !e.Since.IsZero() {
+			since = "since " + e.Since.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(out, "%s (%s): %d responses, %s\n", e.Endpoint, e.Model, e.Responses, since)
+		if !e.Changed() {
+			fmt.Fprintln(out, "  no drift")
+			continue
+		}
+		for _, f := range e.New {
+			fmt.Fprintf(out, "  new       %s (%s)\n", f.Path, kinds(f.Kinds))
+		}
+		for _, f := range e.Vanished {
+			fmt.Fprintf(out, "  vanished  %s (was %s)\n", f.Path, kinds(f.Kinds))
+		}
+		for _, t := range e.TypeChanges {
+			fmt.Fprintf(out, "  type      %s: %s -> %s\n", t.Path, kinds(t.Was), kinds(t.Now))
+		}
+		for _, p := range e.Unknown {
+			fmt.Fprintf(out, "  unknown   %s (not in model)\n", p)
+		}
+		for _, p := range e.Missing {
+			fmt.Fprintf(out, "  missing   %s (in model, never sent)\n", p)
+		}
+		for _, m := range e.Mismatched {
+			fmt.Fprintf(out, "  mismatch  %s: model %s, API sent %s\n", m.Path, m.Model, m.JSON)
+		}
+	}
+}
+
+func kinds(k []string) string { return strings.Join(k, "|") }
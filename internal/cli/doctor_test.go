@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+)
+
+func TestDoctorAPIDrift(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	srv := newFoodoraTestServer(t)
+	cfg := config.New()
+	fc := cfg.Foodora()
+	fc.BaseURL = srv.URL + "/"
+	fc.AccessToken = "tok"
+	fc.RefreshToken = "ref"
+	fc.ExpiresAt = time.Now().Add(time.Hour)
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "orders"}, ""); err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	out, _, err := runCLI(cfgPath, []string{"doctor", "api-drift", "--keep"}, "")
+	if err != nil {
+		t.Fatalf("api-drift: %v", err)
+	}
+	if !strings.Contains(out, "GET tracking/active-orders (foodora.ActiveOrdersResponse): 1 responses, first report") ||
+		!strings.Contains(out, "missing   data.poll_in_sec") {
+		t.Fatalf("out:\n%s", out)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"--output", "json", "doctor", "api-drift"}, "")
+	if err != nil {
+		t.Fatalf("api-drift: %v", err)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatalf("json: %v\n%s", err, out)
+	}
+	if len(rows) == 0 || rows[0]["endpoint"] != "GET tracking/active-orders" || rows[0]["change"] != "missing" {
+		t.Fatalf("rows: %v", rows)
+	}
+
+	// The report became the baseline: nothing new until the next API call.
+	out, _, err = runCLI(cfgPath, []string{"doctor", "api-drift"}, "")
+	if err != nil || !strings.Contains(out, "no foodora responses logged") {
+		t.Fatalf("err=%v out:\n%s", err, out)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "orders"}, ""); err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	out, _, err = runCLI(cfgPath, []string{"doctor", "api-drift"}, "")
+	if err != nil || !strings.Contains(out, "1 responses, since ") || strings.Contains(out, "  new ") || strings.Contains(out, "vanished") {
+		t.Fatalf("err=%v out:\n%s", err, out)
+	}
+}
@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/export"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func newExportCmd(st *state) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export orders to other formats",
+	}
+	cmd.AddCommand(newExportExpensesCmd(st))
+	cmd.AddCommand(newExportICalCmd(st))
+	return cmd
+}
+
+func newExportICalCmd(st *state) *cobra.Command {
+	var limit int
+	var providers []string
+	var outPath string
+	var name string
+	var noActive bool
+	var noDetails bool
+
+	cmd := &cobra.Command{
+		Use:   "ical",
+		Short: "Export past and active orders as an iCalendar (.ics) feed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opened, err := openProviders(cmd.Context(), st, providers, func(p providerEntry) bool { return p.caps.History || p.caps.ActiveOrders })
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			seen := map[string]bool{}
+			var orders []provider.Order
+			add := func(o provider.Order) {
+				if !seen[o.Key()] {
+					seen[o.Key()] = true
+					orders = append(orders, o)
+				}
+			}
+			failed := 0
+			for _, p := range opened {
+				got, err := calendarOrders(ctx, p, limit, !noActive, !noDetails)
+				if err != nil {
+					failed++
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %v\n", p.Name(), err)
+				}
+				undated := 0
+				for _, o := range got {
+					if _, _, ok := export.EventTime(o); !ok {
+						undated++
+					}
+					add(o)
+				}
+				if undated > 0 {
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %d orders without a delivery time left out of the calendar\n", p.Name(), undated)
+				}
+			}
+			if failed == len(opened) {
+				return errors.New("all providers failed")
+			}
+
+			return writeOutputFile(cmd.OutOrStdout(), outPath, func(w io.Writer) error {
+				return export.WriteICal(w, name, orders, time.Now())
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 50, "max past orders per provider")
+	cmd.Flags().StringSliceVar(&providers, "provider", nil, "only export these providers (repeatable)")
+	cmd.Flags().StringVar(&outPath, "out", "", "write to file instead of stdout (e.g. orders.ics)")
+	cmd.Flags().StringVar(&name, "name", "Food orders", "calendar name")
+	cmd.Flags().BoolVar(&noActive, "no-active", false, "skip active orders")
+	cmd.Flags().BoolVar(&noDetails, "no-details", false, "don't fetch order details for item lists")
+	return cmd
+}
+
+// calendarOrders returns active orders followed by up to limit past ones.
+// With details, past orders without items are replaced by OrderDetail.
+func calendarOrders(ctx context.Context, p provider.Provider, limit int, active, details bool) ([]provider.Order, error) {
+	caps := p.Capabilities()
+	var out []provider.Order
+	if active && caps.ActiveOrders {
+		got, err := p.ActiveOrders(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, got...)
+	}
+	if !caps.History {
+		return out, nil
+	}
+	past, err := provider.FetchHistory(ctx, p, limit, 20)
+	if err != nil {
+		return out, err
+	}
+	for _, o := range past {
+		if _, _, ok := export.EventTime(o); ok && details && caps.OrderDetail && len(o.Items) == 0 {
+			d, err := p.OrderDetail(ctx, o.ID)
+			if err != nil {
+				return out, fmt.Errorf("order %s: %w", o.ID, err)
+			}
+			if d.When().IsZero() {
+				d.PlacedAt, d.DeliveredAt = o.PlacedAt, o.DeliveredAt
+			}
+			o = d
+		}
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+func newExportExpensesCmd(st *state) *cobra.Command {
+	var from, to string
+	var format string
+	var outPath string
+	var providers []string
+	var maxOrders int
+
+	cmd := &cobra.Command{
+		Use:   "expenses",
+		Short: "Expense report from order details (csv, printable html receipts, ofx)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rng, err := parseDateRange(from, to)
+			if err != nil {
+				return err
+			}
+			format = strings.ToLower(strings.TrimSpace(format))
+			switch format {
+			case "csv", "html", "ofx":
+			default:
+				return fmt.Errorf("unknown --format %q (use csv, html or ofx)", format)
+			}
+
+			opened, err := openProviders(cmd.Context(), st, providers, func(p providerEntry) bool { return p.caps.History })
+			if err != nil {
+				return err
+			}
+			var orders []provider.Order
+			failed := 0
+			for _, p := range opened {
+				got, undated, err := fetchOrderDetails(cmd.Context(), p, rng, maxOrders)
+				if err != nil {
+					failed++
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %v\n", p.Name(), err)
+				}
+				if undated > 0 {
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %d orders without a date left out of the report\n", p.Name(), undated)
+				}
+				orders = append(orders, got...)
+			}
+			if failed == len(opened) {
+				return errors.New("all providers failed")
+			}
+
+			return writeOutputFile(cmd.OutOrStdout(), outPath, func(w io.Writer) error {
+				expenses := export.Expenses(orders)
+				switch format {
+				case "html":
+					return export.WriteExpensesHTML(w, expenses)
+				case "ofx":
+					return export.WriteExpensesOFX(w, expenses, time.Now())
+				default:
+					return export.WriteExpensesCSV(w, expenses)
+				}
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "first day (YYYY-MM-DD, inclusive)")
+	cmd.Flags().StringVar(&to, "to", "", "last day (YYYY-MM-DD, inclusive; default today)")
+	cmd.Flags().StringVar(&format, "format", "csv", "csv, html or ofx")
+	cmd.Flags().StringVar(&outPath, "out", "", "write to file instead of stdout")
+	cmd.Flags().StringSliceVar(&providers, "provider", nil, "only export these providers (repeatable)")
+	cmd.Flags().IntVar(&maxOrders, "max-orders", 500, "stop after scanning this many history orders per provider")
+	return cmd
+}
+
+// dateRange is a half-open [from, to) interval; a zero from is unbounded.
+type dateRange struct {
+	from, to time.Time
+}
+
+func (r dateRange) contains(t time.Time) bool {
+	return !t.Before(r.from) && t.Before(r.to)
+}
+
+// parseDateRange reads inclusive local YYYY-MM-DD days.
+func parseDateRange(from, to string) (dateRange, error) {
+	var r dateRange
+	if s := strings.TrimSpace(from); s != "" {
+		t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			return r, fmt.Errorf("invalid --from %q (want YYYY-MM-DD)", s)
+		}
+		r.from = t
+	}
+	if s := strings.TrimSpace(to); s != "" {
+		t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			return r, fmt.Errorf("invalid --to %q (want YYYY-MM-DD)", s)
+		}
+		r.to = t.AddDate(0, 0, 1)
+	} else {
+		y, m, d := time.Now().Date()
+		r.to = time.Date(y, m, d+1, 0, 0, 0, 0, time.Local)
+	}
+	if !r.from.IsZero() && !r.from.Before(r.to) {
+		return r, errors.New("--from is after --to")
+	}
+	return r, nil
+}
+
+// fetchOrderDetails pages history newest first and returns the orders inside
+// rng, replaced by OrderDetail where the provider has it. Paging stops at the
+// first page that reaches before rng.from. Undated orders can't be placed in
+// rng; they are skipped and counted in undated.
+func fetchOrderDetails(ctx context.Context, p provider.Provider, rng dateRange, maxOrders int) (out []provider.Order, undated int, err error) {
+	if maxOrders <= 0 {
+		maxOrders = 500
+	}
+	detail := p.Capabilities().OrderDetail
+
+	offset := 0
+	for offset < maxOrders {
+		page, err := p.History(ctx, provider.HistoryRequest{Offset: offset, Limit: min(20, maxOrders-offset)})
+		if err != nil {
+			return out, undated, err
+		}
+		reachedStart := false
+		for _, o := range page.Orders {
+			when := o.When()
+			if when.IsZero() {
+				undated++
+				continue
+			}
+			if !rng.from.IsZero() && when.Before(rng.from) {
+				reachedStart = true
+				continue
+			}
+			if !rng.contains(when) {
+				continue
+			}
+			if detail {
+				d, err := p.OrderDetail(ctx, o.ID)
+				if err != nil {
+					return out, undated, fmt.Errorf("order %s: %w", o.ID, err)
+				}
+				if d.When().IsZero() {
+					d.PlacedAt, d.DeliveredAt = o.PlacedAt, o.DeliveredAt
+				}
+				o = d
+			}
+			out = append(out, o)
+		}
+		offset += len(page.Orders)
+		if len(page.Orders) == 0 || !page.More || reachedStart {
+			break
+		}
+	}
+	return out, undated, nil
+}
+
+// writeOutputFile runs write against path (atomically, via a temp file) or
+// stdout when path is empty.
+func writeOutputFile(stdout io.Writer, path string, write func(io.Writer) error) error {
+	if strings.TrimSpace(path) == "" {
+		return write(stdout)
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
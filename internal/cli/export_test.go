@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// datedProvider serves one order per day, newest first, starting at start.
+type datedProvider struct {
+	detailProvider
+	start time.Time
+}
+
+func (p *datedProvider) History(ctx context.Context, req provider.HistoryRequest) (provider.HistoryPage, error) {
+	page, err := p.detailProvider.History(ctx, req)
+	for i := range page.Orders {
+		page.Orders[i].DeliveredAt = p.start.AddDate(0, 0, -(req.Offset + i))
+	}
+	return page, err
+}
+
+func TestFetchOrderDetails_DateRange(t *testing.T) {
+	p := &datedProvider{start: time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)}
+	for i := 0; i < 60; i++ {
+		p.ids = append(p.ids, fmt.Sprint(i))
+	}
+
+	rng, err := parseDateRange("2025-03-05", "2025-03-07")
+	if err != nil {
+		t.Fatalf("parseDateRange: %v", err)
+	}
+	orders, undated, err := fetchOrderDetails(context.Background(), p, rng, 500)
+	if err != nil || undated != 0 {
+		t.Fatalf("fetchOrderDetails: %v", err)
+	}
+	if len(orders) != 3 || p.details != 3 {
+		t.Fatalf("orders=%d details=%d", len(orders), p.details)
+	}
+	if len(orders[0].Items) != 1 || orders[0].DeliveredAt.Day() != 7 {
+		t.Fatalf("unexpected first order: %#v", orders[0])
+	}
+	// The first page already reaches before --from, so nothing else is requested.
+	if len(p.requests) != 1 {
+		t.Fatalf("expected 1 history request, got %d", len(p.requests))
+	}
+
+	if _, err := parseDateRange("2025-03-08", "2025-03-07"); err == nil {
+		t.Fatalf("expected error for inverted range")
+	}
+}
+
+func TestFetchOrderDetails_CountsUndated(t *testing.T) {
+	p := &detailProvider{pagedProvider: pagedProvider{ids: []string{"3", "2", "1"}}}
+	rng, err := parseDateRange("", "")
+	if err != nil {
+		t.Fatalf("parseDateRange: %v", err)
+	}
+	orders, undated, err := fetchOrderDetails(context.Background(), p, rng, 500)
+	if err != nil || len(orders) != 0 || undated != 3 || p.details != 0 {
+		t.Fatalf("orders=%d undated=%d details=%d err=%v", len(orders), undated, p.details, err)
+	}
+}
+
+func TestExportICalCLI_WarnsAboutUndatedOrders(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"orders":[{"orderId":7,"content":{"title":"Glovo Place"},"layoutType":"INACTIVE_ORDER"}]}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.New()
+	cfg.Glovo().BaseURL = srv.URL
+	cfg.Glovo().AccessToken = "glovo-token"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out, errOut, err := runCLI(cfgPath, []string{"export", "ical", "--provider", "glovo"}, "")
+	if err != nil {
+		t.Fatalf("export ical: %v %s", err, errOut)
+	}
+	if strings.Contains(out, "BEGIN:VEVENT") || !strings.Contains(errOut, "glovo: 1 orders without a delivery time left out") {
+		t.Fatalf("out:\n%s\nstderr:\n%s", out, errOut)
+	}
+}
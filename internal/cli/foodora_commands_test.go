@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/steipete/ordercli/internal/config"
 )
@@ -203,6 +204,48 @@ func setEnv(t *testing.T, k, v string) {
 	})
 }
 
+func TestFoodoraCLI_RefreshOn401(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	inner := newFoodoraTestServer(t)
+	defer inner.Close()
+	// The server revoked "access" before its stated expiry.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer access" {
+			http.Error(w, `{"code":"invalid_token"}`, http.StatusUnauthorized)
+			return
+		}
+		inner.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	cfg := config.New()
+	f := cfg.Foodora()
+	f.BaseURL = srv.URL + "/"
+	f.AccessToken = "access"
+	f.RefreshToken = "refresh"
+	f.ExpiresAt = time.Now().Add(time.Hour)
+	f.OAuthClientID = "android"
+	f.ClientSecret = "secret"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out, _, err := runCLI(cfgPath, []string{"foodora", "orders"}, "")
+	if err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	if !strings.Contains(out, "OC-1") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	got, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got.Foodora().AccessToken != "access2" || got.Foodora().RefreshToken != "refresh2" {
+		t.Fatalf("refreshed token not persisted: %+v", got.Foodora())
+	}
+}
+
 func newFoodoraTestServer(t *testing.T) *httptest.Server {
 	t.Helper()
 
@@ -296,13 +339,55 @@ func ioReadAllBody(r *http.Request) ([]byte, error) {
 func TestDefaultWebURLForConfig(t *testing.T) {
 	st := &state{}
 	st.cfg = config.New()
-	st.foodora().TargetCountryISO = "AT"
+	st.ensureFoodora().TargetCountryISO = "AT"
 	u, ok := defaultWebURLForConfig(st)
 	if !ok || u != "https://www.foodora.at/" {
 		t.Fatalf("got %q ok=%v", u, ok)
 	}
-	st.foodora().TargetCountryISO = "HU"
+	st.ensureFoodora().TargetCountryISO = "HU"
 	if _, ok := defaultWebURLForConfig(st); ok {
 		t.Fatalf("expected false")
 	}
 }
+
+func TestFoodoraCLI_SessionRefreshKeepsSecretReference(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv("ORDERCLI_TEST_FOODORA_REFRESH", "rt-1")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/oauth2/token" || r.FormValue("refresh_token") != "rt-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"access2","refresh_token":"rt-2","expires_in":3600}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.New()
+	fc := cfg.Foodora()
+	fc.BaseURL = srv.URL + "/"
+	fc.OAuthClientID = "android"
+	fc.ClientSecret = "secret"
+	fc.AccessToken = "access"
+	fc.RefreshToken = "env:ORDERCLI_TEST_FOODORA_REFRESH"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	_, errOut, err := runCLI(cfgPath, []string{"foodora", "session", "refresh"}, "")
+	if err != nil {
+		t.Fatalf("session refresh: %v %s", err, errOut)
+	}
+	if !strings.Contains(errOut, "token refreshed for this run only") {
+		t.Fatalf("expected warning, stderr:\n%s", errOut)
+	}
+	cfg, err = config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := cfg.Foodora(); got.RefreshToken != "env:ORDERCLI_TEST_FOODORA_REFRESH" || got.AccessToken != "access" {
+		t.Fatalf("secret reference overwritten: %+v", got)
+	}
+}
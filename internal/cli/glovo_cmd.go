@@ -1,44 +1,114 @@
 package cli
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
+	"github.com/steipete/ordercli/internal/auth"
+	"github.com/steipete/ordercli/internal/config"
 	"github.com/steipete/ordercli/internal/glovo"
+	"github.com/steipete/ordercli/internal/provider"
 )
 
-func newGlovoCmd(st *state) *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "glovo",
-		Short: "Glovo",
+func glovoCommands(st *state) []*cobra.Command {
+	return []*cobra.Command{
+		newGlovoConfigCmd(st),
+		newGlovoLoginCmd(st),
+		newGlovoSessionCmd(st),
+		newGlovoLogoutCmd(st),
+		newGlovoHistoryCmd(st),
+		newGlovoOrderCmd(st),
+		newGlovoOrdersCmd(st),
+		newGlovoCartCmd(st),
+		newGlovoMeCmd(st),
 	}
-	cmd.AddCommand(newGlovoConfigCmd(st))
-	cmd.AddCommand(newGlovoSessionCmd(st))
-	cmd.AddCommand(newGlovoLogoutCmd(st))
-	cmd.AddCommand(newGlovoHistoryCmd(st))
-	cmd.AddCommand(newGlovoOrderCmd(st))
-	cmd.AddCommand(newGlovoOrdersCmd(st))
-	cmd.AddCommand(newGlovoCartCmd(st))
-	cmd.AddCommand(newGlovoMeCmd(st))
-	return cmd
 }
 
-func newGlovoClient(st *state) (*glovo.Client, error) {
+func newGlovoClient(ctx context.Context, st *state) (*glovo.Client, error) {
 	cfg := st.glovo()
-	return glovo.New(glovo.Options{
+	if !cfg.HasSession() {
+		return nil, errors.New("not logged in (run `ordercli glovo login --email ...` or `ordercli glovo session <token>`)")
+	}
+
+	// The token is renewed on first use when expired and again after a 401.
+	account := st.accountFor("glovo")
+	var c *glovo.Client
+	tok, err := st.resolveToken(ctx, cfg.AccessToken, cfg.RefreshToken, cfg.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if tok.RefreshToken == "" && !tok.ExpiresAt.IsZero() && tok.ExpiresAt.Before(time.Now()) {
+		st.warnf("glovo access token has expired and no refresh token is stored; run `ordercli glovo login`")
+	}
+	tokens := auth.NewRefresher(tok, func(ctx context.Context, cur auth.Token) (auth.Token, error) {
+		now := time.Now()
+		t, err := c.Refresh(ctx, cur.RefreshToken)
+		if err != nil {
+			var he *glovo.HTTPError
+			if errors.As(err, &he) && (he.IsUnauthorized() || he.StatusCode == 400) {
+				return auth.Token{}, fmt.Errorf("glovo refresh token rejected; run `ordercli glovo login` again: %w", err)
+			}
+			return auth.Token{}, err
+		}
+		var saved config.GlovoConfig
+		storeGlovoToken(&saved, t, now)
+		return auth.Token{AccessToken: saved.AccessToken, RefreshToken: saved.RefreshToken, ExpiresAt: saved.ExpiresAt}, nil
+	}, func(t auth.Token) {
+		// Look the config up again: a sync may have replaced it since.
+		stored := true
+		st.update(func() {
+			cfg := st.cfg.GlovoAccount(account)
+			stored = storeRefreshedToken(&cfg.AccessToken, &cfg.RefreshToken, &cfg.ExpiresAt, t)
+		})
+		if !stored {
+			st.warnRefreshedRef("glovo", account)
+		}
+	})
+
+	httpOpts, err := st.httpOptions(cfg.Network)
+	if err != nil {
+		return nil, err
+	}
+	c, err = glovo.New(glovo.Options{
 		BaseURL:     cfg.BaseURL,
-		AccessToken: cfg.AccessToken,
+		TokenSource: tokens,
 		DeviceURN:   cfg.DeviceURN,
 		CityCode:    cfg.CityCode,
 		CountryCode: cfg.CountryCode,
 		Language:    cfg.Language,
 		Latitude:    cfg.Latitude,
 		Longitude:   cfg.Longitude,
+		HTTP:        httpOpts,
 	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// storeGlovoToken saves a login/refresh response. Glovo may omit the refresh
+// token on refresh; the old one stays valid then.
+func storeGlovoToken(cfg *config.GlovoConfig, tok glovo.AuthToken, now time.Time) {
+	cfg.AccessToken = tok.AccessToken
+	if tok.RefreshToken != "" {
+		cfg.RefreshToken = tok.RefreshToken
+	}
+	cfg.ExpiresAt = tok.ExpiresAt(now)
+	if cfg.ExpiresAt.IsZero() {
+		if exp, ok := config.AccessTokenExpiresAt(tok.AccessToken); ok {
+			cfg.ExpiresAt = exp
+		}
+	}
 }
 
 // Config commands
@@ -65,11 +135,20 @@ func newGlovoConfigShowCmd(st *state) *cobra.Command {
 			fmt.Fprintf(cmd.OutOrStdout(), "language=%s\n", cfg.Language)
 			fmt.Fprintf(cmd.OutOrStdout(), "latitude=%v\n", cfg.Latitude)
 			fmt.Fprintf(cmd.OutOrStdout(), "longitude=%v\n", cfg.Longitude)
-			if cfg.AccessToken != "" {
+			if secretRefKind(cfg.AccessToken) != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "access_token=%s\n", secretLabel(cfg.AccessToken))
+			} else if cfg.AccessToken != "" {
 				fmt.Fprintf(cmd.OutOrStdout(), "access_token=%s...\n", cfg.AccessToken[:min(20, len(cfg.AccessToken))])
 			} else {
 				fmt.Fprintf(cmd.OutOrStdout(), "access_token=(not set)\n")
 			}
+			if cfg.RefreshToken != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "refresh_token=%s\n", secretLabel(cfg.RefreshToken))
+			}
+			if !cfg.ExpiresAt.IsZero() {
+				fmt.Fprintf(cmd.OutOrStdout(), "expires_at=%s\n", cfg.ExpiresAt.Local().Format(time.RFC3339))
+			}
+			printNetworkConfig(cmd.OutOrStdout(), cfg.Network)
 		},
 	}
 }
@@ -77,12 +156,13 @@ func newGlovoConfigShowCmd(st *state) *cobra.Command {
 func newGlovoConfigSetCmd(st *state) *cobra.Command {
 	var cityCode, countryCode, language, baseURL string
 	var lat, lon float64
+	var network networkConfigFlags
 
 	cmd := &cobra.Command{
 		Use:   "set",
 		Short: "Update Glovo config",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := st.glovo()
+			cfg := st.ensureGlovo()
 			changed := false
 
 			if strings.TrimSpace(cityCode) != "" {
@@ -109,9 +189,14 @@ func newGlovoConfigSetCmd(st *state) *cobra.Command {
 				cfg.Longitude = lon
 				changed = true
 			}
+			netChanged, err := network.apply(st, cmd, &cfg.Network)
+			if err != nil {
+				return err
+			}
+			changed = changed || netChanged
 
 			if !changed {
-				return fmt.Errorf("nothing to set (use --city-code, --country-code, --language, --lat, --lon, or --base-url)")
+				return fmt.Errorf("nothing to set (use --city-code, --country-code, --language, --lat, --lon, --base-url, or --proxy/--ca-file/--http-timeout/--insecure-skip-verify)")
 			}
 			st.markDirty()
 			fmt.Fprintln(cmd.OutOrStdout(), "config updated")
@@ -123,15 +208,99 @@ func newGlovoConfigSetCmd(st *state) *cobra.Command {
 	cmd.Flags().StringVar(&countryCode, "country-code", "", "country code (e.g. ES)")
 	cmd.Flags().StringVar(&language, "language", "", "language code (e.g. en)")
 	cmd.Flags().StringVar(&baseURL, "base-url", "", "API base URL")
+	addNetworkConfigFlags(cmd, &network)
 	cmd.Flags().Float64Var(&lat, "lat", 0, "delivery latitude")
 	cmd.Flags().Float64Var(&lon, "lon", 0, "delivery longitude")
 	return cmd
 }
 
+// Login command
+
+func newGlovoLoginCmd(st *state) *cobra.Command {
+	var email string
+	var password string
+	var passwordStdin bool
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Login with email + password (stores access and refresh token)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(email) == "" {
+				return errors.New("--email required")
+			}
+			if passwordStdin {
+				b, err := bufio.NewReader(os.Stdin).ReadBytes('\n')
+				if err != nil && !errors.Is(err, io.EOF) {
+					return err
+				}
+				password = strings.TrimSpace(string(b))
+			}
+			if password == "" {
+				fmt.Fprint(cmd.ErrOrStderr(), "Password: ")
+				b, err := term.ReadPassword(int(os.Stdin.Fd()))
+				fmt.Fprintln(cmd.ErrOrStderr())
+				if err != nil {
+					return err
+				}
+				password = strings.TrimSpace(string(b))
+			}
+			var err error
+			if password, err = st.resolveSecret(cmd.Context(), password); err != nil {
+				return err
+			}
+			if password == "" {
+				return errors.New("empty password")
+			}
+
+			cfg := st.ensureGlovo()
+			httpOpts, err := st.httpOptions(cfg.Network)
+			if err != nil {
+				return err
+			}
+			c, err := glovo.New(glovo.Options{
+				BaseURL:     cfg.BaseURL,
+				DeviceURN:   cfg.DeviceURN,
+				CityCode:    cfg.CityCode,
+				CountryCode: cfg.CountryCode,
+				Language:    cfg.Language,
+				Latitude:    cfg.Latitude,
+				Longitude:   cfg.Longitude,
+				HTTP:        httpOpts,
+			})
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			tok, err := c.Login(cmd.Context(), strings.TrimSpace(email), password)
+			if err != nil {
+				return err
+			}
+			storeGlovoToken(cfg, tok, now)
+			st.markDirty()
+
+			fmt.Fprintln(cmd.OutOrStdout(), "logged in")
+			if !cfg.ExpiresAt.IsZero() {
+				fmt.Fprintf(cmd.OutOrStdout(), "expires_at=%s\n", cfg.ExpiresAt.Local().Format(time.RFC3339))
+			}
+			if cfg.RefreshToken == "" {
+				fmt.Fprintln(cmd.ErrOrStderr(), "warning: no refresh token returned; you will need to log in again when the token expires")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&email, "email", "", "account email")
+	cmd.Flags().StringVar(&password, "password", "", "password (prefer --password-stdin)")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "read password from stdin")
+	return cmd
+}
+
 // Session command
 
 func newGlovoSessionCmd(st *state) *cobra.Command {
-	return &cobra.Command{
+	var refreshToken string
+
+	cmd := &cobra.Command{
 		Use:   "session <access_token>",
 		Short: "Set access token (from browser localStorage glovo_auth_info)",
 		Args:  cobra.ExactArgs(1),
@@ -141,14 +310,22 @@ func newGlovoSessionCmd(st *state) *cobra.Command {
 				return fmt.Errorf("access token cannot be empty")
 			}
 
-			cfg := st.glovo()
+			cfg := st.ensureGlovo()
 			cfg.AccessToken = token
+			// A pasted token replaces the whole session; keep only what belongs to it.
+			cfg.RefreshToken = strings.TrimSpace(refreshToken)
+			cfg.ExpiresAt = time.Time{}
+			if exp, ok := config.AccessTokenExpiresAt(token); ok {
+				cfg.ExpiresAt = exp
+			}
 			st.markDirty()
 
 			fmt.Fprintln(cmd.OutOrStdout(), "access token saved")
 			return nil
 		},
 	}
+	cmd.Flags().StringVar(&refreshToken, "refresh-token", "", "refresh token (glovo_auth_info.refreshToken) for automatic renewal")
+	return cmd
 }
 
 // Logout command
@@ -156,10 +333,12 @@ func newGlovoSessionCmd(st *state) *cobra.Command {
 func newGlovoLogoutCmd(st *state) *cobra.Command {
 	return &cobra.Command{
 		Use:   "logout",
-		Short: "Clear stored access token",
+		Short: "Clear stored tokens",
 		Run: func(cmd *cobra.Command, args []string) {
 			cfg := st.glovo()
 			cfg.AccessToken = ""
+			cfg.RefreshToken = ""
+			cfg.ExpiresAt = time.Time{}
 			cfg.DeviceURN = ""
 			st.markDirty()
 			fmt.Fprintln(cmd.OutOrStdout(), "logged out")
@@ -177,7 +356,7 @@ func newGlovoHistoryCmd(st *state) *cobra.Command {
 		Use:   "history",
 		Short: "List past orders",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl, err := newGlovoClient(st)
+			cl, err := newGlovoClient(cmd.Context(), st)
 			if err != nil {
 				return err
 			}
@@ -187,52 +366,62 @@ func newGlovoHistoryCmd(st *state) *cobra.Command {
 				return err
 			}
 
+			out := cmd.OutOrStdout()
 			if asJSON {
-				enc := json.NewEncoder(cmd.OutOrStdout())
-				enc.SetIndent("", "  ")
-				return enc.Encode(resp)
+				return st.writeOrdersJSON(out, glovoOrders(resp.Orders), resp)
 			}
-
-			out := cmd.OutOrStdout()
-			if len(resp.Orders) == 0 {
-				fmt.Fprintln(out, "no orders")
+			return st.renderList(out, orderRecords(glovoOrders(resp.Orders)), func() error {
+				printGlovoHistory(out, resp.Orders)
 				return nil
-			}
+			})
+		},
+	}
 
-			for _, o := range resp.Orders {
-				title := o.Content.Title
-				price := ""
-				if o.Footer.Left != nil {
-					price = o.Footer.Left.DataString()
-				}
+	cmd.Flags().IntVar(&offset, "offset", 0, "paging offset")
+	cmd.Flags().IntVar(&limit, "limit", 12, "paging limit")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON (see --schema)")
+	return cmd
+}
 
-				items := ""
-				if len(o.Content.Body) > 0 {
-					// Get first few items
-					itemText := o.Content.Body[0].Data
-					lines := strings.Split(itemText, "\n")
-					if len(lines) > 3 {
-						items = strings.Join(lines[:3], ", ") + "..."
-					} else {
-						items = strings.Join(lines, ", ")
-					}
-				}
+func printGlovoHistory(out io.Writer, orders []glovo.Order) {
+	if len(orders) == 0 {
+		fmt.Fprintln(out, "no orders")
+		return
+	}
 
-				fmt.Fprintf(out, "[%d] %s - %s\n", o.OrderID, title, price)
-				if items != "" {
-					fmt.Fprintf(out, "    %s\n", items)
-				}
-				fmt.Fprintln(out)
+	for _, o := range orders {
+		title := o.Content.Title
+		price := ""
+		if o.Footer.Left != nil {
+			price = o.Footer.Left.DataString()
+		}
+
+		items := ""
+		if len(o.Content.Body) > 0 {
+			// Get first few items
+			itemText := o.Content.Body[0].Data
+			lines := strings.Split(itemText, "\n")
+			if len(lines) > 3 {
+				items = strings.Join(lines[:3], ", ") + "..."
+			} else {
+				items = strings.Join(lines, ", ")
 			}
+		}
 
-			return nil
-		},
+		fmt.Fprintf(out, "[%d] %s - %s\n", o.OrderID, title, price)
+		if items != "" {
+			fmt.Fprintf(out, "    %s\n", items)
+		}
+		fmt.Fprintln(out)
 	}
+}
 
-	cmd.Flags().IntVar(&offset, "offset", 0, "paging offset")
-	cmd.Flags().IntVar(&limit, "limit", 12, "paging limit")
-	cmd.Flags().BoolVar(&asJSON, "json", false, "print raw JSON")
-	return cmd
+func glovoOrders(orders []glovo.Order) []provider.Order {
+	normalized := make([]provider.Order, 0, len(orders))
+	for _, o := range orders {
+		normalized = append(normalized, provider.GlovoOrder(o))
+	}
+	return normalized
 }
 
 // Order command (single order details)
@@ -250,7 +439,7 @@ func newGlovoOrderCmd(st *state) *cobra.Command {
 				return fmt.Errorf("invalid order ID: %s", args[0])
 			}
 
-			cl, err := newGlovoClient(st)
+			cl, err := newGlovoClient(cmd.Context(), st)
 			if err != nil {
 				return err
 			}
@@ -261,42 +450,45 @@ func newGlovoOrderCmd(st *state) *cobra.Command {
 			}
 
 			if asJSON {
-				enc := json.NewEncoder(cmd.OutOrStdout())
-				enc.SetIndent("", "  ")
-				return enc.Encode(order)
+				return st.writeOrderJSON(cmd.OutOrStdout(), provider.GlovoOrder(order), order)
 			}
 
 			out := cmd.OutOrStdout()
-			fmt.Fprintf(out, "Order ID: %d\n", order.OrderID)
-			fmt.Fprintf(out, "Store: %s\n", order.Content.Title)
-			fmt.Fprintf(out, "Status: %s\n", order.LayoutType)
-			if order.Footer.Left != nil {
-				fmt.Fprintf(out, "Total: %s\n", order.Footer.Left.DataString())
-			}
-			if order.CourierName != nil {
-				fmt.Fprintf(out, "Courier: %s\n", *order.CourierName)
-			}
-
-			if len(order.Content.Body) > 0 {
-				fmt.Fprintln(out, "\nItems:")
-				for _, b := range order.Content.Body {
-					lines := strings.Split(b.Data, "\n")
-					for _, line := range lines {
-						if line = strings.TrimSpace(line); line != "" {
-							fmt.Fprintf(out, "  - %s\n", line)
-						}
-					}
-				}
-			}
-
-			return nil
+			return st.renderDetail(out, orderDetailRecords(provider.GlovoOrder(order)), func() error {
+				printGlovoOrder(out, order)
+				return nil
+			})
 		},
 	}
 
-	cmd.Flags().BoolVar(&asJSON, "json", false, "print raw JSON")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON (see --schema)")
 	return cmd
 }
 
+func printGlovoOrder(out io.Writer, order glovo.Order) {
+	fmt.Fprintf(out, "Order ID: %d\n", order.OrderID)
+	fmt.Fprintf(out, "Store: %s\n", order.Content.Title)
+	fmt.Fprintf(out, "Status: %s\n", order.LayoutType)
+	if order.Footer.Left != nil {
+		fmt.Fprintf(out, "Total: %s\n", order.Footer.Left.DataString())
+	}
+	if order.CourierName != nil {
+		fmt.Fprintf(out, "Courier: %s\n", *order.CourierName)
+	}
+
+	if len(order.Content.Body) > 0 {
+		fmt.Fprintln(out, "\nItems:")
+		for _, b := range order.Content.Body {
+			lines := strings.Split(b.Data, "\n")
+			for _, line := range lines {
+				if line = strings.TrimSpace(line); line != "" {
+					fmt.Fprintf(out, "  - %s\n", line)
+				}
+			}
+		}
+	}
+}
+
 // Orders command (active orders tracking)
 
 func newGlovoOrdersCmd(st *state) *cobra.Command {
@@ -308,7 +500,7 @@ func newGlovoOrdersCmd(st *state) *cobra.Command {
 		Use:   "orders",
 		Short: "Show active orders (being delivered)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl, err := newGlovoClient(st)
+			cl, err := newGlovoClient(cmd.Context(), st)
 			if err != nil {
 				return err
 			}
@@ -322,38 +514,57 @@ func newGlovoOrdersCmd(st *state) *cobra.Command {
 				}
 
 				if asJSON {
-					enc := json.NewEncoder(out)
-					enc.SetIndent("", "  ")
-					return enc.Encode(orders)
+					return st.writeOrdersJSON(out, glovoOrders(orders), orders)
 				}
 
-				if len(orders) == 0 {
-					fmt.Fprintln(out, "no active orders")
-					return nil
-				}
+				return st.renderList(out, orderRecords(glovoOrders(orders)), func() error {
+					if len(orders) == 0 {
+						fmt.Fprintln(out, "no active orders")
+						return nil
+					}
 
-				for _, o := range orders {
-					title := o.Content.Title
-					status := o.LayoutType
+					for _, o := range orders {
+						title := o.Content.Title
+						status := o.LayoutType
 
-					fmt.Fprintf(out, "[%d] %s\n", o.OrderID, title)
-					fmt.Fprintf(out, "    Status: %s\n", status)
-					if o.CourierName != nil {
-						fmt.Fprintf(out, "    Courier: %s\n", *o.CourierName)
+						fmt.Fprintf(out, "[%d] %s\n", o.OrderID, title)
+						fmt.Fprintf(out, "    Status: %s\n", status)
+						if o.CourierName != nil {
+							fmt.Fprintf(out, "    Courier: %s\n", *o.CourierName)
+						}
+						fmt.Fprintln(out)
 					}
-					fmt.Fprintln(out)
-				}
-				return nil
+					return nil
+				})
 			}
 
 			if watch {
+				token := st.glovo().AccessToken
 				for {
+					// Save refreshed tokens now and pick up ones other processes saved.
+					if err := st.sync(); err != nil {
+						return err
+					}
+					// The client renews its own token; reopen only for a session
+					// another process saved.
+					if st.glovo().AccessToken != token {
+						if cl, err = newGlovoClient(cmd.Context(), st); err != nil {
+							return err
+						}
+					}
 					// Clear screen for fresh output
 					fmt.Fprint(out, "\033[2J\033[H")
 					fmt.Fprintf(out, "Active Orders (refreshing every %ds, Ctrl+C to stop)\n\n", interval)
 					if err := printOrders(); err != nil {
 						fmt.Fprintf(out, "Error: %v\n", err)
 					}
+					if st.isDirty() {
+						// Save a token the client refreshed during this round.
+						if err := st.sync(); err != nil {
+							return err
+						}
+					}
+					token = st.glovo().AccessToken
 					select {
 					case <-cmd.Context().Done():
 						return nil
@@ -367,7 +578,7 @@ func newGlovoOrdersCmd(st *state) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().BoolVar(&asJSON, "json", false, "print raw JSON")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON (see --schema)")
 	cmd.Flags().BoolVar(&watch, "watch", false, "continuously poll for updates")
 	cmd.Flags().IntVar(&interval, "interval", 30, "polling interval in seconds (with --watch)")
 	return cmd
@@ -382,7 +593,7 @@ func newGlovoCartCmd(st *state) *cobra.Command {
 		Use:   "cart",
 		Short: "Show shopping cart (saved baskets)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl, err := newGlovoClient(st)
+			cl, err := newGlovoClient(cmd.Context(), st)
 			if err != nil {
 				return err
 			}
@@ -405,32 +616,16 @@ func newGlovoCartCmd(st *state) *cobra.Command {
 			}
 
 			out := cmd.OutOrStdout()
-			if len(baskets) == 0 {
-				fmt.Fprintln(out, "cart is empty")
-				return nil
-			}
-
+			r := newRecords("store_id", "store", "product", "quantity", "total", "currency")
 			for _, b := range baskets {
-				fmt.Fprintf(out, "Store: %s (ID: %d)\n", b.StoreName, b.StoreID)
-				fmt.Fprintf(out, "  Items:\n")
 				for _, p := range b.Products {
-					fmt.Fprintf(out, "    %dx %s - %.2f %s\n", p.Quantity, p.Name, p.TotalPrice, b.Currency)
-				}
-				fmt.Fprintf(out, "  Subtotal: %.2f %s\n", b.SubTotal, b.Currency)
-				if b.DeliveryFee > 0 {
-					fmt.Fprintf(out, "  Delivery: %.2f %s\n", b.DeliveryFee, b.Currency)
-				}
-				if b.ServiceFee > 0 {
-					fmt.Fprintf(out, "  Service: %.2f %s\n", b.ServiceFee, b.Currency)
+					r.add(b.StoreID, b.StoreName, p.Name, p.Quantity, p.TotalPrice, b.Currency)
 				}
-				fmt.Fprintf(out, "  Total: %.2f %s\n", b.Total, b.Currency)
-				if !b.IsMinOrderMet {
-					fmt.Fprintf(out, "  ! Min order: %.2f %s\n", b.MinOrderValue, b.Currency)
-				}
-				fmt.Fprintln(out)
 			}
-
-			return nil
+			return st.renderList(out, r, func() error {
+				printGlovoBaskets(out, baskets)
+				return nil
+			})
 		},
 	}
 
@@ -438,6 +633,33 @@ func newGlovoCartCmd(st *state) *cobra.Command {
 	return cmd
 }
 
+func printGlovoBaskets(out io.Writer, baskets []glovo.Basket) {
+	if len(baskets) == 0 {
+		fmt.Fprintln(out, "cart is empty")
+		return
+	}
+
+	for _, b := range baskets {
+		fmt.Fprintf(out, "Store: %s (ID: %d)\n", b.StoreName, b.StoreID)
+		fmt.Fprintf(out, "  Items:\n")
+		for _, p := range b.Products {
+			fmt.Fprintf(out, "    %dx %s - %.2f %s\n", p.Quantity, p.Name, p.TotalPrice, b.Currency)
+		}
+		fmt.Fprintf(out, "  Subtotal: %.2f %s\n", b.SubTotal, b.Currency)
+		if b.DeliveryFee > 0 {
+			fmt.Fprintf(out, "  Delivery: %.2f %s\n", b.DeliveryFee, b.Currency)
+		}
+		if b.ServiceFee > 0 {
+			fmt.Fprintf(out, "  Service: %.2f %s\n", b.ServiceFee, b.Currency)
+		}
+		fmt.Fprintf(out, "  Total: %.2f %s\n", b.Total, b.Currency)
+		if !b.IsMinOrderMet {
+			fmt.Fprintf(out, "  ! Min order: %.2f %s\n", b.MinOrderValue, b.Currency)
+		}
+		fmt.Fprintln(out)
+	}
+}
+
 // Me command
 
 func newGlovoMeCmd(st *state) *cobra.Command {
@@ -447,7 +669,7 @@ func newGlovoMeCmd(st *state) *cobra.Command {
 		Use:   "me",
 		Short: "Show current user profile",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cl, err := newGlovoClient(st)
+			cl, err := newGlovoClient(cmd.Context(), st)
 			if err != nil {
 				return err
 			}
@@ -463,18 +685,26 @@ func newGlovoMeCmd(st *state) *cobra.Command {
 				return enc.Encode(user)
 			}
 
-			out := cmd.OutOrStdout()
-			fmt.Fprintf(out, "ID: %d\n", user.ID)
-			fmt.Fprintf(out, "Name: %s\n", user.Name)
-			fmt.Fprintf(out, "Email: %s\n", user.Email)
+			phone := ""
 			if user.PhoneNumber != nil {
-				fmt.Fprintf(out, "Phone: %s\n", user.PhoneNumber.Number)
+				phone = user.PhoneNumber.Number
 			}
-			fmt.Fprintf(out, "City: %s\n", user.PreferredCityCode)
-			fmt.Fprintf(out, "Language: %s\n", user.PreferredLanguage)
-			fmt.Fprintf(out, "Orders: %d\n", user.DeliveredOrdersCount)
+			r := newRecords("id", "name", "email", "phone", "city", "language", "delivered_orders")
+			r.add(user.ID, user.Name, user.Email, phone, user.PreferredCityCode, user.PreferredLanguage, user.DeliveredOrdersCount)
 
-			return nil
+			out := cmd.OutOrStdout()
+			return st.renderDetail(out, r, func() error {
+				fmt.Fprintf(out, "ID: %d\n", user.ID)
+				fmt.Fprintf(out, "Name: %s\n", user.Name)
+				fmt.Fprintf(out, "Email: %s\n", user.Email)
+				if user.PhoneNumber != nil {
+					fmt.Fprintf(out, "Phone: %s\n", user.PhoneNumber.Number)
+				}
+				fmt.Fprintf(out, "City: %s\n", user.PreferredCityCode)
+				fmt.Fprintf(out, "Language: %s\n", user.PreferredLanguage)
+				fmt.Fprintf(out, "Orders: %d\n", user.DeliveredOrdersCount)
+				return nil
+			})
 		},
 	}
 
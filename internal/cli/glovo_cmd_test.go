@@ -1,11 +1,15 @@
 package cli
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
 )
 
 func TestGlovoCLI_ConfigSetAndShow(t *testing.T) {
@@ -160,3 +164,102 @@ func TestGlovoCLI_MissingToken(t *testing.T) {
 		t.Fatalf("expected error when token missing")
 	}
 }
+
+func TestGlovoCLI_LoginAndRefresh(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+
+	var refreshes int
+	var historyAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["grantType"] != "password" || body["username"] != "a@b.c" || body["password"] != "pw" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			// Inside the 30s expiry margin, so the next command refreshes.
+			_, _ = w.Write([]byte(`{"accessToken":"at-1","refreshToken":"rt-1","expiresIn":1}`))
+		case "/oauth/refresh":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("refresh sent Authorization %q", r.Header.Get("Authorization"))
+			}
+			if body["refreshToken"] != "rt-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			refreshes++
+			_, _ = w.Write([]byte(`{"accessToken":"at-2","expiresIn":3600}`))
+		case "/v3/customer/orders-list":
+			historyAuth = r.Header.Get("Authorization")
+			_, _ = w.Write([]byte(`{"pagination":{"currentLimit":12},"orders":[]}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "config", "set", "--base-url", srv.URL}, ""); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "login", "--email", "a@b.c", "--password", "wrong"}, ""); err == nil {
+		t.Fatalf("expected login error")
+	}
+	out, _, err := runCLI(cfgPath, []string{"glovo", "login", "--email", "a@b.c", "--password-stdin"}, "pw\n")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if !strings.Contains(out, "logged in") {
+		t.Fatalf("unexpected login output: %s", out)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "history"}, ""); err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if refreshes != 1 || historyAuth != "Bearer at-2" {
+		t.Fatalf("refreshes=%d auth=%q", refreshes, historyAuth)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	g := cfg.Glovo()
+	if g.AccessToken != "at-2" || g.RefreshToken != "rt-1" || time.Until(g.ExpiresAt) < 30*time.Minute {
+		t.Fatalf("refreshed token not stored: %+v", g)
+	}
+
+	// A fresh token is used as is.
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "history"}, ""); err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if refreshes != 1 {
+		t.Fatalf("unexpected refresh of a valid token")
+	}
+}
+
+func TestGlovoCLI_RefreshRejected(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	cfg := config.New()
+	g := cfg.Glovo()
+	g.BaseURL = srv.URL
+	g.AccessToken = "old"
+	g.RefreshToken = "revoked"
+	g.ExpiresAt = time.Now().Add(-time.Hour)
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	_, _, err := runCLI(cfgPath, []string{"glovo", "history"}, "")
+	if err == nil || !strings.Contains(err.Error(), "ordercli glovo login") {
+		t.Fatalf("expected re-login hint, got %v", err)
+	}
+}
@@ -1,11 +1,13 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steipete/ordercli/internal/foodora"
+	"github.com/steipete/ordercli/internal/provider"
 )
 
 func newHistoryCmd(st *state) *cobra.Command {
@@ -13,75 +15,51 @@ func newHistoryCmd(st *state) *cobra.Command {
 	var pageSize int
 	var include string
 	var pandagoEnabled bool
+	var asJSON bool
 
 	cmd := &cobra.Command{
 		Use:   "history",
 		Short: "List past orders",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := newAuthedClient(st)
+			c, err := newAuthedClient(cmd.Context(), st)
 			if err != nil {
 				return err
 			}
 
-			limit := totalLimit
-			if limit <= 0 {
-				limit = 20
-			}
-			ps := pageSize
-			if ps <= 0 {
-				ps = 20
-			}
-			if ps > 100 {
-				ps = 100
+			items, err := fetchFoodoraHistory(cmd.Context(), c, foodora.OrderHistoryRequest{
+				Include:        include,
+				Limit:          pageSize,
+				PandaGoEnabled: pandagoEnabled,
+			}, totalLimit)
+			if err != nil {
+				return err
 			}
 
 			out := cmd.OutOrStdout()
-			ctx := cmd.Context()
-
-			offset := 0
-			printed := 0
-			for printed < limit {
-				reqLimit := min(ps, limit-printed)
-				resp, err := c.OrderHistory(ctx, foodora.OrderHistoryRequest{
-					Include:        include,
-					Offset:         offset,
-					Limit:          reqLimit,
-					PandaGoEnabled: pandagoEnabled,
-				})
-				if err != nil {
-					return err
-				}
-
-				if len(resp.Data.Items) == 0 {
-					if printed == 0 {
-						fmt.Fprintln(out, "no past orders")
-					}
+			currency := currencyForTargetISO(st.foodora().TargetCountryISO)
+			orders := make([]provider.Order, 0, len(items))
+			for _, it := range items {
+				orders = append(orders, provider.FoodoraHistoryOrder(it, currency))
+			}
+			if asJSON {
+				return st.writeOrdersJSON(out, orders, items)
+			}
+			return st.renderList(out, orderRecords(orders), func() error {
+				if len(items) == 0 {
+					fmt.Fprintln(out, "no past orders")
 					return nil
 				}
-
-				for _, o := range resp.Data.Items {
-					if printed >= limit {
-						return nil
-					}
+				for _, o := range items {
 					fmt.Fprintf(out, "%s\t%s\t%s\t%s\n",
 						o.OrderCode,
 						historyVendor(o.Vendor),
 						historyStatus(o.CurrentStatus),
 						historyTime(o.ConfirmedDeliveryTime),
 					)
-					printed++
-				}
-
-				offset += len(resp.Data.Items)
-				if resp.Data.TotalCount > 0 && offset >= int(resp.Data.TotalCount) {
-					return nil
 				}
-				if len(resp.Data.Items) < reqLimit {
-					return nil
-				}
-			}
-			return nil
+				return nil
+			})
 		},
 	}
 
@@ -89,11 +67,57 @@ func newHistoryCmd(st *state) *cobra.Command {
 	cmd.Flags().IntVar(&pageSize, "page-size", 20, "page size (API limit)")
 	cmd.Flags().StringVar(&include, "include", "order_products,order_details", "include fields")
 	cmd.Flags().BoolVar(&pandagoEnabled, "pandago-enabled", false, "set pandago_enabled=true")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON (see --schema)")
 
 	cmd.AddCommand(newHistoryShowCmd(st))
 	return cmd
 }
 
+// fetchFoodoraHistory pages through orders/order_history until limit items are
+// collected or the API runs out. req.Limit is the page size; req.Offset is ignored.
+func fetchFoodoraHistory(ctx context.Context, c *foodora.Client, req foodora.OrderHistoryRequest, limit int) ([]foodora.OrderHistoryItem, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	ps := req.Limit
+	if ps <= 0 {
+		ps = 20
+	}
+	if ps > 100 {
+		ps = 100
+	}
+
+	var items []foodora.OrderHistoryItem
+	offset := 0
+	for len(items) < limit {
+		reqLimit := min(ps, limit-len(items))
+		req.Offset = offset
+		req.Limit = reqLimit
+		resp, err := c.OrderHistory(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Data.Items) == 0 {
+			break
+		}
+		for _, o := range resp.Data.Items {
+			if len(items) >= limit {
+				break
+			}
+			items = append(items, o)
+		}
+
+		offset += len(resp.Data.Items)
+		if resp.Data.TotalCount > 0 && offset >= int(resp.Data.TotalCount) {
+			break
+		}
+		if len(resp.Data.Items) < reqLimit {
+			break
+		}
+	}
+	return items, nil
+}
+
 func historyVendor(v *foodora.OrderHistoryVendor) string {
 	if v == nil {
 		return ""
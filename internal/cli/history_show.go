@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +11,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steipete/ordercli/internal/foodora"
+	"github.com/steipete/ordercli/internal/provider"
 )
 
 func newHistoryShowCmd(st *state) *cobra.Command {
@@ -24,7 +24,7 @@ func newHistoryShowCmd(st *state) *cobra.Command {
 		Short: "Show details for a historical order (orders/order_history?order_code=...)",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := newAuthedClient(st)
+			c, err := newAuthedClient(cmd.Context(), st)
 			if err != nil {
 				return err
 			}
@@ -43,22 +43,21 @@ func newHistoryShowCmd(st *state) *cobra.Command {
 
 			item := resp.Data.Items[0]
 			out := cmd.OutOrStdout()
-
+			o := provider.FoodoraDetailOrder(item, currencyForTargetISO(st.foodora().TargetCountryISO))
 			if asJSON {
-				b, _ := json.MarshalIndent(item, "", "  ")
-				b = append(b, '\n')
-				_, _ = out.Write(b)
-				return nil
+				return st.writeOrderJSON(out, o, item)
 			}
 
-			printHistoryDetail(out, item)
-			return nil
+			return st.renderDetail(out, orderDetailRecords(o), func() error {
+				printHistoryDetail(out, item)
+				return nil
+			})
 		},
 	}
 
 	cmd.Flags().StringVar(&include, "include", "order_products,order_details", "include fields")
 	cmd.Flags().BoolVar(&itemReplacement, "item-replacement", false, "set item_replacement=true")
-	cmd.Flags().BoolVar(&asJSON, "json", false, "print raw JSON")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON (see --schema)")
 	return cmd
 }
 
@@ -3,6 +3,8 @@ package cli
 import (
 	"net/url"
 	"strings"
+
+	"github.com/steipete/ordercli/internal/config"
 )
 
 type appHeaderProfile struct {
@@ -28,11 +30,27 @@ func (s *state) cookieHeaderForBaseURL() (host string, cookie string) {
 	return host, strings.TrimSpace(cfg.CookiesByHost[host])
 }
 
+// appProfile returns the imported app profile the foodora account cfg
+// selected (nil for the built-in values, including when the name isn't
+// imported).
+func (s *state) appProfile(cfg *config.FoodoraConfig) *config.AppProfile {
+	name := cfg.AppProfile
+	if name == "" {
+		return nil
+	}
+	return s.cfg.AppProfiles[name]
+}
+
 func (s *state) appHeaders() appHeaderProfile {
 	cfg := s.foodora()
 	p := appHeaderProfile{
 		FPAPIKey: "android",
 	}
+	if ap := s.appProfile(cfg); ap != nil {
+		p.AppName = ap.PackageName
+		p.UserAgent = ap.UserAgent()
+		return p
+	}
 	if strings.EqualFold(cfg.TargetCountryISO, "AT") || strings.HasPrefix(strings.ToUpper(cfg.GlobalEntityID), "MJM_") || strings.Contains(strings.ToLower(cfg.BaseURL), "mj.fd-api.com") {
 		p.AppName = "at.mjam"
 		// From the provided at.mjam APKM (v25.3.0 / build 250300134).
@@ -18,7 +18,7 @@ func TestCookieHost(t *testing.T) {
 func TestAppHeaders_AT(t *testing.T) {
 	st := &state{}
 	st.cfg = config.New()
-	cfg := st.foodora()
+	cfg := st.ensureFoodora()
 	cfg.TargetCountryISO = "AT"
 	p := st.appHeaders()
 	if p.AppName != "at.mjam" || p.FPAPIKey == "" || p.UserAgent == "" {
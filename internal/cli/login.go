@@ -38,7 +38,7 @@ func newLoginCmd(st *state) *cobra.Command {
 		Use:   "login",
 		Short: "Login via oauth2/token (email + password; optional MFA)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := st.foodora()
+			cfg := st.ensureFoodora()
 			if cfg.BaseURL == "" {
 				return errors.New("missing base_url (run `ordercli foodora config set --country HU` or similar)")
 			}
@@ -63,15 +63,22 @@ func newLoginCmd(st *state) *cobra.Command {
 			explicitClientSecret := cmd.Flags().Changed("client-secret")
 			if clientSecret == "" {
 				// Prefer cached/env; if missing, auto-fetch via Remote Config and cache.
-				sec, err := st.resolveClientSecret(cmd.Context(), clientID)
+				sec, err := st.resolveClientSecret(cmd.Context(), cfg, clientID)
 				if err != nil {
 					return err
 				}
 				clientSecret = sec.Secret
-			} else if storeClientSecret {
-				cfg.ClientSecret = clientSecret
-				cfg.OAuthClientID = clientID
-				st.markDirty()
+			} else {
+				if storeClientSecret {
+					// A reference is stored as given, never what it resolves to.
+					cfg.ClientSecret = clientSecret
+					cfg.OAuthClientID = clientID
+					st.markDirty()
+				}
+				var err error
+				if clientSecret, err = st.resolveSecret(cmd.Context(), clientSecret); err != nil {
+					return err
+				}
 			}
 
 			if passwordStdin {
@@ -90,6 +97,10 @@ func newLoginCmd(st *state) *cobra.Command {
 				}
 				password = strings.TrimSpace(string(b))
 			}
+			var err error
+			if password, err = st.resolveSecret(cmd.Context(), password); err != nil {
+				return err
+			}
 			if password == "" {
 				return errors.New("empty password")
 			}
@@ -111,7 +122,7 @@ func newLoginCmd(st *state) *cobra.Command {
 				tok, mfa, err := oauthPassword(ctx, st, cmd, browser, req)
 				if err != nil {
 					if !explicitClientSecret && !didRetrySecret && isInvalidClientErr(err) {
-						sec, ferr := st.forceFetchClientSecret(ctx, clientID)
+						sec, ferr := st.forceFetchClientSecret(ctx, cfg, clientID)
 						if ferr != nil {
 							return err
 						}
@@ -230,10 +241,7 @@ func oauthPassword(ctx context.Context, st *state, cmd *cobra.Command, browser b
 		}
 
 		if sess.CookieHeader != "" {
-			if cfg.CookiesByHost == nil {
-				cfg.CookiesByHost = map[string]string{}
-			}
-			cfg.CookiesByHost[strings.ToLower(sess.Host)] = sess.CookieHeader
+			cfg.SetCookies(sess.Host, sess.CookieHeader, time.Now())
 			st.markDirty()
 		}
 		if sess.UserAgent != "" {
@@ -253,6 +261,10 @@ func oauthPassword(ctx context.Context, st *state, cmd *cobra.Command, browser b
 		ua = "ordercli/" + version.Version
 	}
 
+	httpOpts, err := st.httpOptions(cfg.Network)
+	if err != nil {
+		return foodora.AuthToken{}, nil, err
+	}
 	c, err := foodora.New(foodora.Options{
 		BaseURL:          cfg.BaseURL,
 		DeviceID:         cfg.DeviceID,
@@ -262,6 +274,7 @@ func oauthPassword(ctx context.Context, st *state, cmd *cobra.Command, browser b
 		CookieHeader:     cookie,
 		FPAPIKey:         prof.FPAPIKey,
 		AppName:          prof.AppName,
+		HTTP:             httpOpts,
 		OriginalUserAgent: func() string {
 			if strings.HasPrefix(ua, "Android-app-") {
 				return ua
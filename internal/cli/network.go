@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/cassette"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/httpx"
+)
+
+const envTrace = "ORDERCLI_TRACE"
+
+// networkFlags are the global flags that shape the transport under every
+// provider client.
+type networkFlags struct {
+	record, replay, trace string
+	// network overrides each provider's config.Network field by field.
+	network config.Network
+	timeout time.Duration
+}
+
+// setupNetwork prepares the --record/--replay/--trace layers; httpOptions
+// stacks them on each client's network transport.
+func (s *state) setupNetwork(f networkFlags) error {
+	if f.timeout < 0 {
+		return errors.New("--http-timeout must be positive")
+	}
+	if f.timeout > 0 {
+		f.network.Timeout = f.timeout.String()
+	}
+	if f.network.Proxy != "" {
+		if _, err := httpx.ParseProxy(f.network.Proxy); err != nil {
+			return err
+		}
+	}
+	s.network = f.network
+
+	switch {
+	case f.record != "" && f.replay != "":
+		return errors.New("--record and --replay are mutually exclusive")
+	case f.replay != "":
+		r, err := cassette.NewReplayer(f.replay)
+		if err != nil {
+			return err
+		}
+		s.replayer = r
+		s.replaying = true
+	case f.record != "":
+		r, err := cassette.NewRecorder(f.record, nil)
+		if err != nil {
+			return err
+		}
+		s.recorder = r
+	}
+
+	w, err := s.traceWriter(f.trace)
+	if err != nil {
+		return err
+	}
+	if w != nil {
+		s.tracer = httpx.NewTracer(w, nil)
+	}
+	return nil
+}
+
+// httpOptions returns the HTTP settings a provider client is built with:
+// its config.Network under the global flags, then recording and tracing.
+func (s *state) httpOptions(n config.Network) (httpx.Options, error) {
+	n = mergeNetwork(s.network, n)
+	var opts httpx.Options
+	if n.Timeout != "" {
+		d, err := time.ParseDuration(n.Timeout)
+		if err != nil || d <= 0 {
+			return httpx.Options{}, fmt.Errorf("invalid network timeout %q (want e.g. 90s)", n.Timeout)
+		}
+		// Slow hosts need a longer wait per attempt too, but one attempt must
+		// leave room for retries: keep the default ratio (20s of 60s).
+		opts.Timeout = d
+		opts.AttemptTimeout = d / (httpx.DefaultTimeout / httpx.DefaultAttemptTimeout)
+	}
+
+	var base http.RoundTripper
+	if s.replayer != nil {
+		base = s.replayer
+	} else {
+		if n.InsecureSkipVerify && !s.warnedInsecure {
+			s.warnedInsecure = true
+			s.warnf("TLS certificate verification is disabled (insecure_skip_verify); tokens can be intercepted")
+		}
+		var err error
+		base, err = httpx.NewNetworkTransport(opts, httpx.Network{Proxy: n.Proxy, CAFile: n.CAFile, InsecureSkipVerify: n.InsecureSkipVerify})
+		if err != nil {
+			return httpx.Options{}, err
+		}
+	}
+	if s.recorder != nil {
+		base = s.recorder.With(base)
+	}
+	if s.tracer != nil {
+		base = s.tracer.With(base)
+	}
+	opts.Base = base
+	return opts, nil
+}
+
+// mergeNetwork is cfg with every field set in flags replaced.
+func mergeNetwork(flags, cfg config.Network) config.Network {
+	if flags.Proxy != "" {
+		cfg.Proxy = flags.Proxy
+	}
+	if flags.CAFile != "" {
+		cfg.CAFile = flags.CAFile
+	}
+	if flags.Timeout != "" {
+		cfg.Timeout = flags.Timeout
+	}
+	cfg.InsecureSkipVerify = cfg.InsecureSkipVerify || flags.InsecureSkipVerify
+	return cfg
+}
+
+// traceWriter resolves --trace (or ORDERCLI_TRACE when the flag is unset):
+// "-", "1" or "stderr" trace to stderr, anything else is a file appended to.
+func (s *state) traceWriter(trace string) (io.Writer, error) {
+	if trace == "" {
+		trace = strings.TrimSpace(os.Getenv(envTrace))
+	}
+	switch strings.ToLower(trace) {
+	case "", "0", "false":
+		return nil, nil
+	case "-", "1", "true", "stderr":
+		if s.stderr == nil {
+			return os.Stderr, nil
+		}
+		return s.stderr, nil
+	}
+	f, err := os.OpenFile(trace, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("trace: %w", err)
+	}
+	return f, nil
+}
+
+// networkConfigFlags are the config.Network flags of `<provider> config set`.
+// They shadow the global flags of the same names there.
+type networkConfigFlags struct {
+	proxy, caFile, timeout string
+	insecure               bool
+}
+
+func addNetworkConfigFlags(cmd *cobra.Command, f *networkConfigFlags) {
+	cmd.Flags().StringVar(&f.proxy, "proxy", "", "proxy URL for this provider: http://, https:// or socks5:// (\"\" = environment)")
+	cmd.Flags().StringVar(&f.caFile, "ca-file", "", "PEM bundle of extra root CAs for this provider (\"\" = system roots)")
+	cmd.Flags().StringVar(&f.timeout, "http-timeout", "", "HTTP timeout per call for this provider, e.g. 90s (\"\" = 60s)")
+	cmd.Flags().BoolVar(&f.insecure, "insecure-skip-verify", false, "disable TLS certificate verification for this provider (unsafe)")
+}
+
+// apply copies the flags given on cmd into n and reports whether there were any.
+func (f networkConfigFlags) apply(st *state, cmd *cobra.Command, n *config.Network) (bool, error) {
+	changed := false
+	if cmd.Flags().Changed("proxy") {
+		if p := strings.TrimSpace(f.proxy); p != "" {
+			if _, err := httpx.ParseProxy(p); err != nil {
+				return false, err
+			}
+		}
+		n.Proxy = strings.TrimSpace(f.proxy)
+		changed = true
+	}
+	if cmd.Flags().Changed("ca-file") {
+		path := strings.TrimSpace(f.caFile)
+		if path != "" {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return false, err
+			}
+			if _, err := os.Stat(abs); err != nil {
+				return false, fmt.Errorf("ca file: %w", err)
+			}
+			path = abs
+		}
+		n.CAFile = path
+		changed = true
+	}
+	if cmd.Flags().Changed("http-timeout") {
+		t := strings.TrimSpace(f.timeout)
+		if t != "" {
+			if d, err := time.ParseDuration(t); err != nil || d <= 0 {
+				return false, fmt.Errorf("invalid --http-timeout %q (want e.g. 90s)", t)
+			}
+		}
+		n.Timeout = t
+		changed = true
+	}
+	if cmd.Flags().Changed("insecure-skip-verify") {
+		n.InsecureSkipVerify = f.insecure
+		if f.insecure {
+			st.warnf("TLS certificate verification is now disabled for this provider; undo with --insecure-skip-verify=false")
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// printNetworkConfig adds the non-default config.Network fields to `config show`.
+func printNetworkConfig(w io.Writer, n config.Network) {
+	if n.Proxy != "" {
+		p := n.Proxy
+		if u, err := httpx.ParseProxy(p); err == nil {
+			p = u.Redacted()
+		}
+		fmt.Fprintf(w, "proxy=%s\n", p)
+	}
+	if n.CAFile != "" {
+		fmt.Fprintf(w, "ca_file=%s\n", n.CAFile)
+	}
+	if n.Timeout != "" {
+		fmt.Fprintf(w, "timeout=%s\n", n.Timeout)
+	}
+	if n.InsecureSkipVerify {
+		fmt.Fprintln(w, "insecure_skip_verify=true")
+	}
+}
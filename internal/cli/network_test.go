@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+)
+
+func TestRecordReplay_FoodoraOrders(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	cassetteDir := filepath.Join(t.TempDir(), "cassette")
+
+	srv := newFoodoraTestServer(t)
+	cfg := config.New()
+	fc := cfg.Foodora()
+	fc.BaseURL = srv.URL + "/"
+	fc.AccessToken = "tokSECRET"
+	fc.RefreshToken = "refSECRET"
+	fc.ExpiresAt = time.Now().Add(time.Hour)
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	live, _, err := runCLI(cfgPath, []string{"--record", cassetteDir, "foodora", "orders"}, "")
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	srv.Close()
+
+	files, _ := os.ReadDir(cassetteDir)
+	if len(files) == 0 {
+		t.Fatalf("nothing recorded")
+	}
+	for _, f := range files {
+		b, _ := os.ReadFile(filepath.Join(cassetteDir, f.Name()))
+		if strings.Contains(string(b), "tokSECRET") {
+			t.Fatalf("%s leaks the token:\n%s", f.Name(), b)
+		}
+	}
+
+	before, _ := os.ReadFile(cfgPath)
+	replayed, _, err := runCLI(cfgPath, []string{"--replay", cassetteDir, "foodora", "orders"}, "")
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed != live || !strings.Contains(replayed, "OC-1") {
+		t.Fatalf("replay differs:\nlive=%s\nreplayed=%s", live, replayed)
+	}
+	after, _ := os.ReadFile(cfgPath)
+	if string(before) != string(after) {
+		t.Fatalf("replay wrote the config")
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"--replay", cassetteDir, "--record", cassetteDir, "foodora", "orders"}, ""); err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestTrace_FoodoraOrders(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	srv := newFoodoraTestServer(t)
+	cfg := config.New()
+	fc := cfg.Foodora()
+	fc.BaseURL = srv.URL + "/"
+	fc.AccessToken = "tokSECRET"
+	fc.RefreshToken = "refSECRET"
+	fc.ExpiresAt = time.Now().Add(time.Hour)
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	_, stderr, err := runCLI(cfgPath, []string{"--trace", "foodora", "orders"}, "")
+	if err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	if !strings.Contains(stderr, "--> GET "+srv.URL) || !strings.Contains(stderr, "<-- 200 OK GET ") || !strings.Contains(stderr, "Authorization: ***") {
+		t.Fatalf("stderr:\n%s", stderr)
+	}
+	if strings.Contains(stderr, "tokSECRET") {
+		t.Fatalf("trace leaks the token:\n%s", stderr)
+	}
+
+	traceFile := filepath.Join(t.TempDir(), "trace.log")
+	t.Setenv("ORDERCLI_TRACE", traceFile)
+	_, stderr, err = runCLI(cfgPath, []string{"foodora", "orders"}, "")
+	if err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	b, _ := os.ReadFile(traceFile)
+	if strings.Contains(stderr, "-->") || !strings.Contains(string(b), "<-- 200 OK GET ") {
+		t.Fatalf("stderr=%s\nfile=%s", stderr, b)
+	}
+}
+
+func TestNetworkSettings_ConfigAndFlags(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	srv := newFoodoraTestServer(t)
+
+	// A plain-HTTP forward proxy in front of the fake fd-api.
+	var viaProxy int
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		viaProxy++
+		res, err := http.DefaultTransport.RoundTrip(r.Clone(r.Context()))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer res.Body.Close()
+		for k, v := range res.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(res.StatusCode)
+		_, _ = io.Copy(w, res.Body)
+	}))
+	t.Cleanup(proxy.Close)
+
+	cfg := config.New()
+	fc := cfg.Foodora()
+	fc.BaseURL = srv.URL + "/"
+	fc.AccessToken = "tok"
+	fc.RefreshToken = "ref"
+	fc.ExpiresAt = time.Now().Add(time.Hour)
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "config", "set", "--proxy", proxy.URL, "--http-timeout", "90s"}, ""); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	out, _, err := runCLI(cfgPath, []string{"foodora", "config", "show"}, "")
+	if err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	if !strings.Contains(out, "proxy="+proxy.URL+"\n") || !strings.Contains(out, "timeout=90s\n") {
+		t.Fatalf("show:\n%s", out)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "orders"}, ""); err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	if viaProxy == 0 {
+		t.Fatalf("config proxy not used")
+	}
+
+	// The global flag wins over the provider config.
+	viaProxy = 0
+	_, _, err = runCLI(cfgPath, []string{"--proxy", "http://127.0.0.1:1", "foodora", "orders"}, "")
+	if err == nil || viaProxy != 0 {
+		t.Fatalf("err=%v viaProxy=%d", err, viaProxy)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"foodora", "config", "set", "--http-timeout", "soon"}, ""); err == nil || !strings.Contains(err.Error(), "invalid --http-timeout") {
+		t.Fatalf("err=%v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "config", "set", "--proxy", "ftp://x"}, ""); err == nil || !strings.Contains(err.Error(), "unsupported scheme") {
+		t.Fatalf("err=%v", err)
+	}
+
+	_, stderr, err := runCLI(cfgPath, []string{"--insecure-skip-verify", "--proxy", proxy.URL, "foodora", "orders"}, "")
+	if err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	if strings.Count(stderr, "TLS certificate verification is disabled") != 1 {
+		t.Fatalf("stderr:\n%s", stderr)
+	}
+}
+
+func TestHTTPOptions_TimeoutLeavesRoomForRetries(t *testing.T) {
+	st := &state{}
+	if err := st.setupNetwork(networkFlags{timeout: 90 * time.Second}); err != nil {
+		t.Fatalf("setupNetwork: %v", err)
+	}
+	opts, err := st.httpOptions(config.Network{Timeout: "10s"})
+	if err != nil {
+		t.Fatalf("httpOptions: %v", err)
+	}
+	if opts.Timeout != 90*time.Second || opts.AttemptTimeout != 30*time.Second {
+		t.Fatalf("timeout=%s attempt=%s", opts.Timeout, opts.AttemptTimeout)
+	}
+
+	// Local --timeout flags don't collide with the global --http-timeout.
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	if _, _, err := runCLI(cfgPath, []string{"--http-timeout", "5s", "auth", "status", "--timeout", "1s"}, ""); err != nil {
+		t.Fatalf("auth status: %v", err)
+	}
+}
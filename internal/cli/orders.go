@@ -8,30 +8,62 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/auth"
 	"github.com/steipete/ordercli/internal/config"
 	"github.com/steipete/ordercli/internal/foodora"
+	"github.com/steipete/ordercli/internal/provider"
 	"github.com/steipete/ordercli/internal/version"
 )
 
 func newOrdersCmd(st *state) *cobra.Command {
 	var watch bool
+	var asJSON bool
 
 	cmd := &cobra.Command{
 		Use:   "orders",
 		Short: "List active orders",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := newAuthedClient(st)
+			c, err := newAuthedClient(cmd.Context(), st)
 			if err != nil {
 				return err
 			}
 
 			ctx := cmd.Context()
+			token := st.foodora().AccessToken
 			for {
+				if watch {
+					// Persist refreshed tokens right away instead of on exit, and
+					// pick up sessions other processes saved meanwhile.
+					if err := st.sync(); err != nil {
+						return err
+					}
+					// The client renews its own token; reopen only for a session
+					// another process saved.
+					if st.foodora().AccessToken != token {
+						if c, err = newAuthedClient(cmd.Context(), st); err != nil {
+							return err
+						}
+					}
+				}
 				resp, err := c.ActiveOrders(ctx)
 				if err != nil {
 					return err
 				}
-				printActiveOrders(cmd, resp.Data.ActiveOrders)
+				if watch && st.isDirty() {
+					// Save a token the client refreshed during this round.
+					if err := st.sync(); err != nil {
+						return err
+					}
+				}
+				token = st.foodora().AccessToken
+				if asJSON {
+					err = st.writeOrdersJSON(cmd.OutOrStdout(), foodoraActiveOrders(st, resp.Data.ActiveOrders), resp.Data.ActiveOrders)
+				} else {
+					err = printActiveOrders(cmd, st, resp.Data.ActiveOrders)
+				}
+				if err != nil {
+					return err
+				}
 
 				if !watch {
 					return nil
@@ -45,6 +77,7 @@ func newOrdersCmd(st *state) *cobra.Command {
 		},
 	}
 	cmd.Flags().BoolVar(&watch, "watch", false, "poll active orders")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON (see --schema)")
 	return cmd
 }
 
@@ -54,7 +87,7 @@ func newOrderCmd(st *state) *cobra.Command {
 		Short: "Show details for a single order (tracking/orders/{orderCode})",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := newAuthedClient(st)
+			c, err := newAuthedClient(cmd.Context(), st)
 			if err != nil {
 				return err
 			}
@@ -62,16 +95,20 @@ func newOrderCmd(st *state) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "status=%d\n", resp.Status)
-			if v, ok := resp.Data["status_messages"]; ok {
-				fmt.Fprintf(cmd.OutOrStdout(), "status_messages=%v\n", v)
-			}
-			return nil
+			r := newRecords("order_id", "status", "status_messages")
+			r.add(args[0], resp.Status, resp.Data["status_messages"])
+			return st.renderDetail(cmd.OutOrStdout(), r, func() error {
+				fmt.Fprintf(cmd.OutOrStdout(), "status=%d\n", resp.Status)
+				if v, ok := resp.Data["status_messages"]; ok {
+					fmt.Fprintf(cmd.OutOrStdout(), "status_messages=%v\n", v)
+				}
+				return nil
+			})
 		},
 	}
 }
 
-func newAuthedClient(st *state) (*foodora.Client, error) {
+func newAuthedClient(ctx context.Context, st *state) (*foodora.Client, error) {
 	cfg := st.foodora()
 	if cfg.BaseURL == "" {
 		return nil, errors.New("missing base_url (run `ordercli foodora config set --country ...`)")
@@ -90,16 +127,45 @@ func newAuthedClient(st *state) (*foodora.Client, error) {
 		ua = "ordercli/" + version.Version
 	}
 
-	c, err := foodora.New(foodora.Options{
+	// The token is renewed on first use when expired and again after any 401.
+	account := st.accountFor("foodora")
+	var c *foodora.Client
+	tok, err := st.resolveToken(ctx, cfg.AccessToken, cfg.RefreshToken, cfg.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	// Refreshes of several accounts may run at once (timeline), so each gets
+	// its account's config explicitly, looked up again since a sync may have
+	// replaced it.
+	tokens := auth.NewRefresher(tok, func(ctx context.Context, cur auth.Token) (auth.Token, error) {
+		return refreshFoodoraToken(ctx, st, c, st.foodoraAccount(account), cur.RefreshToken)
+	}, func(t auth.Token) {
+		stored := true
+		st.update(func() {
+			cfg := st.cfg.FoodoraAccount(account)
+			stored = storeRefreshedToken(&cfg.AccessToken, &cfg.RefreshToken, &cfg.ExpiresAt, t)
+		})
+		if !stored {
+			st.warnRefreshedRef("foodora", account)
+		}
+	})
+
+	httpOpts, err := st.httpOptions(cfg.Network)
+	if err != nil {
+		return nil, err
+	}
+	c, err = foodora.New(foodora.Options{
 		BaseURL:          cfg.BaseURL,
 		DeviceID:         cfg.DeviceID,
 		GlobalEntityID:   cfg.GlobalEntityID,
 		TargetCountryISO: cfg.TargetCountryISO,
-		AccessToken:      cfg.AccessToken,
+		TokenSource:      tokens,
 		UserAgent:        ua,
 		CookieHeader:     cookie,
 		FPAPIKey:         prof.FPAPIKey,
 		AppName:          prof.AppName,
+		HTTP:             httpOpts,
+		Drift:            st.driftRecorder(),
 		OriginalUserAgent: func() string {
 			if strings.HasPrefix(ua, "Android-app-") {
 				return ua
@@ -110,56 +176,62 @@ func newAuthedClient(st *state) (*foodora.Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	return c, nil
+}
 
+// refreshFoodoraToken runs the refresh_token grant for the account cfg,
+// re-fetching the client secret once if the cached one is rejected.
+func refreshFoodoraToken(ctx context.Context, st *state, c *foodora.Client, cfg *config.FoodoraConfig, refreshToken string) (auth.Token, error) {
 	now := time.Now()
-	if cfg.TokenLikelyExpired(now) {
-		sec, err := st.resolveClientSecret(context.Background(), cfg.OAuthClientID)
-		if err != nil {
-			return nil, err
-		}
-		tok, err := c.OAuthTokenRefresh(context.Background(), foodora.OAuthRefreshRequest{
-			RefreshToken: cfg.RefreshToken,
-			ClientSecret: sec.Secret,
-			ClientID:     cfg.OAuthClientID,
-		})
-		if err != nil && isInvalidClientErr(err) {
-			if sec2, ferr := st.forceFetchClientSecret(context.Background(), cfg.OAuthClientID); ferr == nil {
-				tok, err = c.OAuthTokenRefresh(context.Background(), foodora.OAuthRefreshRequest{
-					RefreshToken: cfg.RefreshToken,
-					ClientSecret: sec2.Secret,
-					ClientID:     cfg.OAuthClientID,
-				})
-			}
-		}
-		if err != nil {
-			return nil, err
-		}
-		cfg.AccessToken = tok.AccessToken
-		cfg.RefreshToken = tok.RefreshToken
-		cfg.ExpiresAt = tok.ExpiresAt(now)
-		if cfg.ExpiresAt.IsZero() {
-			if exp, ok := config.AccessTokenExpiresAt(tok.AccessToken); ok {
-				cfg.ExpiresAt = exp
-			}
+	clientID := oauthClientID(cfg, "")
+	sec, err := st.resolveClientSecret(ctx, cfg, clientID)
+	if err != nil {
+		return auth.Token{}, err
+	}
+	tok, err := c.OAuthTokenRefresh(ctx, foodora.OAuthRefreshRequest{
+		RefreshToken: refreshToken,
+		ClientSecret: sec.Secret,
+		ClientID:     clientID,
+	})
+	if err != nil && isInvalidClientErr(err) {
+		if sec2, ferr := st.forceFetchClientSecret(ctx, cfg, clientID); ferr == nil {
+			tok, err = c.OAuthTokenRefresh(ctx, foodora.OAuthRefreshRequest{
+				RefreshToken: refreshToken,
+				ClientSecret: sec2.Secret,
+				ClientID:     clientID,
+			})
 		}
-		st.markDirty()
-		c.SetAccessToken(tok.AccessToken)
 	}
-
-	return c, nil
+	if err != nil {
+		return auth.Token{}, err
+	}
+	out := auth.Token{AccessToken: tok.AccessToken, RefreshToken: tok.RefreshToken, ExpiresAt: tok.ExpiresAt(now)}
+	if out.ExpiresAt.IsZero() {
+		out.ExpiresAt, _ = config.AccessTokenExpiresAt(tok.AccessToken)
+	}
+	return out, nil
 }
 
-func printActiveOrders(cmd *cobra.Command, orders []foodora.ActiveOrder) {
+func printActiveOrders(cmd *cobra.Command, st *state, orders []foodora.ActiveOrder) error {
 	out := cmd.OutOrStdout()
-	if len(orders) == 0 {
-		fmt.Fprintln(out, "no active orders")
-		return
-	}
-	for _, o := range orders {
-		status := o.Status.Subtitle
-		if status == "" && len(o.Status.Titles) > 0 {
-			status = o.Status.Titles[0].Name
+	normalized := foodoraActiveOrders(st, orders)
+	return st.renderList(out, orderRecords(normalized), func() error {
+		if len(orders) == 0 {
+			fmt.Fprintln(out, "no active orders")
+			return nil
 		}
-		fmt.Fprintf(out, "%s\t%s\t%s\n", o.Code, o.Vendor.Name, status)
+		for _, o := range normalized {
+			fmt.Fprintf(out, "%s\t%s\t%s\n", o.ID, o.Vendor, o.Status)
+		}
+		return nil
+	})
+}
+
+func foodoraActiveOrders(st *state, orders []foodora.ActiveOrder) []provider.Order {
+	currency := currencyForTargetISO(st.foodora().TargetCountryISO)
+	out := make([]provider.Order, 0, len(orders))
+	for _, o := range orders {
+		out = append(out, provider.FoodoraActiveOrder(o, currency))
 	}
+	return out
 }
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// Generic commands built from the Provider interface. Providers only get these
+// when they don't ship a hand-written command of the same name.
+
+func newProviderOrdersCmd(st *state, p providerEntry) *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "orders",
+		Short: "List active orders",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pr, err := p.open(cmd.Context(), st)
+			if err != nil {
+				return err
+			}
+			orders, err := pr.ActiveOrders(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return st.writeOrdersJSON(cmd.OutOrStdout(), orders, nil)
+			}
+			return st.renderList(cmd.OutOrStdout(), orderRecords(orders), func() error {
+				if len(orders) == 0 {
+					fmt.Fprintln(cmd.OutOrStdout(), "no active orders")
+					return nil
+				}
+				printOrderLines(cmd.OutOrStdout(), orders)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON (see --schema)")
+	return cmd
+}
+
+func newProviderHistoryCmd(st *state, p providerEntry) *cobra.Command {
+	var limit int
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List past orders",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pr, err := p.open(cmd.Context(), st)
+			if err != nil {
+				return err
+			}
+			orders, err := provider.FetchHistory(cmd.Context(), pr, limit, 20)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return st.writeOrdersJSON(cmd.OutOrStdout(), orders, nil)
+			}
+			return st.renderList(cmd.OutOrStdout(), orderRecords(orders), func() error {
+				if len(orders) == 0 {
+					fmt.Fprintln(cmd.OutOrStdout(), "no past orders")
+					return nil
+				}
+				printOrderLines(cmd.OutOrStdout(), orders)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 20, "max orders to print")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON (see --schema)")
+	return cmd
+}
+
+func newProviderOrderCmd(st *state, p providerEntry) *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "order <id>",
+		Short: "Show details for a single order",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pr, err := p.open(cmd.Context(), st)
+			if err != nil {
+				return err
+			}
+			o, err := pr.OrderDetail(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return st.writeOrderJSON(cmd.OutOrStdout(), o, nil)
+			}
+			return st.renderDetail(cmd.OutOrStdout(), orderDetailRecords(o), func() error {
+				printOrderDetail(cmd.OutOrStdout(), o)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON (see --schema)")
+	return cmd
+}
+
+func newProviderMeCmd(st *state, p providerEntry) *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "me",
+		Short: "Show current user profile",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pr, err := p.open(cmd.Context(), st)
+			if err != nil {
+				return err
+			}
+			me, err := pr.Profile(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return writeJSON(cmd.OutOrStdout(), me)
+			}
+			r := newRecords("id", "name", "email")
+			r.add(me.ID, me.Name, me.Email)
+			out := cmd.OutOrStdout()
+			return st.renderDetail(out, r, func() error {
+				fmt.Fprintf(out, "id=%s\n", me.ID)
+				fmt.Fprintf(out, "name=%s\n", me.Name)
+				fmt.Fprintf(out, "email=%s\n", me.Email)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print normalized JSON")
+	return cmd
+}
+
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printOrderLines prints one tab-separated line per order:
+// time, provider, id, vendor, status, total.
+func printOrderLines(out io.Writer, orders []provider.Order) {
+	for _, o := range orders {
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\n", orderWhen(o), o.Source(), o.ID, o.Vendor, o.Status, orderTotal(o))
+	}
+}
+
+func printOrderDetail(out io.Writer, o provider.Order) {
+	fmt.Fprintf(out, "order=%s\n", o.ID)
+	if o.Vendor != "" {
+		fmt.Fprintf(out, "vendor=%s\n", o.Vendor)
+	}
+	if when := orderWhen(o); when != "" {
+		fmt.Fprintf(out, "time=%s\n", when)
+	}
+	if o.Status != "" {
+		fmt.Fprintf(out, "status=%s\n", o.Status)
+	}
+	if total := orderTotal(o); total != "" {
+		fmt.Fprintf(out, "total=%s\n", total)
+	}
+	if len(o.Items) > 0 {
+		fmt.Fprintln(out, "items:")
+		for _, it := range o.Items {
+			if it.Quantity > 0 {
+				fmt.Fprintf(out, "- %dx %s\n", it.Quantity, it.Name)
+			} else {
+				fmt.Fprintf(out, "- %s\n", it.Name)
+			}
+		}
+	}
+}
+
+func orderWhen(o provider.Order) string {
+	t := o.When()
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(time.Local).Format(time.RFC3339)
+}
+
+func orderTotal(o provider.Order) string {
+	if o.Total == 0 {
+		return ""
+	}
+	return strings.TrimSpace(strconv.FormatFloat(o.Total, 'f', 2, 64) + " " + o.Currency)
+}
@@ -1,32 +1,154 @@
 package cli
 
-import "github.com/spf13/cobra"
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
 
-func newFoodoraCmd(st *state) *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "foodora",
-		Short: "foodora (via fd-api)",
-	}
-	cmd.AddCommand(newCountriesCmd(st))
-	cmd.AddCommand(newConfigCmd(st))
-	cmd.AddCommand(newCookiesCmd(st))
-	cmd.AddCommand(newSessionCmd(st))
-	cmd.AddCommand(newLoginCmd(st))
-	cmd.AddCommand(newLogoutCmd(st))
-	cmd.AddCommand(newOrdersCmd(st))
-	cmd.AddCommand(newHistoryCmd(st))
-	cmd.AddCommand(newOrderCmd(st))
-	cmd.AddCommand(newReorderCmd(st))
-	return cmd
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// providerEntry wires one provider into the cobra tree and the cross-provider commands.
+type providerEntry struct {
+	name  string
+	short string
+	caps  provider.Capabilities
+	// configured reports whether credentials exist (without network calls).
+	configured func(st *state) bool
+	// authStatus describes the stored session for `auth status` (no network).
+	authStatus func(ctx context.Context, st *state, now time.Time) authStatus
+	open       func(ctx context.Context, st *state) (provider.Provider, error)
+	// commands are provider-specific; generic commands fill the remaining capabilities.
+	commands func(st *state) []*cobra.Command
+}
+
+var providerRegistry = []providerEntry{
+	{
+		name:       "foodora",
+		short:      "foodora (via fd-api)",
+		caps:       provider.FoodoraCapabilities,
+		configured: func(st *state) bool { return st.foodora().HasSession() },
+		authStatus: foodoraAuthStatus,
+		open: func(ctx context.Context, st *state) (provider.Provider, error) {
+			c, err := newAuthedClient(ctx, st)
+			if err != nil {
+				return nil, err
+			}
+			return provider.NewFoodora(c, currencyForTargetISO(st.foodora().TargetCountryISO)), nil
+		},
+		commands: foodoraCommands,
+	},
+	{
+		name:  "deliveroo",
+		short: "Deliveroo",
+		caps:  provider.DeliverooCapabilities,
+		configured: func(st *state) bool {
+			if st.deliveroo().HasSession() {
+				return true
+			}
+			// The env token belongs to the default account only.
+			return st.accountFor("deliveroo") == config.DefaultAccount &&
+				strings.TrimSpace(os.Getenv("DELIVEROO_BEARER_TOKEN")) != ""
+		},
+		authStatus: deliverooAuthStatus,
+		open: func(ctx context.Context, st *state) (provider.Provider, error) {
+			c, err := newDeliverooClient(ctx, st, "", "", "", "")
+			if err != nil {
+				return nil, err
+			}
+			return provider.NewDeliveroo(c), nil
+		},
+		commands: deliverooCommands,
+	},
+	{
+		name:       "glovo",
+		short:      "Glovo",
+		caps:       provider.GlovoCapabilities,
+		configured: func(st *state) bool { return st.glovo().HasSession() },
+		authStatus: glovoAuthStatus,
+		open: func(ctx context.Context, st *state) (provider.Provider, error) {
+			c, err := newGlovoClient(ctx, st)
+			if err != nil {
+				return nil, err
+			}
+			return provider.NewGlovo(c), nil
+		},
+		commands: glovoCommands,
+	},
+}
+
+func findProvider(name string) (providerEntry, bool) {
+	for _, p := range providerRegistry {
+		if strings.EqualFold(p.name, strings.TrimSpace(name)) {
+			return p, true
+		}
+	}
+	return providerEntry{}, false
+}
+
+func providerNames() []string {
+	names := make([]string, 0, len(providerRegistry))
+	for _, p := range providerRegistry {
+		names = append(names, p.name)
+	}
+	return names
 }
 
-func newDeliverooCmd(st *state) *cobra.Command {
+func newProviderCmd(st *state, p providerEntry) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "deliveroo",
-		Short: "Deliveroo",
+		Use:   p.name,
+		Short: p.short,
+	}
+	if p.commands != nil {
+		cmd.AddCommand(p.commands(st)...)
+	}
+
+	have := map[string]bool{}
+	for _, c := range cmd.Commands() {
+		have[c.Name()] = true
+	}
+	generic := []struct {
+		name  string
+		ok    bool
+		build func(*state, providerEntry) *cobra.Command
+	}{
+		{"orders", p.caps.ActiveOrders, newProviderOrdersCmd},
+		{"history", p.caps.History, newProviderHistoryCmd},
+		{"order", p.caps.OrderDetail, newProviderOrderCmd},
+		{"me", p.caps.Profile, newProviderMeCmd},
+	}
+	for _, g := range generic {
+		if g.ok && !have[g.name] {
+			cmd.AddCommand(g.build(st, p))
+		}
 	}
-	cmd.AddCommand(newDeliverooConfigCmd(st))
-	cmd.AddCommand(newDeliverooHistoryCmd(st))
-	cmd.AddCommand(newDeliverooOrdersCmd(st))
 	return cmd
 }
+
+func foodoraCommands(st *state) []*cobra.Command {
+	return []*cobra.Command{
+		newCountriesCmd(st),
+		newConfigCmd(st),
+		newAPKImportCmd(st),
+		newCookiesCmd(st),
+		newSessionCmd(st),
+		newLoginCmd(st),
+		newLogoutCmd(st),
+		newOrdersCmd(st),
+		newHistoryCmd(st),
+		newOrderCmd(st),
+		newReorderCmd(st),
+	}
+}
+
+func deliverooCommands(st *state) []*cobra.Command {
+	return []*cobra.Command{
+		newDeliverooConfigCmd(st),
+		newDeliverooSessionCmd(st),
+		newDeliverooHistoryCmd(st),
+		newDeliverooOrdersCmd(st),
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+type fakeProvider struct{}
+
+func (fakeProvider) Name() string { return "fake" }
+
+func (fakeProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{History: true, Profile: true}
+}
+
+func (fakeProvider) ActiveOrders(ctx context.Context) ([]provider.Order, error) { return nil, nil }
+
+func (fakeProvider) History(ctx context.Context, req provider.HistoryRequest) (provider.HistoryPage, error) {
+	return provider.HistoryPage{Orders: []provider.Order{{Provider: "fake", ID: "F-1", Vendor: "Fake Vendor"}}}, nil
+}
+
+func (fakeProvider) OrderDetail(ctx context.Context, id string) (provider.Order, error) {
+	return provider.Order{}, provider.ErrUnsupported
+}
+
+func (fakeProvider) Profile(ctx context.Context) (provider.Profile, error) {
+	return provider.Profile{Name: "Fake User"}, nil
+}
+
+func TestNewProviderCmd_GeneratesCapabilityCommands(t *testing.T) {
+	st := &state{cfg: config.New()}
+	entry := providerEntry{
+		name:       "fake",
+		caps:       fakeProvider{}.Capabilities(),
+		configured: func(*state) bool { return true },
+		open:       func(context.Context, *state) (provider.Provider, error) { return fakeProvider{}, nil },
+		commands: func(*state) []*cobra.Command {
+			return []*cobra.Command{{Use: "me", Run: func(*cobra.Command, []string) {}}}
+		},
+	}
+
+	cmd := newProviderCmd(st, entry)
+	var names []string
+	for _, c := range cmd.Commands() {
+		names = append(names, c.Name())
+	}
+	if got := strings.Join(names, ","); got != "history,me" {
+		t.Fatalf("unexpected commands: %s", got)
+	}
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"history"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if !strings.Contains(out.String(), "fake\tF-1\tFake Vendor") {
+		t.Fatalf("unexpected out: %q", out.String())
+	}
+}
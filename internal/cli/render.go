@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// Output formats for the global --output flag. The empty format keeps each
+// command's human-readable text.
+const (
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+	outputCSV    = "csv"
+	outputTSV    = "tsv"
+	outputTable  = "table"
+)
+
+var outputFormats = []string{outputJSON, outputNDJSON, outputCSV, outputTSV, outputTable}
+
+func parseOutputFormat(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "text" {
+		return "", nil
+	}
+	for _, f := range outputFormats {
+		if s == f {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown --output %q (use %s)", s, strings.Join(outputFormats, ", "))
+}
+
+// records is the structured form of a command result. Column names are part
+// of the CLI contract: snake_case, shared across formats, only ever appended to.
+type records struct {
+	columns []string
+	rows    [][]any
+}
+
+func newRecords(columns ...string) *records { return &records{columns: columns} }
+
+// add appends a row; values are given in column order.
+func (r *records) add(values ...any) {
+	if len(values) != len(r.columns) {
+		panic(fmt.Sprintf("records: %d values for %d columns", len(values), len(r.columns)))
+	}
+	r.rows = append(r.rows, values)
+}
+
+// renderList writes r in the selected format, or calls text when no format is set.
+func (s *state) renderList(out io.Writer, r *records, text func() error) error {
+	return renderRecords(out, s.output, r, false, text)
+}
+
+// renderDetail is renderList for single-object results: JSON gets an object
+// instead of an array and table prints field/value pairs.
+func (s *state) renderDetail(out io.Writer, r *records, text func() error) error {
+	return renderRecords(out, s.output, r, true, text)
+}
+
+func renderRecords(out io.Writer, format string, r *records, detail bool, text func() error) error {
+	switch format {
+	case "":
+		return text()
+	case outputJSON:
+		if detail {
+			if len(r.rows) == 0 {
+				_, err := io.WriteString(out, "null\n")
+				return err
+			}
+			return writeJSON(out, r.object(0))
+		}
+		objs := make([]orderedObject, 0, len(r.rows))
+		for i := range r.rows {
+			objs = append(objs, r.object(i))
+		}
+		return writeJSON(out, objs)
+	case outputNDJSON:
+		enc := json.NewEncoder(out)
+		for i := range r.rows {
+			if err := enc.Encode(r.object(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case outputCSV:
+		w := csv.NewWriter(out)
+		_ = w.Write(r.columns)
+		for _, row := range r.rows {
+			_ = w.Write(cells(row))
+		}
+		w.Flush()
+		return w.Error()
+	case outputTSV:
+		var b strings.Builder
+		b.WriteString(strings.Join(r.columns, "\t") + "\n")
+		for _, row := range r.rows {
+			c := cells(row)
+			for i := range c {
+				c[i] = cellEscaper.Replace(c[i])
+			}
+			b.WriteString(strings.Join(c, "\t") + "\n")
+		}
+		_, err := io.WriteString(out, b.String())
+		return err
+	case outputTable:
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		if detail {
+			for _, row := range r.rows {
+				for i, c := range cells(row) {
+					fmt.Fprintf(tw, "%s\t%s\n", strings.ToUpper(r.columns[i]), cellEscaper.Replace(c))
+				}
+			}
+			return tw.Flush()
+		}
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(r.columns, "\t")))
+		for _, row := range r.rows {
+			c := cells(row)
+			for i := range c {
+				c[i] = cellEscaper.Replace(c[i])
+			}
+			fmt.Fprintln(tw, strings.Join(c, "\t"))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// cellEscaper keeps TSV and table cells on one line.
+var cellEscaper = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// orderedObject marshals a row as a JSON object with keys in column order.
+type orderedObject struct {
+	keys   []string
+	values []any
+}
+
+func (r *records) object(i int) orderedObject {
+	return orderedObject{keys: r.columns, values: r.rows[i]}
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(jsonValue(o.values[i]))
+		if err != nil {
+			return nil, err
+		}
+		b.Write(kb)
+		b.WriteByte(':')
+		b.Write(vb)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func jsonValue(v any) any {
+	switch t := v.(type) {
+	case time.Time:
+		if t.IsZero() {
+			return nil
+		}
+		return t.Format(time.RFC3339)
+	case []string:
+		if t == nil {
+			return []string{}
+		}
+	}
+	return v
+}
+
+func cells(row []any) []string {
+	out := make([]string, len(row))
+	for i, v := range row {
+		out[i] = cell(v)
+	}
+	return out
+}
+
+func cell(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case int:
+		return strconv.Itoa(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case time.Time:
+		if t.IsZero() {
+			return ""
+		}
+		return t.In(time.Local).Format(time.RFC3339)
+	case []string:
+		return strings.Join(t, "; ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// orderColumns are shared by every command that lists orders.
+var orderColumns = []string{"time", "provider", "order_id", "vendor", "status", "total", "currency"}
+
+func orderRecords(orders []provider.Order) *records {
+	r := newRecords(orderColumns...)
+	for _, o := range orders {
+		r.add(o.When(), o.Source(), o.ID, o.Vendor, o.Status, o.Total, o.Currency)
+	}
+	return r
+}
+
+// orderDetailRecords is orderRecords plus the item lines.
+func orderDetailRecords(o provider.Order) *records {
+	r := newRecords(append(append([]string{}, orderColumns...), "items")...)
+	items := make([]string, 0, len(o.Items))
+	for _, it := range o.Items {
+		if it.Quantity > 0 {
+			items = append(items, fmt.Sprintf("%dx %s", it.Quantity, it.Name))
+		} else {
+			items = append(items, it.Name)
+		}
+	}
+	r.add(o.When(), o.Source(), o.ID, o.Vendor, o.Status, o.Total, o.Currency, items)
+	return r
+}
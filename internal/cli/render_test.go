@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRenderRecords_Formats(t *testing.T) {
+	r := newRecords("time", "name", "total", "items")
+	r.add(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), "Pad\tThai", 9.5, []string{"a", "b"})
+	r.add(time.Time{}, "Soup", 0.0, []string(nil))
+
+	cases := []struct {
+		format string
+		detail bool
+		want   string
+	}{
+		{outputNDJSON, false, `{"time":"2025-01-02T03:04:05Z","name":"Pad\tThai","total":9.5,"items":["a","b"]}` + "\n" +
+			`{"time":null,"name":"Soup","total":0,"items":[]}` + "\n"},
+		{outputTSV, false, "time\tname\ttotal\titems\n" +
+			time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC).Local().Format(time.RFC3339) + "\tPad Thai\t9.5\ta; b\n" +
+			"\tSoup\t0\t\n"},
+		{outputJSON, true, "{\n  \"time\": \"2025-01-02T03:04:05Z\",\n  \"name\": \"Pad\\tThai\",\n  \"total\": 9.5,\n  \"items\": [\n    \"a\",\n    \"b\"\n  ]\n}\n"},
+	}
+	for _, tc := range cases {
+		var out bytes.Buffer
+		if err := renderRecords(&out, tc.format, r, tc.detail, nil); err != nil {
+			t.Fatalf("%s: %v", tc.format, err)
+		}
+		if out.String() != tc.want {
+			t.Fatalf("%s: got\n%q\nwant\n%q", tc.format, out.String(), tc.want)
+		}
+	}
+
+	called := false
+	if err := renderRecords(&bytes.Buffer{}, "", r, false, func() error { called = true; return nil }); err != nil || !called {
+		t.Fatalf("text fallback not used (err=%v)", err)
+	}
+	if _, err := parseOutputFormat("yaml"); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
@@ -22,7 +22,7 @@ func newReorderCmd(st *state) *cobra.Command {
 		Short: "Reorder a past order (adds to cart when --confirm)",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, err := newAuthedClient(st)
+			c, err := newAuthedClient(cmd.Context(), st)
 			if err != nil {
 				return err
 			}
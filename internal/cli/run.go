@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -21,25 +22,71 @@ func Run(ctx context.Context, args []string) error {
 
 func newRoot() *cobra.Command {
 	var cfgPath string
+	var output string
+	var schema string
+	var account string
+	var netFlags networkFlags
 
 	cmd := &cobra.Command{
 		Use:   "ordercli",
 		Short: "multi-provider order CLI",
 	}
 	cmd.PersistentFlags().StringVar(&cfgPath, "config", "", "config path (default: OS config dir)")
+	cmd.PersistentFlags().StringVarP(&output, "output", "o", "", "output format: "+strings.Join(outputFormats, "|")+" (default: text)")
+	cmd.PersistentFlags().StringVar(&account, "account", "", "named account to use (default: the current one, see \"ordercli account use\")")
+	cmd.PersistentFlags().StringVar(&schema, "schema", schemaDefault, "order JSON for --json: normalized (versioned, see docs/order-schema.md) or raw (provider payloads) (default: raw for provider commands that always printed it, normalized for the rest)")
+	cmd.PersistentFlags().StringVar(&netFlags.record, "record", "", "record every HTTP exchange (secrets redacted) into this directory")
+	cmd.PersistentFlags().StringVar(&netFlags.replay, "replay", "", "answer HTTP requests from recordings in this directory instead of the network (config is not saved)")
+	cmd.PersistentFlags().StringVar(&netFlags.trace, "trace", "", "log every HTTP exchange (secrets redacted) to stderr, or to this file with --trace=<file> (env: ORDERCLI_TRACE)")
+	cmd.PersistentFlags().Lookup("trace").NoOptDefVal = "-"
+	cmd.PersistentFlags().StringVar(&netFlags.network.Proxy, "proxy", "", "HTTP(S) or SOCKS5 proxy URL for every provider (e.g. socks5://127.0.0.1:1080; default: provider config, then HTTPS_PROXY)")
+	cmd.PersistentFlags().StringVar(&netFlags.network.CAFile, "ca-file", "", "PEM bundle of extra root CAs to trust (e.g. a corporate TLS proxy)")
+	cmd.PersistentFlags().DurationVar(&netFlags.timeout, "http-timeout", 0, "HTTP timeout per call, retries included (default: provider config, then 60s)")
+	cmd.PersistentFlags().BoolVar(&netFlags.network.InsecureSkipVerify, "insecure-skip-verify", false, "disable TLS certificate verification (unsafe; prints a warning)")
 
 	st := &state{}
 	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		st.configPath = cfgPath
-		return st.load()
+		st.stderr = cmd.ErrOrStderr()
+		format, err := parseOutputFormat(output)
+		if err != nil {
+			return err
+		}
+		st.output = format
+		if st.schema, err = parseSchema(schema); err != nil {
+			return err
+		}
+		if err := st.setupNetwork(netFlags); err != nil {
+			return err
+		}
+		if err := st.load(); err != nil {
+			return err
+		}
+		if account = strings.TrimSpace(account); account != "" {
+			if !st.cfg.KnowsAccount(account) {
+				return fmt.Errorf("unknown account %q (see `ordercli account list`)", account)
+			}
+			st.account = account
+		}
+		return nil
 	}
 	cmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
 		return st.save()
 	}
 
-	cmd.AddCommand(newFoodoraCmd(st))
-	cmd.AddCommand(newDeliverooCmd(st))
-	cmd.AddCommand(newGlovoCmd(st))
+	cmd.AddCommand(newTimelineCmd(st))
+	cmd.AddCommand(newSyncCmd(st))
+	cmd.AddCommand(newSearchCmd(st))
+	cmd.AddCommand(newStatsCmd(st))
+	cmd.AddCommand(newExportCmd(st))
+	cmd.AddCommand(newServeCmd(st))
+	cmd.AddCommand(newRootConfigCmd(st))
+	cmd.AddCommand(newAccountCmd(st))
+	cmd.AddCommand(newAuthCmd(st))
+	cmd.AddCommand(newDoctorCmd(st))
+	for _, p := range providerRegistry {
+		cmd.AddCommand(newProviderCmd(st, p))
+	}
 
 	return cmd
 }
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// Values of the global --schema flag. Without the flag, commands that have an
+// upstream payload (the hand-written provider commands, whose --json has
+// always printed it) stay raw; the unified and generic commands print the
+// normalized document.
+const (
+	schemaDefault    = ""
+	schemaNormalized = "normalized"
+	schemaRaw        = "raw"
+)
+
+func parseSchema(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case schemaDefault:
+		return schemaDefault, nil
+	case schemaNormalized:
+		return schemaNormalized, nil
+	case schemaRaw:
+		return schemaRaw, nil
+	default:
+		return "", fmt.Errorf("unknown --schema %q (use normalized or raw)", s)
+	}
+}
+
+// writeOrdersJSON prints an order list for --json: the versioned normalized
+// document, or with --schema raw the upstream payload (raw, or each order's Raw
+// when raw is nil). Without --schema, a non-nil raw is printed as is.
+func (s *state) writeOrdersJSON(out io.Writer, orders []provider.Order, raw any) error {
+	if s.rawJSON(raw) {
+		if raw == nil {
+			raw = rawPayloads(orders)
+		}
+		return writeJSON(out, raw)
+	}
+	return writeJSON(out, provider.NewListDocument(orders))
+}
+
+// writeOrderJSON is writeOrdersJSON for a single order.
+func (s *state) writeOrderJSON(out io.Writer, o provider.Order, raw any) error {
+	if s.rawJSON(raw) {
+		if raw == nil {
+			raw = o.Raw
+		}
+		return writeJSON(out, raw)
+	}
+	return writeJSON(out, provider.NewOrderDocument(o))
+}
+
+func (s *state) rawJSON(raw any) bool {
+	return s.schema == schemaRaw || (s.schema == schemaDefault && raw != nil)
+}
+
+func rawPayloads(orders []provider.Order) []any {
+	out := make([]any, 0, len(orders))
+	for _, o := range orders {
+		out = append(out, o.Raw)
+	}
+	return out
+}
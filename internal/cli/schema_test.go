@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+)
+
+func TestHistoryShowJSON_Schema(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	srv := newFoodoraTestServer(t)
+	defer srv.Close()
+
+	cfg := config.New()
+	fd := cfg.Foodora()
+	fd.BaseURL = srv.URL + "/"
+	fd.TargetCountryISO = "AT"
+	fd.AccessToken = "access"
+	fd.RefreshToken = "refresh"
+	fd.ExpiresAt = time.Now().Add(time.Hour)
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	// history show --json printed the raw payload before --schema existed.
+	out, _, err := runCLI(cfgPath, []string{"foodora", "history", "show", "HIST-1", "--json"}, "")
+	if err != nil {
+		t.Fatalf("default: %v", err)
+	}
+	if !strings.Contains(out, `"order_code": "HIST-1"`) || strings.Contains(out, "schema_version") {
+		t.Fatalf("default output is not the raw payload:\n%s", out)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"--schema", "normalized", "foodora", "history", "show", "HIST-1", "--json"}, "")
+	if err != nil {
+		t.Fatalf("normalized: %v", err)
+	}
+	for _, want := range []string{`"schema_version": 1`, `"order": {`, `"id": "HIST-1"`, `"currency": "EUR"`, `"name": "Burger"`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("normalized output missing %s:\n%s", want, out)
+		}
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"--schema", "raw", "foodora", "history", "show", "HIST-1", "--json"}, "")
+	if err != nil {
+		t.Fatalf("raw: %v", err)
+	}
+	if !strings.Contains(out, `"order_code": "HIST-1"`) || strings.Contains(out, "schema_version") {
+		t.Fatalf("unexpected raw output:\n%s", out)
+	}
+
+	if _, _, err := runCLI(cfgPath, []string{"--schema", "v2", "foodora", "history", "show", "HIST-1"}, ""); err == nil {
+		t.Fatalf("expected error for unknown schema")
+	}
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/provider"
+	"github.com/steipete/ordercli/internal/search"
+)
+
+func newSearchCmd(st *state) *cobra.Command {
+	var providers []string
+	var offline bool
+	var rebuild bool
+	var limit int
+	var maxResults int
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search ordered dishes (names, variations, toppings, notes) across providers",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idx, err := search.Load(search.DefaultPath(st.configPath))
+			if err != nil {
+				return err
+			}
+			if rebuild {
+				if offline {
+					return errors.New("--rebuild needs network access (drop --offline)")
+				}
+				idx.Reset()
+			}
+
+			if !offline {
+				opened, err := openProviders(cmd.Context(), st, providers, func(p providerEntry) bool { return p.caps.History })
+				if err != nil {
+					if idx.Len() == 0 {
+						return err
+					}
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v (using cached index)\n", err)
+				}
+				for _, p := range opened {
+					if _, err := indexProvider(cmd.Context(), idx, p, limit); err != nil {
+						fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %v (using cached index)\n", p.Name(), err)
+					}
+				}
+				if err := idx.Save(); err != nil {
+					return err
+				}
+			}
+
+			hits := idx.Search(strings.Join(args, " "))
+			if maxResults > 0 && len(hits) > maxResults {
+				hits = hits[:maxResults]
+			}
+			r := newRecords("time", "provider", "order_id", "vendor", "line")
+			for _, h := range hits {
+				r.add(h.Doc.When, h.Doc.Provider, h.Doc.OrderID, h.Doc.Vendor, h.Line)
+			}
+			out := cmd.OutOrStdout()
+			return st.renderList(out, r, func() error {
+				if len(hits) == 0 {
+					fmt.Fprintln(out, "no matches")
+					return nil
+				}
+				for _, h := range hits {
+					date := "-"
+					if !h.Doc.When.IsZero() {
+						date = h.Doc.When.Local().Format("2006-01-02")
+					}
+					fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\n", date, h.Doc.Provider, h.Doc.OrderID, h.Doc.Vendor, h.Line)
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&providers, "provider", nil, "only refresh these providers (repeatable)")
+	cmd.Flags().BoolVar(&offline, "offline", false, "don't refresh; search the local index only")
+	cmd.Flags().BoolVar(&rebuild, "rebuild", false, "drop the local index and re-index from scratch")
+	cmd.Flags().IntVar(&limit, "limit", 100, "max new orders to index per provider and run")
+	cmd.Flags().IntVar(&maxResults, "max", 0, "max results to print (0 = all)")
+	return cmd
+}
+
+// indexProvider adds unseen orders newest first. Once a run has indexed the
+// whole history (the index marks the provider complete) later runs stop at
+// the first page that is already fully indexed; until then they page on past
+// indexed orders, so runs capped by limit eventually reach the oldest ones.
+// List items are used when the history carries them (glovo); otherwise each
+// order is fetched via OrderDetail when supported (foodora). The index is
+// saved by the caller, also after a partial failure.
+func indexProvider(ctx context.Context, idx *search.Index, p provider.Provider, limit int) (int, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	detail := p.Capabilities().OrderDetail
+	// A run that stops early (limit, error) may leave a gap of unindexed
+	// orders below the new ones, so completeness is only restored at the end.
+	complete := idx.IsComplete(p.Name())
+	idx.SetComplete(p.Name(), false)
+	added := 0
+	offset := 0
+	for added < limit {
+		page, err := p.History(ctx, provider.HistoryRequest{Offset: offset, Limit: min(20, limit-added)})
+		if err != nil {
+			return added, err
+		}
+
+		allIndexed := true
+		for _, o := range page.Orders {
+			if idx.Has(o.Key()) {
+				continue
+			}
+			allIndexed = false
+			if len(o.Items) == 0 && detail {
+				d, err := p.OrderDetail(ctx, o.ID)
+				if err != nil {
+					return added, fmt.Errorf("order %s: %w", o.ID, err)
+				}
+				d.Provider, d.ID = o.Provider, o.ID
+				if d.Vendor == "" {
+					d.Vendor = o.Vendor
+				}
+				if d.When().IsZero() {
+					d.PlacedAt, d.DeliveredAt = o.PlacedAt, o.DeliveredAt
+				}
+				o = d
+			}
+			idx.Add(o)
+			added++
+		}
+
+		offset += len(page.Orders)
+		if len(page.Orders) == 0 || !page.More || (complete && allIndexed) {
+			idx.SetComplete(p.Name(), true)
+			break
+		}
+	}
+	return added, nil
+}
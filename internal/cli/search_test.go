@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+	"github.com/steipete/ordercli/internal/search"
+)
+
+// detailProvider has item-less history and serves items via OrderDetail.
+type detailProvider struct {
+	pagedProvider
+	details int
+}
+
+func (p *detailProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{History: true, OrderDetail: true}
+}
+
+func (p *detailProvider) OrderDetail(ctx context.Context, id string) (provider.Order, error) {
+	p.details++
+	return provider.Order{Provider: "fake", ID: id, Items: []provider.Item{{Name: "Pad Thai " + id}}}, nil
+}
+
+func TestIndexProvider_FetchesDetailsIncrementally(t *testing.T) {
+	idx, err := search.Load(filepath.Join(t.TempDir(), "index.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	p := &detailProvider{pagedProvider: pagedProvider{ids: []string{"3", "2", "1"}}}
+
+	added, err := indexProvider(context.Background(), idx, p, 100)
+	if err != nil || added != 3 || p.details != 3 {
+		t.Fatalf("first run added=%d details=%d err=%v", added, p.details, err)
+	}
+
+	p.ids = append([]string{"4"}, p.ids...)
+	added, err = indexProvider(context.Background(), idx, p, 100)
+	if err != nil || added != 1 || p.details != 4 {
+		t.Fatalf("second run added=%d details=%d err=%v", added, p.details, err)
+	}
+	if hits := idx.Search("pad thai 4"); len(hits) != 1 || hits[0].Doc.OrderID != "4" {
+		t.Fatalf("unexpected hits: %#v", hits)
+	}
+}
+
+func TestIndexProvider_ResumesCappedRun(t *testing.T) {
+	idx, err := search.Load(filepath.Join(t.TempDir(), "index.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	p := &detailProvider{pagedProvider: pagedProvider{ids: []string{"5", "4", "3", "2", "1"}}}
+
+	added, err := indexProvider(context.Background(), idx, p, 2)
+	if err != nil || added != 2 || idx.IsComplete("fake") {
+		t.Fatalf("capped run added=%d complete=%v err=%v", added, idx.IsComplete("fake"), err)
+	}
+
+	// A new order arrived meanwhile; the next run must still reach the older
+	// pages the capped run never got to.
+	p.ids = append([]string{"6"}, p.ids...)
+	added, err = indexProvider(context.Background(), idx, p, 100)
+	if err != nil || added != 4 || idx.Len() != 6 || !idx.IsComplete("fake") {
+		t.Fatalf("second run added=%d len=%d complete=%v err=%v", added, idx.Len(), idx.IsComplete("fake"), err)
+	}
+	if hits := idx.Search("pad thai 1"); len(hits) != 1 || hits[0].Doc.OrderID != "1" {
+		t.Fatalf("unexpected hits: %#v", hits)
+	}
+
+	// Once complete, a run stops at the first fully indexed page.
+	p.requests = nil
+	if added, err := indexProvider(context.Background(), idx, p, 3); err != nil || added != 0 || len(p.requests) != 1 {
+		t.Fatalf("third run added=%d requests=%v err=%v", added, p.requests, err)
+	}
+}
+
+func TestSearchCLI_WarnsWhenFallingBackToCachedIndex(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.New()
+	cfg.Glovo().AccessToken = "env:ORDERCLI_TEST_UNSET_GLOVO_TOKEN"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	idx, err := search.Load(search.DefaultPath(cfgPath))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	idx.Add(provider.Order{Provider: "glovo", ID: "7", Items: []provider.Item{{Name: "Pad Thai"}}})
+	if err := idx.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	out, errOut, err := runCLI(cfgPath, []string{"search", "pad", "thai"}, "")
+	if err != nil {
+		t.Fatalf("search: %v err=%s", err, errOut)
+	}
+	if !strings.Contains(out, "Pad Thai") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if !strings.Contains(errOut, "warning: glovo:") || !strings.Contains(errOut, "(using cached index)") {
+		t.Fatalf("expected cached index warning, got %q", errOut)
+	}
+}
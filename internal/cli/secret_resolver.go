@@ -1,46 +1,217 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
+	"os/exec"
+	"runtime"
 	"strings"
+	"time"
 
+	"github.com/steipete/ordercli/internal/auth"
+	"github.com/steipete/ordercli/internal/config"
 	"github.com/steipete/ordercli/internal/firebase"
+	"github.com/steipete/ordercli/internal/httpx"
 )
 
+// Secret references: any secret-bearing config value or flag may name where
+// the secret lives instead of holding it. They are resolved when used.
+const (
+	secretRefEnv  = "env:"  // env:NAME
+	secretRefFile = "file:" // file:/run/secrets/x (trailing newline trimmed)
+	secretRefExec = "exec:" // exec:pass show foodora (first line of stdout)
+)
+
+// secretExecTimeout bounds exec: references (a password manager may prompt).
+const secretExecTimeout = time.Minute
+
+// secretRefKind returns the scheme of a secret reference ("env", "file",
+// "exec"), or "" for a literal value.
+func secretRefKind(v string) string {
+	for _, p := range []string{secretRefEnv, secretRefFile, secretRefExec} {
+		if strings.HasPrefix(v, p) {
+			return strings.TrimSuffix(p, ":")
+		}
+	}
+	return ""
+}
+
+// secretLabel masks v for display but names the kind of a reference.
+func secretLabel(v string) string {
+	if kind := secretRefKind(v); kind != "" {
+		return kind + ": reference"
+	}
+	return "***"
+}
+
+// resolveSecret returns v, or what it refers to. Results are cached so an
+// exec: reference runs (and prompts) at most once per process; the plaintext
+// is never stored back into the config.
+func (s *state) resolveSecret(ctx context.Context, v string) (string, error) {
+	v = strings.TrimSpace(v)
+	kind := secretRefKind(v)
+	if kind == "" {
+		return v, nil
+	}
+	s.mu.Lock()
+	out, ok := s.secrets[v]
+	s.mu.Unlock()
+	if ok {
+		return out, nil
+	}
+	ref := strings.TrimSpace(v[len(kind)+1:])
+	if ref == "" {
+		return "", fmt.Errorf("empty secret reference %q", v)
+	}
+
+	switch kind {
+	case "env":
+		val, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("secret reference %s: environment variable not set", v)
+		}
+		out = strings.TrimSpace(val)
+	case "file":
+		b, err := os.ReadFile(ref)
+		if err != nil {
+			return "", fmt.Errorf("secret reference %s: %w", v, err)
+		}
+		out = strings.TrimSpace(string(b))
+	case "exec":
+		b, err := runSecretCommand(ctx, ref, s.stderr)
+		if err != nil {
+			return "", fmt.Errorf("secret reference exec:%s: %w", firstWord(ref), err)
+		}
+		out, _, _ = strings.Cut(string(b), "\n")
+		out = strings.TrimSpace(out)
+	}
+	if out == "" {
+		return "", fmt.Errorf("secret reference %s resolved to an empty value", v)
+	}
+	s.mu.Lock()
+	if s.secrets == nil {
+		s.secrets = map[string]string{}
+	}
+	s.secrets[v] = out
+	s.mu.Unlock()
+	return out, nil
+}
+
+// resolveToken builds the starting token of a client from stored values that
+// may be secret references; expiresAt falls back to the JWT expiry.
+func (s *state) resolveToken(ctx context.Context, access, refresh string, expiresAt time.Time) (auth.Token, error) {
+	var tok auth.Token
+	var err error
+	if tok.AccessToken, err = s.resolveSecret(ctx, access); err != nil {
+		return auth.Token{}, err
+	}
+	if tok.RefreshToken, err = s.resolveSecret(ctx, refresh); err != nil {
+		return auth.Token{}, err
+	}
+	tok.ExpiresAt = expiresAt
+	if tok.ExpiresAt.IsZero() {
+		tok.ExpiresAt, _ = config.AccessTokenExpiresAt(tok.AccessToken)
+	}
+	return tok, nil
+}
+
+// storeRefreshedToken writes a token the CLI renewed into a provider config's
+// fields and reports whether it did. Fields holding secret references are
+// left alone: the renewed token then lives only in memory for this run, and
+// the referenced secret has to be updated by whoever owns it.
+func storeRefreshedToken(access, refresh *string, expiresAt *time.Time, t auth.Token) bool {
+	if secretRefKind(strings.TrimSpace(*access)) != "" || secretRefKind(strings.TrimSpace(*refresh)) != "" {
+		return false
+	}
+	*access = t.AccessToken
+	*refresh = t.RefreshToken
+	*expiresAt = t.ExpiresAt
+	return true
+}
+
+// warnRefreshedRef tells the user a renewed token was not saved over a
+// secret reference (see storeRefreshedToken).
+func (s *state) warnRefreshedRef(provider, account string) {
+	s.warnf("%s/%s: token refreshed for this run only; the config keeps its secret reference (update the referenced token if the refresh token changed)", provider, account)
+}
+
+// runSecretCommand runs command through the shell; stderr is passed through
+// so password managers can show prompts.
+var runSecretCommand = func(ctx context.Context, command string, stderr io.Writer) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, secretExecTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// firstWord keeps error messages from echoing command arguments.
+func firstWord(s string) string {
+	if f := strings.Fields(s); len(f) > 0 {
+		return f[0]
+	}
+	return s
+}
+
 type resolvedSecret struct {
 	Secret     string
 	FromEnv    bool
 	FromConfig bool
 	FromFetch  bool
+	// Ref is the secret reference scheme the value came through, if any.
+	Ref string
 }
 
-func (s *state) resolveClientSecret(ctx context.Context, clientID string) (resolvedSecret, error) {
-	cfg := s.foodora()
-	if clientID == "" {
-		clientID = strings.TrimSpace(cfg.OAuthClientID)
-	}
-	if clientID == "" {
-		clientID = "android"
+// Source names where the secret came from: config, env or remote.
+func (r resolvedSecret) Source() string {
+	switch {
+	case r.FromConfig && r.Ref != "":
+		return "config (" + r.Ref + ":)"
+	case r.FromConfig:
+		return "config"
+	case r.FromEnv && r.Ref != "":
+		return "env (" + r.Ref + ":)"
+	case r.FromEnv:
+		return "env"
+	case r.FromFetch:
+		return "remote"
 	}
+	return ""
+}
 
-	// Only reuse cached secrets when we know which client_id they belong to.
-	// Legacy configs may have a stored secret without oauth_client_id; assume that is for android only.
-	if cfg.ClientSecret != "" && strings.EqualFold(strings.TrimSpace(cfg.OAuthClientID), clientID) {
-		return resolvedSecret{Secret: cfg.ClientSecret, FromConfig: true}, nil
-	}
-	if cfg.ClientSecret != "" && strings.TrimSpace(cfg.OAuthClientID) == "" && clientID == "android" {
-		return resolvedSecret{Secret: cfg.ClientSecret, FromConfig: true}, nil
-	}
-	if v := os.Getenv("FOODORA_CLIENT_SECRET"); v != "" {
-		return resolvedSecret{Secret: v, FromEnv: true}, nil
+// resolveClientSecret finds the OAuth client secret of the foodora account
+// cfg, fetching (and caching) it from Remote Config as a last resort. cfg is
+// passed explicitly: token refreshes of several accounts run concurrently.
+func (s *state) resolveClientSecret(ctx context.Context, cfg *config.FoodoraConfig, clientID string) (resolvedSecret, error) {
+	clientID = oauthClientID(cfg, clientID)
+	if sec, ok, err := s.localClientSecret(ctx, cfg, clientID); err != nil || ok {
+		return sec, err
 	}
 
-	secret, err := fetchClientSecretFromRemoteConfig(ctx, s.firebaseConfig(), s.remoteConfigKeyCandidates(), clientID)
+	httpOpts, err := s.httpOptions(cfg.Network)
+	if err != nil {
+		return resolvedSecret{}, err
+	}
+	secret, err := fetchClientSecretFromRemoteConfig(ctx, httpOpts, s.firebaseConfig(cfg), remoteConfigKeyCandidates(cfg), clientID)
 	if err != nil {
 		return resolvedSecret{}, err
 	}
@@ -49,22 +220,72 @@ func (s *state) resolveClientSecret(ctx context.Context, clientID string) (resol
 	}
 
 	// Cache for next run.
-	cfg.ClientSecret = secret
-	cfg.OAuthClientID = clientID
-	s.markDirty()
+	s.cacheClientSecret(cfg, clientID, secret)
 	return resolvedSecret{Secret: secret, FromFetch: true}, nil
 }
 
-func (s *state) forceFetchClientSecret(ctx context.Context, clientID string) (resolvedSecret, error) {
-	cfg := s.foodora()
+// cacheClientSecret stores a fetched client secret in cfg, unless cfg's
+// secret is a reference: like refreshed tokens (see storeRefreshedToken),
+// the fetched value is then used for this run only.
+func (s *state) cacheClientSecret(cfg *config.FoodoraConfig, clientID, secret string) {
+	stored := true
+	s.update(func() {
+		if secretRefKind(strings.TrimSpace(cfg.ClientSecret)) != "" {
+			stored = false
+			return
+		}
+		cfg.ClientSecret = secret
+		cfg.OAuthClientID = clientID
+	})
+	if !stored {
+		s.warnf("foodora: fetched client secret used for this run only; the config keeps its secret reference (update the referenced secret)")
+	}
+}
+
+// oauthClientID defaults clientID to the one stored in cfg, else "android".
+func oauthClientID(cfg *config.FoodoraConfig, clientID string) string {
 	if clientID == "" {
 		clientID = strings.TrimSpace(cfg.OAuthClientID)
 	}
 	if clientID == "" {
 		clientID = "android"
 	}
+	return clientID
+}
 
-	secret, err := fetchClientSecretFromRemoteConfig(ctx, s.firebaseConfig(), s.remoteConfigKeyCandidates(), clientID)
+// localClientSecret is resolveClientSecret without the Remote Config fetch.
+// Stored and env secrets may be references (see resolveSecret).
+func (s *state) localClientSecret(ctx context.Context, cfg *config.FoodoraConfig, clientID string) (resolvedSecret, bool, error) {
+	stored := cfg.ClientSecret != "" &&
+		(strings.EqualFold(strings.TrimSpace(cfg.OAuthClientID), clientID) ||
+			// Legacy configs may have a stored secret without oauth_client_id; assume that is for android only.
+			(strings.TrimSpace(cfg.OAuthClientID) == "" && clientID == "android"))
+	// Only reuse cached secrets when we know which client_id they belong to.
+	if stored {
+		v, err := s.resolveSecret(ctx, cfg.ClientSecret)
+		if err != nil {
+			return resolvedSecret{}, false, err
+		}
+		return resolvedSecret{Secret: v, FromConfig: true, Ref: secretRefKind(cfg.ClientSecret)}, true, nil
+	}
+	if env := os.Getenv("FOODORA_CLIENT_SECRET"); env != "" {
+		v, err := s.resolveSecret(ctx, env)
+		if err != nil {
+			return resolvedSecret{}, false, err
+		}
+		return resolvedSecret{Secret: v, FromEnv: true, Ref: secretRefKind(env)}, true, nil
+	}
+	return resolvedSecret{}, false, nil
+}
+
+func (s *state) forceFetchClientSecret(ctx context.Context, cfg *config.FoodoraConfig, clientID string) (resolvedSecret, error) {
+	clientID = oauthClientID(cfg, clientID)
+
+	httpOpts, err := s.httpOptions(cfg.Network)
+	if err != nil {
+		return resolvedSecret{}, err
+	}
+	secret, err := fetchClientSecretFromRemoteConfig(ctx, httpOpts, s.firebaseConfig(cfg), remoteConfigKeyCandidates(cfg), clientID)
 	if err != nil {
 		return resolvedSecret{}, err
 	}
@@ -72,14 +293,21 @@ func (s *state) forceFetchClientSecret(ctx context.Context, clientID string) (re
 		return resolvedSecret{}, errors.New("fetched empty client secret")
 	}
 
-	cfg.ClientSecret = secret
-	cfg.OAuthClientID = clientID
-	s.markDirty()
+	s.cacheClientSecret(cfg, clientID, secret)
 	return resolvedSecret{Secret: secret, FromFetch: true}, nil
 }
 
-func (s *state) firebaseConfig() firebase.APKFirebaseConfig {
-	cfg := s.foodora()
+func (s *state) firebaseConfig(cfg *config.FoodoraConfig) firebase.APKFirebaseConfig {
+	if p := s.appProfile(cfg); p != nil {
+		return firebase.APKFirebaseConfig{
+			APIKey:      p.FirebaseAPIKey,
+			ProjectID:   p.FirebaseProjectID,
+			ProjectNum:  p.FirebaseProjectNum,
+			AppID:       p.FirebaseAppID,
+			PackageName: p.PackageName,
+			CertSHA1:    p.CertSHA1,
+		}
+	}
 	if strings.EqualFold(cfg.TargetCountryISO, "AT") {
 		return firebase.MjamAT
 	}
@@ -92,8 +320,7 @@ func (s *state) firebaseConfig() firebase.APKFirebaseConfig {
 	return firebase.NetPincerHU
 }
 
-func (s *state) remoteConfigKeyCandidates() []string {
-	cfg := s.foodora()
+func remoteConfigKeyCandidates(cfg *config.FoodoraConfig) []string {
 	var keys []string
 
 	if u, err := url.Parse(cfg.BaseURL); err == nil {
@@ -124,8 +351,8 @@ func (s *state) remoteConfigKeyCandidates() []string {
 	return out
 }
 
-func fetchClientSecretFromRemoteConfig(ctx context.Context, cfg firebase.APKFirebaseConfig, keys []string, clientID string) (string, error) {
-	rc := firebase.NewRemoteConfigClient(cfg)
+func fetchClientSecretFromRemoteConfig(ctx context.Context, opts httpx.Options, cfg firebase.APKFirebaseConfig, keys []string, clientID string) (string, error) {
+	rc := firebase.NewRemoteConfigClient(cfg, opts)
 	resp, err := rc.Fetch(ctx)
 	if err != nil {
 		return "", err
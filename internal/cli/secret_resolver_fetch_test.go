@@ -15,10 +15,9 @@ type rtFunc func(*http.Request) (*http.Response, error)
 
 func (f rtFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
 
-func TestResolveClientSecret_FetchesAndCaches(t *testing.T) {
-	// touches http.DefaultTransport/env
-	withEnvMap(t, map[string]string{"FOODORA_CLIENT_SECRET": ""})
-
+// fakeRemoteConfig makes Firebase Remote Config serve client secret "sec".
+func fakeRemoteConfig(t *testing.T) {
+	t.Helper()
 	orig := http.DefaultTransport
 	t.Cleanup(func() { http.DefaultTransport = orig })
 
@@ -34,15 +33,21 @@ func TestResolveClientSecret_FetchesAndCaches(t *testing.T) {
 			return &http.Response{StatusCode: 500, Body: io.NopCloser(bytes.NewReader([]byte("unexpected")))}, nil
 		}
 	})
+}
+
+func TestResolveClientSecret_FetchesAndCaches(t *testing.T) {
+	// touches http.DefaultTransport/env
+	withEnvMap(t, map[string]string{"FOODORA_CLIENT_SECRET": ""})
+	fakeRemoteConfig(t)
 
 	st := &state{cfg: config.New(), configPath: "x"}
-	fc := st.foodora()
+	fc := st.ensureFoodora()
 	fc.BaseURL = "https://mj.fd-api.com/api/v5/"
 	fc.TargetCountryISO = "AT"
 	fc.ClientSecret = ""
 	fc.OAuthClientID = ""
 
-	sec, err := st.resolveClientSecret(context.Background(), "android")
+	sec, err := st.resolveClientSecret(context.Background(), st.foodora(), "android")
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -56,7 +61,7 @@ func TestResolveClientSecret_FetchesAndCaches(t *testing.T) {
 		t.Fatalf("expected dirty")
 	}
 
-	sec2, err := st.forceFetchClientSecret(context.Background(), "android")
+	sec2, err := st.forceFetchClientSecret(context.Background(), st.foodora(), "android")
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -64,3 +69,27 @@ func TestResolveClientSecret_FetchesAndCaches(t *testing.T) {
 		t.Fatalf("unexpected: %#v", sec2)
 	}
 }
+
+func TestForceFetchClientSecret_KeepsReference(t *testing.T) {
+	withEnvMap(t, map[string]string{"FOODORA_CLIENT_SECRET": "", "ORDERCLI_TEST_CLIENT_SECRET": "stale"})
+	fakeRemoteConfig(t)
+
+	var stderr bytes.Buffer
+	st := &state{cfg: config.New(), configPath: "x", stderr: &stderr}
+	fc := st.ensureFoodora()
+	fc.BaseURL = "https://mj.fd-api.com/api/v5/"
+	fc.TargetCountryISO = "AT"
+	fc.ClientSecret = "env:ORDERCLI_TEST_CLIENT_SECRET"
+	fc.OAuthClientID = "android"
+
+	sec, err := st.forceFetchClientSecret(context.Background(), fc, "android")
+	if err != nil || sec.Secret != "sec" {
+		t.Fatalf("sec=%#v err=%v", sec, err)
+	}
+	if fc.ClientSecret != "env:ORDERCLI_TEST_CLIENT_SECRET" {
+		t.Fatalf("reference overwritten: %q", fc.ClientSecret)
+	}
+	if !strings.Contains(stderr.String(), "keeps its secret reference") {
+		t.Fatalf("expected warning, got %q", stderr.String())
+	}
+}
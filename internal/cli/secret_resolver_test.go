@@ -2,7 +2,13 @@ package cli
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/steipete/ordercli/internal/config"
@@ -10,11 +16,11 @@ import (
 
 func TestResolveClientSecret_FromConfig(t *testing.T) {
 	st := &state{cfg: config.New()}
-	cfg := st.foodora()
+	cfg := st.ensureFoodora()
 	cfg.ClientSecret = "s"
 	cfg.OAuthClientID = "android"
 
-	sec, err := st.resolveClientSecret(context.Background(), "android")
+	sec, err := st.resolveClientSecret(context.Background(), st.foodora(), "android")
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -25,7 +31,7 @@ func TestResolveClientSecret_FromConfig(t *testing.T) {
 
 func TestResolveClientSecret_FromEnv(t *testing.T) {
 	st := &state{cfg: config.New()}
-	cfg := st.foodora()
+	cfg := st.ensureFoodora()
 	cfg.ClientSecret = ""
 
 	old, had := os.LookupEnv("FOODORA_CLIENT_SECRET")
@@ -38,7 +44,7 @@ func TestResolveClientSecret_FromEnv(t *testing.T) {
 		}
 	})
 
-	sec, err := st.resolveClientSecret(context.Background(), "android")
+	sec, err := st.resolveClientSecret(context.Background(), st.foodora(), "android")
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -49,12 +55,129 @@ func TestResolveClientSecret_FromEnv(t *testing.T) {
 
 func TestRemoteConfigKeyCandidates(t *testing.T) {
 	st := &state{cfg: config.New()}
-	cfg := st.foodora()
+	cfg := st.ensureFoodora()
 	cfg.BaseURL = "https://mj.fd-api.com/api/v5/"
 	cfg.TargetCountryISO = "AT"
 
-	keys := st.remoteConfigKeyCandidates()
+	keys := remoteConfigKeyCandidates(st.foodora())
 	if len(keys) < 2 {
 		t.Fatalf("unexpected keys: %#v", keys)
 	}
 }
+
+func TestResolveSecret_References(t *testing.T) {
+	st := &state{cfg: config.New()}
+	ctx := context.Background()
+
+	t.Setenv("ORDERCLI_TEST_SECRET", " from-env \n")
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for in, want := range map[string]string{
+		"plain":                    "plain",
+		"env:ORDERCLI_TEST_SECRET": "from-env",
+		"file:" + path:             "from-file",
+	} {
+		got, err := st.resolveSecret(ctx, in)
+		if err != nil || got != want {
+			t.Fatalf("resolveSecret(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"env:ORDERCLI_TEST_UNSET", "file:" + path + ".missing", "env:"} {
+		if _, err := st.resolveSecret(ctx, in); err == nil {
+			t.Fatalf("resolveSecret(%q): expected error", in)
+		}
+	}
+}
+
+func TestResolveSecret_ExecRunsOnceAndKeepsFirstLine(t *testing.T) {
+	orig := runSecretCommand
+	defer func() { runSecretCommand = orig }()
+	var runs []string
+	runSecretCommand = func(ctx context.Context, command string, stderr io.Writer) ([]byte, error) {
+		runs = append(runs, command)
+		return []byte("hunter2\nurl: https://example.com\n"), nil
+	}
+
+	st := &state{cfg: config.New()}
+	for range 2 {
+		got, err := st.resolveSecret(context.Background(), "exec:pass show foodora")
+		if err != nil || got != "hunter2" {
+			t.Fatalf("got %q, %v", got, err)
+		}
+	}
+	if len(runs) != 1 || runs[0] != "pass show foodora" {
+		t.Fatalf("runs = %q", runs)
+	}
+}
+
+func TestRunSecretCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	st := &state{cfg: config.New()}
+	got, err := st.resolveSecret(context.Background(), "exec:printf 'abc\\ndef'")
+	if err != nil || got != "abc" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	if _, err := st.resolveSecret(context.Background(), "exec:exit 3"); err == nil {
+		t.Fatalf("expected error for failing command")
+	}
+}
+
+func TestResolveClientSecret_Reference(t *testing.T) {
+	t.Setenv("ORDERCLI_TEST_CLIENT_SECRET", "resolved")
+	st := &state{cfg: config.New()}
+	cfg := st.ensureFoodora()
+	cfg.ClientSecret = "env:ORDERCLI_TEST_CLIENT_SECRET"
+	cfg.OAuthClientID = "android"
+
+	sec, err := st.resolveClientSecret(context.Background(), st.foodora(), "android")
+	if err != nil || sec.Secret != "resolved" || sec.Source() != "config (env:)" {
+		t.Fatalf("unexpected: %#v, %v", sec, err)
+	}
+	if cfg.ClientSecret != "env:ORDERCLI_TEST_CLIENT_SECRET" {
+		t.Fatalf("reference was replaced: %q", cfg.ClientSecret)
+	}
+}
+
+func TestSecretReferences_NotWrittenBack(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv("ORDERCLI_TEST_GLOVO_TOKEN", "glovo-plaintext")
+
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"pagination":{"currentLimit":12},"orders":[]}`))
+	}))
+	defer srv.Close()
+
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "config", "set", "--base-url", srv.URL}, ""); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "session", "env:ORDERCLI_TEST_GLOVO_TOKEN"}, ""); err != nil {
+		t.Fatalf("session: %v", err)
+	}
+	if _, _, err := runCLI(cfgPath, []string{"glovo", "history"}, ""); err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if gotAuth != "Bearer glovo-plaintext" {
+		t.Fatalf("reference not resolved: %q", gotAuth)
+	}
+	out, _, _ := runCLI(cfgPath, []string{"glovo", "config", "show"}, "")
+	if !strings.Contains(out, "access_token=env: reference") {
+		t.Fatalf("unexpected show: %s", out)
+	}
+
+	b, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "glovo-plaintext") || !strings.Contains(string(b), "env:ORDERCLI_TEST_GLOVO_TOKEN") {
+		t.Fatalf("config should keep the reference only:\n%s", b)
+	}
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/provider"
+	"github.com/steipete/ordercli/internal/server"
+)
+
+func newServeCmd(st *state) *cobra.Command {
+	var addr string
+	var token string
+	var cacheTTL time.Duration
+	var allowRemote bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve orders over a local HTTP/JSON API (bearer token auth)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return fmt.Errorf("invalid --addr: %w", err)
+			}
+			if !allowRemote && !isLoopbackHost(host) {
+				return fmt.Errorf("refusing to listen on %s (not loopback); pass --allow-remote to override", addr)
+			}
+
+			if token == "" {
+				token = strings.TrimSpace(os.Getenv("ORDERCLI_SERVE_TOKEN"))
+			}
+			if token == "" {
+				b := make([]byte, 24)
+				if _, err := rand.Read(b); err != nil {
+					return err
+				}
+				token = hex.EncodeToString(b)
+				fmt.Fprintf(cmd.ErrOrStderr(), "token: %s (set ORDERCLI_SERVE_TOKEN or --token to fix it)\n", token)
+			}
+
+			srv, err := server.New(server.Options{
+				Token:    token,
+				CacheTTL: cacheTTL,
+				Open: func(ctx context.Context, only []string) ([]provider.Provider, error) {
+					for _, name := range only {
+						if _, ok := findProvider(name); !ok {
+							return nil, fmt.Errorf("%w: unknown provider %q", server.ErrNotFound, name)
+						}
+					}
+					return openProviders(ctx, st, only, nil)
+				},
+				// Sessions refreshed while serving are written back right away.
+				AfterUpstream: st.save,
+			})
+			if err != nil {
+				return err
+			}
+
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			httpSrv := &http.Server{Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = httpSrv.Shutdown(shutdownCtx)
+			}()
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "listening on http://%s\n", ln.Addr())
+			if err := httpSrv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8765", "listen address")
+	cmd.Flags().StringVar(&token, "token", "", "bearer token clients must send (default: ORDERCLI_SERVE_TOKEN or a random one)")
+	cmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 30*time.Second, "reuse responses this long (0 disables)")
+	cmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "allow listening on non-loopback addresses")
+	return cmd
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
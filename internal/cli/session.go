@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/auth"
 	"github.com/steipete/ordercli/internal/chromecookies"
+	"github.com/steipete/ordercli/internal/config"
 	"github.com/steipete/ordercli/internal/foodora"
 	"github.com/steipete/ordercli/internal/version"
 )
@@ -36,7 +38,7 @@ func newSessionChromeCmd(st *state) *cobra.Command {
 		Use:   "chrome",
 		Short: "Import refresh_token (+ device_token) from Chrome cookies",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := st.foodora()
+			cfg := st.ensureFoodora()
 			if cfg.BaseURL == "" {
 				return errors.New("missing base_url (run `ordercli foodora config set --country ...`)")
 			}
@@ -128,13 +130,17 @@ func newSessionRefreshCmd(st *state) *cobra.Command {
 			if cfg.RefreshToken == "" {
 				return errors.New("missing refresh_token (run `ordercli foodora login ...` or `ordercli foodora session chrome ...`)")
 			}
+			cur, err := st.resolveToken(cmd.Context(), cfg.AccessToken, cfg.RefreshToken, cfg.ExpiresAt)
+			if err != nil {
+				return err
+			}
 
 			clientID := strings.TrimSpace(forceClientID)
 			if clientID == "" {
 				clientID = strings.TrimSpace(cfg.OAuthClientID)
 			}
 			if clientID == "" {
-				if cid, ok := jwtClientID(cfg.AccessToken); ok {
+				if cid, ok := jwtClientID(cur.AccessToken); ok {
 					clientID = cid
 				}
 			}
@@ -142,7 +148,7 @@ func newSessionRefreshCmd(st *state) *cobra.Command {
 				clientID = "android"
 			}
 
-			sec, err := st.resolveClientSecret(cmd.Context(), clientID)
+			sec, err := st.resolveClientSecret(cmd.Context(), cfg, clientID)
 			if err != nil {
 				return err
 			}
@@ -157,6 +163,10 @@ func newSessionRefreshCmd(st *state) *cobra.Command {
 				ua = "ordercli/" + version.Version
 			}
 
+			httpOpts, err := st.httpOptions(cfg.Network)
+			if err != nil {
+				return err
+			}
 			c, err := foodora.New(foodora.Options{
 				BaseURL:          cfg.BaseURL,
 				DeviceID:         cfg.DeviceID,
@@ -166,6 +176,7 @@ func newSessionRefreshCmd(st *state) *cobra.Command {
 				CookieHeader:     cookie,
 				FPAPIKey:         prof.FPAPIKey,
 				AppName:          prof.AppName,
+				HTTP:             httpOpts,
 				OriginalUserAgent: func() string {
 					if strings.HasPrefix(ua, "Android-app-") {
 						return ua
@@ -179,14 +190,14 @@ func newSessionRefreshCmd(st *state) *cobra.Command {
 
 			now := time.Now()
 			tok, err := c.OAuthTokenRefresh(cmd.Context(), foodora.OAuthRefreshRequest{
-				RefreshToken: cfg.RefreshToken,
+				RefreshToken: cur.RefreshToken,
 				ClientSecret: sec.Secret,
 				ClientID:     clientID,
 			})
 			if err != nil && isInvalidClientErr(err) {
-				if sec2, ferr := st.forceFetchClientSecret(cmd.Context(), clientID); ferr == nil {
+				if sec2, ferr := st.forceFetchClientSecret(cmd.Context(), cfg, clientID); ferr == nil {
 					tok, err = c.OAuthTokenRefresh(cmd.Context(), foodora.OAuthRefreshRequest{
-						RefreshToken: cfg.RefreshToken,
+						RefreshToken: cur.RefreshToken,
 						ClientSecret: sec2.Secret,
 						ClientID:     clientID,
 					})
@@ -196,18 +207,21 @@ func newSessionRefreshCmd(st *state) *cobra.Command {
 				return err
 			}
 
-			cfg.AccessToken = tok.AccessToken
-			if tok.RefreshToken != "" {
-				cfg.RefreshToken = tok.RefreshToken
+			next := auth.Token{AccessToken: tok.AccessToken, RefreshToken: tok.RefreshToken, ExpiresAt: tok.ExpiresAt(now)}
+			if next.RefreshToken == "" {
+				next.RefreshToken = cur.RefreshToken
 			}
-			cfg.ExpiresAt = tok.ExpiresAt(now)
-			if cfg.ExpiresAt.IsZero() {
-				if exp, ok := cfg.AccessTokenExpiresAt(); ok {
-					cfg.ExpiresAt = exp
-				}
+			if next.ExpiresAt.IsZero() {
+				next.ExpiresAt, _ = config.AccessTokenExpiresAt(next.AccessToken)
+			}
+			stored := true
+			st.update(func() {
+				stored = storeRefreshedToken(&cfg.AccessToken, &cfg.RefreshToken, &cfg.ExpiresAt, next)
+				cfg.OAuthClientID = clientID
+			})
+			if !stored {
+				st.warnRefreshedRef("foodora", st.accountFor("foodora"))
 			}
-			cfg.OAuthClientID = clientID
-			st.markDirty()
 			fmt.Fprintln(cmd.OutOrStdout(), "ok")
 			return nil
 		},
@@ -1,23 +1,141 @@
 package cli
 
 import (
+	"bufio"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/steipete/ordercli/internal/cassette"
 	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/httpx"
+	"golang.org/x/term"
+)
+
+// Passphrase sources for encrypted configs (see `ordercli config encrypt`).
+const (
+	envPassphrase    = "ORDERCLI_PASSPHRASE"
+	envNewPassphrase = "ORDERCLI_NEW_PASSPHRASE"
 )
 
 type state struct {
 	configPath string
-	cfg        config.Config
-	dirty      bool
+	// mu guards cfg, dirty and secrets against the token refreshes and secret
+	// lookups that run concurrently when a command fetches several accounts
+	// at once (see timeline.go). Use update to change cfg from there.
+	mu    sync.Mutex
+	cfg   config.Config
+	dirty bool
+	// base is cfg as last read from or written to disk; save writes only
+	// what changed since (see config.Update).
+	base config.Config
+
+	// output is the --output format ("" = command's text output).
+	output string
+	// schema selects what --json prints for orders: schemaNormalized,
+	// schemaRaw or schemaDefault (see rawJSON).
+	schema string
+
+	// account is the --account flag; it overrides `account use` for every
+	// provider. openProviders also sets it while opening each account.
+	account string
+
+	// upgraded is set when the config file has an old format version; save
+	// backs the file up before writing the new format.
+	upgraded bool
+
+	// stderr receives warnings (cmd.ErrOrStderr of the running command).
+	stderr io.Writer
+
+	// stdin is shared so several passphrase reads consume successive lines.
+	stdin *bufio.Reader
+
+	// secrets caches resolved secret references (see resolveSecret) for the
+	// life of the process. They never go into cfg.
+	secrets map[string]string
+
+	// network holds the global --proxy/--ca-file/--http-timeout/
+	// --insecure-skip-verify flags; replayer, recorder and tracer are the
+	// --replay/--record/--trace layers (see network.go).
+	network  config.Network
+	replayer http.RoundTripper
+	recorder *cassette.Recorder
+	tracer   *httpx.Tracer
+	// replaying is set by --replay: responses are recorded fakes, so config
+	// changes (e.g. replayed tokens) are never saved.
+	replaying bool
+	// warnedInsecure is set once the insecure-skip-verify warning was shown.
+	warnedInsecure bool
 }
 
-func (s *state) foodora() *config.FoodoraConfig { return s.cfg.Foodora() }
+// foodora, deliveroo and glovo are the current account's provider config for
+// reading: a missing section is not added to the config, so commands that
+// only look (history, stats, auth status, ...) never save empty sections.
+// Commands that store settings or a session use the ensure* variants.
+func (s *state) foodora() *config.FoodoraConfig {
+	return s.cfg.LookupFoodoraAccount(s.accountFor("foodora"))
+}
 
-func (s *state) deliveroo() *config.DeliverooConfig { return s.cfg.Deliveroo() }
+// foodoraAccount is the config of the named foodora account. Unlike foodora
+// it is safe to call while other accounts refresh their tokens.
+func (s *state) foodoraAccount(account string) *config.FoodoraConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg.FoodoraAccount(account)
+}
 
-func (s *state) glovo() *config.GlovoConfig { return s.cfg.Glovo() }
+func (s *state) deliveroo() *config.DeliverooConfig {
+	return s.cfg.LookupDeliverooAccount(s.accountFor("deliveroo"))
+}
+
+func (s *state) glovo() *config.GlovoConfig { return s.cfg.LookupGlovoAccount(s.accountFor("glovo")) }
+
+// ensureFoodora, ensureDeliveroo and ensureGlovo are the write-side getters:
+// they add the current account's section when it is missing.
+func (s *state) ensureFoodora() *config.FoodoraConfig {
+	return s.cfg.FoodoraAccount(s.accountFor("foodora"))
+}
+
+func (s *state) ensureDeliveroo() *config.DeliverooConfig {
+	return s.cfg.DeliverooAccount(s.accountFor("deliveroo"))
+}
+
+func (s *state) ensureGlovo() *config.GlovoConfig {
+	return s.cfg.GlovoAccount(s.accountFor("glovo"))
+}
+
+// accountFor is the account provider commands act on.
+func (s *state) accountFor(provider string) string {
+	if s.account != "" {
+		return s.account
+	}
+	return s.cfg.ActiveAccount(provider)
+}
+
+// accountsFor lists the accounts cross-provider commands visit: only the
+// --account one when given, else all of them.
+func (s *state) accountsFor(provider string) []string {
+	if s.account != "" {
+		if s.cfg.HasAccount(provider, s.account) {
+			return []string{s.account}
+		}
+		return nil
+	}
+	return s.cfg.AccountNames(provider)
+}
+
+// inAccount runs fn with every provider switched to account.
+func (s *state) inAccount(account string, fn func() error) error {
+	prev := s.account
+	s.account = account
+	defer func() { s.account = prev }()
+	return fn()
+}
 
 func (s *state) load() error {
 	if s.configPath == "" {
@@ -45,6 +163,7 @@ func (s *state) load() error {
 					}
 					s.configPath = p
 					s.cfg = cfg
+					s.base = config.New()
 					s.dirty = true // migrate to new path on exit
 					return nil
 				}
@@ -57,21 +176,130 @@ func (s *state) load() error {
 		return err
 	}
 	s.cfg = cfg
+	if cfg.LoadedVersion() < config.CurrentVersion {
+		if _, err := os.Stat(s.configPath); err == nil {
+			s.upgraded = true
+			s.dirty = true
+		}
+	}
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	s.base = s.cfg.Clone()
 	return nil
 }
 
+// unlock decrypts secret fields of an encrypted config.
+func (s *state) unlock() error {
+	if !s.cfg.Encrypted() {
+		return nil
+	}
+	pass, err := s.readPassphrase("Config passphrase: ", envPassphrase)
+	if err != nil {
+		return err
+	}
+	return s.cfg.Unlock(pass)
+}
+
+// readPassphrase takes the passphrase from env, else prompts on a terminal,
+// else reads the next line of stdin.
+func (s *state) readPassphrase(prompt, env string) ([]byte, error) {
+	if v := os.Getenv(env); v != "" {
+		return []byte(v), nil
+	}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprint(os.Stderr, prompt)
+		b, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+	if s.stdin == nil {
+		s.stdin = bufio.NewReader(os.Stdin)
+	}
+	line, err := s.stdin.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("no passphrase (set %s or pipe it on stdin)", env)
+	}
+	return []byte(line), nil
+}
+
 func (s *state) save() error {
-	if !s.dirty {
+	if !s.isDirty() || s.replaying {
 		return nil
 	}
 	if s.configPath == "" {
 		return errors.New("internal: configPath unset")
 	}
-	if err := config.Save(s.configPath, s.cfg); err != nil {
+	if s.upgraded {
+		if _, err := config.Backup(s.configPath, time.Now()); err != nil {
+			return fmt.Errorf("backup before config upgrade: %w", err)
+		}
+		s.upgraded = false
+	}
+	return s.write()
+}
+
+// sync writes pending changes right away and picks up what other processes
+// saved meanwhile (e.g. tokens refreshed by `login` in another terminal).
+// Long-running commands call it between rounds. Provider configs obtained
+// before the call are stale afterwards; fetch them again.
+func (s *state) sync() error {
+	if s.replaying {
+		return nil
+	}
+	if s.isDirty() {
+		return s.save()
+	}
+	if _, err := os.Stat(s.configPath); err != nil {
+		return nil
+	}
+	return s.write()
+}
+
+func (s *state) write() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	merged, err := config.Update(s.configPath, s.base, s.cfg)
+	if err != nil {
 		return err
 	}
+	s.cfg = merged
+	s.base = merged.Clone()
 	s.dirty = false
 	return nil
 }
 
-func (s *state) markDirty() { s.dirty = true }
+func (s *state) markDirty() {
+	s.mu.Lock()
+	s.dirty = true
+	s.mu.Unlock()
+}
+
+func (s *state) isDirty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dirty
+}
+
+// update runs fn, which changes cfg, under mu and marks the config dirty.
+func (s *state) update(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn()
+	s.dirty = true
+}
+
+func (s *state) warnf(format string, args ...any) {
+	w := s.stderr
+	if w == nil {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "warning: "+format+"\n", args...)
+}
@@ -73,3 +73,85 @@ func withEnvMap(t *testing.T, m map[string]string) {
 		}
 	})
 }
+
+func TestStateSave_KeepsOtherProcessTokens(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	cfg := config.New()
+	cfg.Foodora().AccessToken = "old"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	watcher := &state{configPath: cfgPath}
+	login := &state{configPath: cfgPath}
+	for _, st := range []*state{watcher, login} {
+		if err := st.load(); err != nil {
+			t.Fatalf("load: %v", err)
+		}
+	}
+
+	login.foodora().AccessToken = "fresh"
+	login.markDirty()
+	if err := login.save(); err != nil {
+		t.Fatalf("login save: %v", err)
+	}
+
+	// The long-running process picks up the new session on sync...
+	if err := watcher.sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if got := watcher.foodora().AccessToken; got != "fresh" {
+		t.Fatalf("expected synced token, got %q", got)
+	}
+	// ...and its own later changes don't clobber it.
+	watcher.foodora().HTTPUserAgent = "ua"
+	watcher.markDirty()
+	if err := watcher.save(); err != nil {
+		t.Fatalf("watcher save: %v", err)
+	}
+	got, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got.Foodora().AccessToken != "fresh" || got.Foodora().HTTPUserAgent != "ua" {
+		t.Fatalf("unexpected config: %#v", got.Foodora())
+	}
+}
+
+func TestStateProviderGetters_ReadsDontAddSections(t *testing.T) {
+	cfg := config.New()
+	if err := cfg.AddAccount("glovo", "work"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	st := &state{cfg: cfg}
+
+	for _, account := range []string{config.DefaultAccount, "work", "unknown"} {
+		_ = st.inAccount(account, func() error {
+			if st.foodora().HasSession() || st.deliveroo().HasSession() {
+				t.Fatalf("%s: unexpected session", account)
+			}
+			if st.glovo().BaseURL == "" {
+				t.Fatalf("%s: glovo defaults missing", account)
+			}
+			return nil
+		})
+	}
+	if st.cfg.Providers.Foodora != nil || st.cfg.Providers.Deliveroo != nil || st.cfg.Providers.Glovo != nil {
+		t.Fatalf("default sections added: %#v", st.cfg.Providers)
+	}
+	if w := st.cfg.Accounts["work"]; w == nil || w.Foodora != nil || w.Deliveroo != nil || w.Glovo == nil {
+		t.Fatalf("unexpected work account: %#v", w)
+	}
+	if _, ok := st.cfg.Accounts["unknown"]; ok {
+		t.Fatalf("unknown account added")
+	}
+
+	st.account = "work"
+	st.ensureFoodora().TargetCountryISO = "AT"
+	if fc := st.cfg.Accounts["work"].Foodora; fc == nil || fc.TargetCountryISO != "AT" || fc.DeviceID == "" {
+		t.Fatalf("ensureFoodora did not add the section: %#v", fc)
+	}
+	if st.foodora() != st.cfg.Accounts["work"].Foodora {
+		t.Fatalf("foodora doesn't return the stored section")
+	}
+}
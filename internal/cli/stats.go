@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/archive"
+	"github.com/steipete/ordercli/internal/provider"
+	"github.com/steipete/ordercli/internal/stats"
+)
+
+func newStatsCmd(st *state) *cobra.Command {
+	var limit int
+	var providers []string
+	var fromArchive bool
+	var top int
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Spend per month, vendor, provider and weekday (per currency)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var orders []provider.Order
+			if fromArchive {
+				a, err := archive.Open(archive.DefaultDir(st.configPath))
+				if err != nil {
+					return err
+				}
+				all, err := a.Orders()
+				if err != nil {
+					return err
+				}
+				orders = filterProviders(all, providers)
+			} else {
+				opened, err := openProviders(cmd.Context(), st, providers, func(p providerEntry) bool { return p.caps.History })
+				if err != nil {
+					return err
+				}
+				var errs []error
+				orders, errs = fetchTimeline(cmd.Context(), opened, limit)
+				for _, err := range errs {
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", err)
+				}
+				if len(errs) == len(opened) {
+					return errors.New("all providers failed")
+				}
+			}
+
+			report := stats.Compute(orders, time.Local)
+			if asJSON {
+				return writeJSON(cmd.OutOrStdout(), report)
+			}
+			return st.renderList(cmd.OutOrStdout(), statsRecords(report), func() error {
+				if report.Orders == 0 {
+					fmt.Fprintln(cmd.OutOrStdout(), "no orders")
+					return nil
+				}
+				printStats(cmd.OutOrStdout(), report, top)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 500, "max orders to fetch per provider")
+	cmd.Flags().StringSliceVar(&providers, "provider", nil, "only include these providers (repeatable)")
+	cmd.Flags().BoolVar(&fromArchive, "archive", false, "use the local archive (filled by sync) instead of fetching")
+	cmd.Flags().IntVar(&top, "top", 5, "number of top vendors to show")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the full report as JSON")
+	return cmd
+}
+
+func filterProviders(orders []provider.Order, only []string) []provider.Order {
+	if len(only) == 0 {
+		return orders
+	}
+	want := map[string]bool{}
+	for _, name := range only {
+		want[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	var out []provider.Order
+	for _, o := range orders {
+		if want[o.Provider] {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// statsRecords flattens a report into one row per currency and bucket.
+// dimension is total, average, vendor, provider, month or weekday.
+func statsRecords(r stats.Report) *records {
+	out := newRecords("currency", "dimension", "key", "orders", "total")
+	for _, s := range r.Currencies {
+		out.add(s.Currency, "total", "", s.Orders, s.Total)
+		out.add(s.Currency, "average", "", s.Orders, s.Average)
+		for _, dim := range []struct {
+			name    string
+			buckets []stats.Bucket
+		}{
+			{"vendor", s.ByVendor},
+			{"provider", s.ByProvider},
+			{"month", s.ByMonth},
+			{"weekday", s.ByWeekday},
+		} {
+			for _, b := range dim.buckets {
+				out.add(s.Currency, dim.name, b.Key, b.Orders, b.Total)
+			}
+		}
+	}
+	return out
+}
+
+func printStats(out io.Writer, r stats.Report, top int) {
+	f := r.Frequency
+	fmt.Fprintf(out, "orders=%d\n", r.Orders)
+	if f.Dated > 0 {
+		fmt.Fprintf(out, "range=%s..%s\tdays=%d\tper_week=%.2f\tper_month=%.2f\tavg_gap_days=%.1f\n",
+			f.First.Format("2006-01-02"), f.Last.Format("2006-01-02"), f.Days, f.PerWeek, f.PerMonth, f.AvgGapDays)
+	}
+	if f.Undated > 0 {
+		fmt.Fprintf(out, "undated=%d (not in month/weekday/frequency)\n", f.Undated)
+	}
+	if r.Cancelled > 0 {
+		fmt.Fprintf(out, "cancelled=%d (cancelled or failed, not counted)\n", r.Cancelled)
+	}
+
+	for _, s := range r.Currencies {
+		fmt.Fprintf(out, "\n== %s ==\n", s.Currency)
+		fmt.Fprintf(out, "total=%s\torders=%d\taverage=%s\n", money(s.Total, s.Currency), s.Orders, money(s.Average, s.Currency))
+		printBuckets(out, "top vendors", s.TopVendors(top), s.Currency)
+		printBuckets(out, "by provider", s.ByProvider, s.Currency)
+		printBuckets(out, "by month", s.ByMonth, s.Currency)
+		printBuckets(out, "by weekday", s.ByWeekday, s.Currency)
+	}
+}
+
+func printBuckets(out io.Writer, title string, buckets []stats.Bucket, currency string) {
+	if len(buckets) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "%s:\n", title)
+	for _, b := range buckets {
+		fmt.Fprintf(out, "  %s\t%d\t%s\n", b.Key, b.Orders, money(b.Total, currency))
+	}
+}
+
+func money(v float64, currency string) string {
+	if currency == stats.UnknownCurrency {
+		return fmt.Sprintf("%.2f", v)
+	}
+	return fmt.Sprintf("%.2f %s", v, currency)
+}
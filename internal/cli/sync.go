@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/archive"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func newSyncCmd(st *state) *cobra.Command {
+	var providers []string
+	var pageSize int
+	var full bool
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Archive order history locally (incremental; stops at already-archived orders once complete)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dir == "" {
+				dir = archive.DefaultDir(st.configPath)
+			}
+			a, err := archive.Open(dir)
+			if err != nil {
+				return err
+			}
+			opened, err := openProviders(cmd.Context(), st, providers, func(p providerEntry) bool { return p.caps.History })
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			failed := 0
+			r := newRecords("provider", "new", "error")
+			for _, p := range opened {
+				added, err := syncProvider(cmd.Context(), a, p, pageSize, full)
+				msg := ""
+				if err != nil {
+					failed++
+					msg = err.Error()
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %v\n", p.Name(), err)
+				}
+				r.add(p.Name(), added, msg)
+			}
+			err = st.renderList(out, r, func() error {
+				for _, row := range r.rows {
+					fmt.Fprintf(out, "%s\tnew=%d\n", row[0], row[1])
+				}
+				fmt.Fprintf(out, "archive=%s\torders=%d\n", a.Dir(), a.Len())
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if failed == len(opened) {
+				return fmt.Errorf("sync failed for all providers")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&providers, "provider", nil, "only sync these providers (repeatable)")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "history page size")
+	cmd.Flags().BoolVar(&full, "full", false, "page through the whole history (don't stop at archived orders)")
+	cmd.Flags().StringVar(&dir, "dir", "", "archive dir (default: archive/ next to config)")
+	return cmd
+}
+
+// syncProvider pages newest-first history and appends unseen orders after every
+// page, so an interrupted sync keeps its progress. Once a sync has reached the
+// end of the history (the archive marks the provider complete), later ones
+// stop at the first page that contains an archived order unless full is set;
+// until then they page on past archived orders to pick up older ones.
+func syncProvider(ctx context.Context, a *archive.Archive, p provider.Provider, pageSize int, full bool) (int, error) {
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	// A sync that stops early may leave a gap below the orders it added, so
+	// completeness is only restored once it gets through.
+	complete := a.IsComplete(p.Name())
+	if err := a.SetComplete(p.Name(), false); err != nil {
+		return 0, err
+	}
+	added := 0
+	offset := 0
+	for {
+		page, err := p.History(ctx, provider.HistoryRequest{Offset: offset, Limit: pageSize})
+		if err != nil {
+			return added, err
+		}
+
+		now := time.Now()
+		reachedArchived := false
+		records := make([]archive.Record, 0, len(page.Orders))
+		for _, o := range page.Orders {
+			if a.Has(o.Key()) {
+				reachedArchived = true
+				continue
+			}
+			records = append(records, archive.NewRecord(o, now))
+		}
+		n, err := a.Append(records)
+		added += n
+		if err != nil {
+			return added, err
+		}
+
+		offset += len(page.Orders)
+		if len(page.Orders) == 0 || !page.More || (complete && reachedArchived && !full) {
+			return added, a.SetComplete(p.Name(), true)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/steipete/ordercli/internal/archive"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// pagedProvider serves ids newest first ("n", "n-1", ...) and records the requests.
+type pagedProvider struct {
+	fakeProvider
+	ids      []string
+	requests []provider.HistoryRequest
+}
+
+func (p *pagedProvider) History(ctx context.Context, req provider.HistoryRequest) (provider.HistoryPage, error) {
+	p.requests = append(p.requests, req)
+	end := min(req.Offset+req.Limit, len(p.ids))
+	var page provider.HistoryPage
+	for _, id := range p.ids[min(req.Offset, end):end] {
+		page.Orders = append(page.Orders, provider.Order{Provider: "fake", ID: id})
+	}
+	page.More = end < len(p.ids)
+	return page, nil
+}
+
+func TestSyncProvider_StopsAtArchived(t *testing.T) {
+	a, err := archive.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	p := &pagedProvider{}
+	for i := 10; i >= 1; i-- {
+		p.ids = append(p.ids, fmt.Sprint(i))
+	}
+	added, err := syncProvider(context.Background(), a, p, 3, false)
+	if err != nil || added != 10 {
+		t.Fatalf("first sync added=%d err=%v", added, err)
+	}
+
+	// Two new orders on top: one page is enough to hit archived ones.
+	p.ids = append([]string{"12", "11"}, p.ids...)
+	p.requests = nil
+	added, err = syncProvider(context.Background(), a, p, 3, false)
+	if err != nil || added != 2 {
+		t.Fatalf("second sync added=%d err=%v", added, err)
+	}
+	if len(p.requests) != 1 {
+		t.Fatalf("expected 1 page request, got %d", len(p.requests))
+	}
+	if a.Len() != 12 {
+		t.Fatalf("archive len=%d", a.Len())
+	}
+}
+
+func TestSyncProvider_ResumesInterruptedSync(t *testing.T) {
+	a, err := archive.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	p := &failingPagedProvider{pagedProvider: pagedProvider{ids: []string{"6", "5", "4", "3", "2", "1"}}, failAt: 3}
+	added, err := syncProvider(context.Background(), a, p, 3, false)
+	if err == nil || added != 3 || a.IsComplete("fake") {
+		t.Fatalf("interrupted sync added=%d complete=%v err=%v", added, a.IsComplete("fake"), err)
+	}
+
+	// The next sync must get past the archived first page to the older one.
+	p.failAt = -1
+	added, err = syncProvider(context.Background(), a, p, 3, false)
+	if err != nil || added != 3 || a.Len() != 6 || !a.IsComplete("fake") {
+		t.Fatalf("second sync added=%d len=%d complete=%v err=%v", added, a.Len(), a.IsComplete("fake"), err)
+	}
+
+	reopened, err := archive.Open(a.Dir())
+	if err != nil || !reopened.IsComplete("fake") {
+		t.Fatalf("complete marker not persisted: %v", err)
+	}
+}
+
+// failingPagedProvider fails History requests at offset failAt.
+type failingPagedProvider struct {
+	pagedProvider
+	failAt int
+}
+
+func (p *failingPagedProvider) History(ctx context.Context, req provider.HistoryRequest) (provider.HistoryPage, error) {
+	if req.Offset == p.failAt {
+		return provider.HistoryPage{}, errors.New("boom")
+	}
+	return p.pagedProvider.History(ctx, req)
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/ordercli/internal/config"
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func newTimelineCmd(st *state) *cobra.Command {
+	var limit int
+	var providers []string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Merged order timeline across all configured providers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opened, err := openProviders(cmd.Context(), st, providers, func(p providerEntry) bool { return p.caps.History })
+			if err != nil {
+				return err
+			}
+
+			orders, errs := fetchTimeline(cmd.Context(), opened, limit)
+			for _, err := range errs {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", err)
+			}
+			if len(errs) == len(opened) {
+				return errors.New("all providers failed")
+			}
+
+			if asJSON {
+				return st.writeOrdersJSON(cmd.OutOrStdout(), orders, nil)
+			}
+			return st.renderList(cmd.OutOrStdout(), orderRecords(orders), func() error {
+				if len(orders) == 0 {
+					fmt.Fprintln(cmd.OutOrStdout(), "no past orders")
+					return nil
+				}
+				printOrderLines(cmd.OutOrStdout(), orders)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "max orders to fetch per provider")
+	cmd.Flags().StringSliceVar(&providers, "provider", nil, "only include these providers (repeatable: "+strings.Join(providerNames(), ", ")+")")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print orders as JSON (see --schema)")
+	return cmd
+}
+
+// openProviders opens every registered provider that has credentials and passes
+// filter. Providers without a session are skipped silently unless named in only.
+// Providers or accounts that fail to open are reported as warnings; it only
+// fails when nothing could be opened.
+func openProviders(ctx context.Context, st *state, only []string, filter func(providerEntry) bool) ([]provider.Provider, error) {
+	want := map[string]bool{}
+	for _, name := range only {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		p, ok := findProvider(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown provider %q (known: %s)", name, strings.Join(providerNames(), ", "))
+		}
+		want[p.name] = true
+	}
+
+	var opened []provider.Provider
+	var errs []error
+	for _, p := range providerRegistry {
+		if len(want) > 0 && !want[p.name] {
+			continue
+		}
+		if filter != nil && !filter(p) {
+			continue
+		}
+		current := st.accountFor(p.name)
+		for _, account := range st.accountsFor(p.name) {
+			err := st.inAccount(account, func() error {
+				// A provider asked for by name is opened even without a
+				// session (for its error), but only in its current account.
+				if !p.configured(st) && (!want[p.name] || account != current) {
+					return nil
+				}
+				pr, err := p.open(ctx, st)
+				if err != nil {
+					return err
+				}
+				if account != config.DefaultAccount {
+					pr = provider.WithAccount(pr, account)
+				}
+				opened = append(opened, pr)
+				return nil
+			})
+			if err != nil {
+				if account != config.DefaultAccount {
+					errs = append(errs, fmt.Errorf("%s/%s: %w", p.name, account, err))
+				} else {
+					errs = append(errs, fmt.Errorf("%s: %w", p.name, err))
+				}
+			}
+		}
+	}
+
+	if len(opened) == 0 {
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		return nil, errors.New("no providers configured (log in with `ordercli foodora login`, `ordercli glovo session` or `ordercli deliveroo session`)")
+	}
+	for _, err := range errs {
+		st.warnf("%v", err)
+	}
+	return opened, nil
+}
+
+// fetchTimeline queries all providers concurrently and merges the results newest first.
+func fetchTimeline(ctx context.Context, providers []provider.Provider, limit int) ([]provider.Order, []error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	results := make([][]provider.Order, len(providers))
+	errs := make([]error, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			orders, err := provider.FetchHistory(ctx, p, limit, 20)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", p.Name(), err)
+				return
+			}
+			results[i] = orders
+		}()
+	}
+	wg.Wait()
+
+	var merged []provider.Order
+	var failed []error
+	for i := range providers {
+		merged = append(merged, results[i]...)
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+		}
+	}
+	provider.SortNewestFirst(merged)
+	return merged, failed
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+)
+
+func TestTimelineCLI_MergesProviders(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+
+	foodoraSrv := newFoodoraTestServer(t)
+	defer foodoraSrv.Close()
+
+	glovoSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"orders":[{"orderId":7,"content":{"title":"Glovo Place"},"footer":{"left":{"type":"TEXT","data":"9,50 €"}},"layoutType":"INACTIVE_ORDER"}]}`))
+	}))
+	defer glovoSrv.Close()
+
+	cfg := config.New()
+	fd := cfg.Foodora()
+	fd.BaseURL = foodoraSrv.URL + "/"
+	fd.TargetCountryISO = "AT"
+	fd.AccessToken = "access"
+	fd.RefreshToken = "refresh"
+	fd.ExpiresAt = time.Now().Add(time.Hour)
+	gl := cfg.Glovo()
+	gl.BaseURL = glovoSrv.URL
+	gl.AccessToken = "glovo-token"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out, errOut, err := runCLI(cfgPath, []string{"history"}, "")
+	if err != nil {
+		t.Fatalf("history: %v err=%s", err, errOut)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", out)
+	}
+	if !strings.Contains(lines[0], "foodora\tHIST-1\tTest Vendor") || !strings.Contains(lines[0], "12.30 EUR") {
+		t.Fatalf("unexpected first line: %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "glovo\t7\tGlovo Place") || !strings.Contains(lines[1], "9.50 EUR") {
+		t.Fatalf("unexpected second line: %q", lines[1])
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"history", "--provider", "glovo", "--json"}, "")
+	if err != nil {
+		t.Fatalf("history json: %v", err)
+	}
+	if strings.Contains(out, "HIST-1") || !strings.Contains(out, `"provider": "glovo"`) {
+		t.Fatalf("unexpected json: %s", out)
+	}
+
+	out, _, err = runCLI(cfgPath, []string{"--output", "csv", "history", "--provider", "glovo"}, "")
+	if err != nil {
+		t.Fatalf("history csv: %v", err)
+	}
+	if want := "time,provider,order_id,vendor,status,total,currency\n,glovo,7,Glovo Place,INACTIVE_ORDER,9.5,EUR\n"; out != want {
+		t.Fatalf("unexpected csv:\n%s", out)
+	}
+}
+
+// Expired accounts refresh concurrently while the timeline fetches them; each
+// refresh must use its own account's tokens and client secret (run with -race).
+func TestTimelineCLI_RefreshesAccountsConcurrently(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+
+	foodoraServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/oauth2/token":
+				_ = r.ParseForm()
+				if r.FormValue("refresh_token") != name+"-refresh" || r.FormValue("client_secret") != name+"-secret" {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"access_token":"` + name + `-access2","refresh_token":"` + name + `-refresh2","expires_in":3600}`))
+			case "/orders/order_history":
+				if r.Header.Get("Authorization") != "Bearer "+name+"-access2" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write([]byte(`{"status":200,"data":{"total_count":1,"items":[{"order_code":"` + name + `-1","current_status":{"message":"delivered"},"vendor":{"code":"V","name":"Vendor"},"total_value":1}]}}`))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	}
+
+	cfg := config.New()
+	for _, name := range []string{config.DefaultAccount, "work"} {
+		srv := foodoraServer(name)
+		defer srv.Close()
+		fc := cfg.FoodoraAccount(name)
+		fc.BaseURL = srv.URL + "/"
+		fc.TargetCountryISO = "AT"
+		fc.OAuthClientID = "android"
+		fc.ClientSecret = name + "-secret"
+		fc.AccessToken = name + "-access"
+		fc.RefreshToken = name + "-refresh"
+		fc.ExpiresAt = time.Now().Add(-time.Hour)
+	}
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out, errOut, err := runCLI(cfgPath, []string{"history", "--provider", "foodora"}, "")
+	if err != nil {
+		t.Fatalf("history: %v err=%s", err, errOut)
+	}
+	if !strings.Contains(out, "default-1") || !strings.Contains(out, "work-1") {
+		t.Fatalf("unexpected history: %q (stderr %q)", out, errOut)
+	}
+
+	cfg, err = config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	for _, name := range []string{config.DefaultAccount, "work"} {
+		fc := cfg.FoodoraAccount(name)
+		if fc.AccessToken != name+"-access2" || fc.RefreshToken != name+"-refresh2" || !fc.ExpiresAt.After(time.Now()) {
+			t.Fatalf("%s: refreshed token not saved: %+v", name, fc)
+		}
+	}
+}
+
+func TestTimelineCLI_SkipsProvidersThatFailToOpen(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	srv := newFoodoraTestServer(t)
+	defer srv.Close()
+
+	cfg := config.New()
+	fd := cfg.Foodora()
+	fd.BaseURL = srv.URL + "/"
+	fd.TargetCountryISO = "AT"
+	fd.AccessToken = "access"
+	fd.RefreshToken = "refresh"
+	fd.ExpiresAt = time.Now().Add(time.Hour)
+	cfg.Glovo().AccessToken = "env:ORDERCLI_TEST_UNSET_GLOVO_TOKEN"
+	if err := config.Save(cfgPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out, errOut, err := runCLI(cfgPath, []string{"history"}, "")
+	if err != nil {
+		t.Fatalf("history: %v err=%s", err, errOut)
+	}
+	if !strings.Contains(out, "HIST-1") {
+		t.Fatalf("unexpected history: %q", out)
+	}
+	if !strings.Contains(errOut, "warning: glovo:") {
+		t.Fatalf("expected a warning for glovo, got %q", errOut)
+	}
+
+	_, _, err = runCLI(cfgPath, []string{"history", "--provider", "glovo"}, "")
+	if err == nil || !strings.Contains(err.Error(), "glovo:") {
+		t.Fatalf("expected glovo open error, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// DefaultAccount names the provider sections in Config.Providers.
+const DefaultAccount = "default"
+
+// ProviderNames are the providers that can have named accounts.
+var ProviderNames = []string{"foodora", "deliveroo", "glovo"}
+
+var accountNameRE = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+func validAccountName(name string) error {
+	if !accountNameRE.MatchString(name) {
+		return fmt.Errorf("invalid account name %q (use a-z, 0-9, '-' and '_')", name)
+	}
+	return nil
+}
+
+func validProvider(provider string) error {
+	for _, p := range ProviderNames {
+		if p == provider {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown provider %q", provider)
+}
+
+// account returns the provider sections of the named account, creating the
+// account if needed ("" and DefaultAccount are Config.Providers).
+func (c *Config) account(name string) *Providers {
+	if name == "" || name == DefaultAccount {
+		return &c.Providers
+	}
+	if c.Accounts == nil {
+		c.Accounts = map[string]*Providers{}
+	}
+	p := c.Accounts[name]
+	if p == nil {
+		p = &Providers{}
+		c.Accounts[name] = p
+	}
+	return p
+}
+
+// lookupAccount is account without creating it (nil when missing).
+func (c *Config) lookupAccount(name string) *Providers {
+	if name == "" || name == DefaultAccount {
+		return &c.Providers
+	}
+	return c.Accounts[name]
+}
+
+func (p *Providers) has(provider string) bool {
+	switch provider {
+	case "foodora":
+		return p.Foodora != nil
+	case "deliveroo":
+		return p.Deliveroo != nil
+	case "glovo":
+		return p.Glovo != nil
+	}
+	return false
+}
+
+// HasAccount reports whether the named account exists for provider. The
+// default account always exists.
+func (c *Config) HasAccount(provider, name string) bool {
+	if name == "" || name == DefaultAccount {
+		return true
+	}
+	p := c.Accounts[name]
+	return p != nil && p.has(provider)
+}
+
+// AccountNames lists provider's accounts: the default one first, then the
+// named ones sorted.
+func (c *Config) AccountNames(provider string) []string {
+	names := []string{}
+	for name, p := range c.Accounts {
+		if p != nil && p.has(provider) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultAccount}, names...)
+}
+
+// KnowsAccount reports whether any provider has the named account.
+func (c *Config) KnowsAccount(name string) bool {
+	for _, provider := range ProviderNames {
+		if c.HasAccount(provider, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddAccount creates an empty named account for provider.
+func (c *Config) AddAccount(provider, name string) error {
+	if err := validProvider(provider); err != nil {
+		return err
+	}
+	if name == DefaultAccount {
+		return fmt.Errorf("account %q always exists", DefaultAccount)
+	}
+	if err := validAccountName(name); err != nil {
+		return err
+	}
+	if c.HasAccount(provider, name) {
+		return fmt.Errorf("%s account %q already exists", provider, name)
+	}
+	switch provider {
+	case "foodora":
+		c.FoodoraAccount(name)
+	case "deliveroo":
+		c.DeliverooAccount(name)
+	case "glovo":
+		c.GlovoAccount(name)
+	}
+	return nil
+}
+
+// RemoveAccount deletes a named account of provider, including its tokens.
+// If it was the current account, the default one takes over.
+func (c *Config) RemoveAccount(provider, name string) error {
+	if err := validProvider(provider); err != nil {
+		return err
+	}
+	if name == DefaultAccount || name == "" {
+		return fmt.Errorf("the %s account can't be removed (use `logout` to clear it)", DefaultAccount)
+	}
+	if !c.HasAccount(provider, name) {
+		return fmt.Errorf("no %s account %q", provider, name)
+	}
+	p := c.Accounts[name]
+	switch provider {
+	case "foodora":
+		p.Foodora = nil
+	case "deliveroo":
+		p.Deliveroo = nil
+	case "glovo":
+		p.Glovo = nil
+	}
+	if p.Foodora == nil && p.Deliveroo == nil && p.Glovo == nil {
+		delete(c.Accounts, name)
+	}
+	if c.CurrentAccount[provider] == name {
+		delete(c.CurrentAccount, provider)
+	}
+	return nil
+}
+
+// UseAccount makes name the current account of provider.
+func (c *Config) UseAccount(provider, name string) error {
+	if err := validProvider(provider); err != nil {
+		return err
+	}
+	if !c.HasAccount(provider, name) {
+		return fmt.Errorf("no %s account %q (see `ordercli account list`)", provider, name)
+	}
+	if name == DefaultAccount {
+		delete(c.CurrentAccount, provider)
+		return nil
+	}
+	if c.CurrentAccount == nil {
+		c.CurrentAccount = map[string]string{}
+	}
+	c.CurrentAccount[provider] = name
+	return nil
+}
+
+// ActiveAccount is the account `account use` selected for provider.
+func (c *Config) ActiveAccount(provider string) string {
+	if name := c.CurrentAccount[provider]; name != "" && c.HasAccount(provider, name) {
+		return name
+	}
+	return DefaultAccount
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAccounts(t *testing.T) {
+	cfg := New()
+	if err := cfg.AddAccount("foodora", "work"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := cfg.AddAccount("foodora", "Bad Name"); err == nil {
+		t.Fatalf("expected invalid name error")
+	}
+	if err := cfg.AddAccount("foodora", DefaultAccount); err == nil {
+		t.Fatalf("expected default account error")
+	}
+	if err := cfg.AddAccount("glovo", "alt"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if got := strings.Join(cfg.AccountNames("foodora"), ","); got != "default,work" {
+		t.Fatalf("foodora accounts: %s", got)
+	}
+	if cfg.HasAccount("glovo", "work") || !cfg.KnowsAccount("work") {
+		t.Fatalf("accounts are per provider")
+	}
+	if err := cfg.UseAccount("foodora", "work"); err != nil {
+		t.Fatalf("use: %v", err)
+	}
+	if cfg.ActiveAccount("foodora") != "work" || cfg.ActiveAccount("glovo") != DefaultAccount {
+		t.Fatalf("unexpected active accounts: %#v", cfg.CurrentAccount)
+	}
+	if err := cfg.RemoveAccount("foodora", "work"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if cfg.ActiveAccount("foodora") != DefaultAccount || cfg.Accounts["work"] != nil {
+		t.Fatalf("expected work account gone")
+	}
+}
+
+func TestAccounts_EncryptedSecrets(t *testing.T) {
+	scryptN = 1 << 10
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	cfg := New()
+	cfg.FoodoraAccount("work").AccessToken = "work-secret"
+	if err := cfg.SetPassphrase([]byte("pw")); err != nil {
+		t.Fatalf("set passphrase: %v", err)
+	}
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	b, _ := os.ReadFile(path)
+	if strings.Contains(string(b), "work-secret") {
+		t.Fatalf("named account secret in plaintext:\n%s", b)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := got.Unlock([]byte("pw")); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if got.FoodoraAccount("work").AccessToken != "work-secret" {
+		t.Fatalf("unexpected token %q", got.FoodoraAccount("work").AccessToken)
+	}
+}
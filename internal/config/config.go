@@ -7,12 +7,27 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Version   int       `json:"version"`
+	Version int `json:"version"`
+	// Providers is the default account of every provider.
 	Providers Providers `json:"providers,omitempty"`
+	// Accounts are named accounts besides the default one; each holds only
+	// the providers it was added for (see accounts.go).
+	Accounts map[string]*Providers `json:"accounts,omitempty"`
+	// CurrentAccount is the account selected with `account use`, by provider.
+	CurrentAccount map[string]string `json:"current_account,omitempty"`
+	// AppProfiles are app builds imported with `foodora apk-import`, by name.
+	AppProfiles map[string]*AppProfile `json:"app_profiles,omitempty"`
+	Encryption  *Encryption            `json:"encryption,omitempty"`
+
+	// key decrypts secret fields once Unlock succeeded (see crypto.go).
+	key []byte
+	// loadedVersion is the version the file had before Upgrade.
+	loadedVersion int
 }
 
 type Providers struct {
@@ -34,27 +49,81 @@ type FoodoraConfig struct {
 
 	HTTPUserAgent string            `json:"http_user_agent,omitempty"`
 	CookiesByHost map[string]string `json:"cookies_by_host,omitempty"`
+	// CookiesSavedAt records when each CookiesByHost entry was captured.
+	CookiesSavedAt map[string]time.Time `json:"cookies_saved_at,omitempty"`
 
 	PendingMfaToken     string    `json:"pending_mfa_token,omitempty"`
 	PendingMfaChannel   string    `json:"pending_mfa_channel,omitempty"`
 	PendingMfaEmail     string    `json:"pending_mfa_email,omitempty"`
 	PendingMfaCreatedAt time.Time `json:"pending_mfa_created_at,omitempty"`
+
+	// AppProfile selects an entry of Config.AppProfiles for Firebase and
+	// app headers instead of the built-in HU/AT values.
+	AppProfile string `json:"app_profile,omitempty"`
+
+	Network Network `json:"network,omitzero"`
+}
+
+// AppProfile is what an imported Android app build identifies itself with.
+type AppProfile struct {
+	PackageName string `json:"package_name"`
+	VersionName string `json:"version_name,omitempty"`
+	VersionCode int64  `json:"version_code,omitempty"`
+
+	FirebaseAPIKey     string `json:"firebase_api_key"`
+	FirebaseProjectID  string `json:"firebase_project_id,omitempty"`
+	FirebaseProjectNum string `json:"firebase_project_number"`
+	FirebaseAppID      string `json:"firebase_app_id"`
+	CertSHA1           string `json:"cert_sha1"`
+
+	Source     string    `json:"source,omitempty"`
+	ImportedAt time.Time `json:"imported_at,omitempty"`
+}
+
+// UserAgent is the User-Agent the app sends to fd-api, e.g.
+// "Android-app-25.3.0(250300134)" ("" without a version).
+func (p AppProfile) UserAgent() string {
+	if p.VersionName == "" || p.VersionCode == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Android-app-%s(%d)", p.VersionName, p.VersionCode)
 }
 
 type DeliverooConfig struct {
 	Market  string `json:"market,omitempty"`
 	BaseURL string `json:"base_url,omitempty"`
+
+	BearerToken string `json:"bearer_token,omitempty"`
+	Cookie      string `json:"cookie,omitempty"`
+
+	Network Network `json:"network,omitzero"`
 }
 
 type GlovoConfig struct {
-	BaseURL     string  `json:"base_url,omitempty"`
-	AccessToken string  `json:"access_token,omitempty"`
-	DeviceURN   string  `json:"device_urn,omitempty"`
-	CityCode    string  `json:"city_code,omitempty"`
-	CountryCode string  `json:"country_code,omitempty"`
-	Language    string  `json:"language,omitempty"`
-	Latitude    float64 `json:"latitude,omitempty"`
-	Longitude   float64 `json:"longitude,omitempty"`
+	BaseURL      string    `json:"base_url,omitempty"`
+	AccessToken  string    `json:"access_token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+	DeviceURN    string    `json:"device_urn,omitempty"`
+	CityCode     string    `json:"city_code,omitempty"`
+	CountryCode  string    `json:"country_code,omitempty"`
+	Language     string    `json:"language,omitempty"`
+	Latitude     float64   `json:"latitude,omitempty"`
+	Longitude    float64   `json:"longitude,omitempty"`
+
+	Network Network `json:"network,omitzero"`
+}
+
+// Network is how a provider's client connects (`<provider> config set
+// --proxy ...`). The global flags of the same names override it.
+type Network struct {
+	// Proxy is an http://, https:// or socks5:// URL ("" = HTTP_PROXY etc.).
+	Proxy string `json:"proxy,omitempty"`
+	// CAFile is a PEM bundle trusted in addition to the system roots.
+	CAFile string `json:"ca_file,omitempty"`
+	// Timeout is a Go duration ("90s") bounding one call, retries included.
+	Timeout            string `json:"timeout,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
 }
 
 func DefaultPath() (string, error) {
@@ -81,7 +150,29 @@ func LegacyPathFoodoracli() (string, error) {
 	return filepath.Join(dir, "foodoracli", "config.json"), nil
 }
 
+// Load reads path under a shared lock (see Lock) and upgrades old formats in
+// memory. A missing file is a new, empty config. When the lock file can't be
+// created (read-only config dir) the file is read without the lock; Save
+// would fail there anyway.
 func Load(path string) (Config, error) {
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return New(), nil
+		}
+		return Config{}, err
+	}
+	unlock, err := Lock(path, false)
+	if err != nil {
+		if lockUnavailable(err) {
+			return load(path)
+		}
+		return Config{}, err
+	}
+	defer unlock()
+	return load(path)
+}
+
+func load(path string) (Config, error) {
 	var cfg Config
 
 	b, err := os.ReadFile(path)
@@ -92,47 +183,48 @@ func Load(path string) (Config, error) {
 		return cfg, err
 	}
 
-	var sniff struct {
-		Providers json.RawMessage `json:"providers"`
-	}
-	if err := json.Unmarshal(b, &sniff); err != nil {
+	b, from, err := Upgrade(b)
+	if err != nil {
 		return cfg, err
 	}
-
-	if len(sniff.Providers) > 0 {
-		if err := json.Unmarshal(b, &cfg); err != nil {
-			return cfg, err
-		}
-	} else {
-		var legacy FoodoraConfig
-		if err := json.Unmarshal(b, &legacy); err != nil {
-			return cfg, err
-		}
-		cfg = New()
-		cfg.Providers.Foodora = &legacy
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, err
 	}
+	cfg.loadedVersion = from
 
-	if cfg.Version == 0 {
-		cfg.Version = 1
-	}
-	if cfg.Providers.Foodora != nil && cfg.Providers.Foodora.DeviceID == "" {
-		cfg.Providers.Foodora.DeviceID = newDeviceID()
-	}
+	fillDeviceIDs(&cfg)
 	return cfg, nil
 }
 
+// LoadedVersion is the format version of the file Load read; it is below
+// CurrentVersion when the file was upgraded in memory and should be saved.
+func (c Config) LoadedVersion() int { return c.loadedVersion }
+
+// Save writes cfg under an exclusive lock, replacing the file. Use Update to
+// keep changes other processes made in the meantime.
 func Save(path string, cfg Config) error {
-	if cfg.Version == 0 {
-		cfg.Version = 1
+	unlock, err := Lock(path, true)
+	if err != nil {
+		return err
 	}
-	if cfg.Providers.Foodora != nil && cfg.Providers.Foodora.DeviceID == "" {
-		cfg.Providers.Foodora.DeviceID = newDeviceID()
+	defer unlock()
+	return save(path, cfg)
+}
+
+func save(path string, cfg Config) error {
+	if cfg.Version == 0 {
+		cfg.Version = CurrentVersion
 	}
+	fillDeviceIDs(&cfg)
 
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
 	}
 
+	cfg, err := sealed(cfg)
+	if err != nil {
+		return err
+	}
 	b, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return err
@@ -148,34 +240,127 @@ func Save(path string, cfg Config) error {
 
 func New() Config {
 	return Config{
-		Version: 1,
+		Version:       CurrentVersion,
+		loadedVersion: CurrentVersion,
 	}
 }
 
-func (c *Config) Foodora() *FoodoraConfig {
-	if c.Providers.Foodora == nil {
-		c.Providers.Foodora = &FoodoraConfig{}
+// fillDeviceIDs gives every foodora account its own device id.
+func fillDeviceIDs(cfg *Config) {
+	if cfg.Providers.Foodora != nil && cfg.Providers.Foodora.DeviceID == "" {
+		cfg.Providers.Foodora.DeviceID = newDeviceID()
+	}
+	for _, p := range cfg.Accounts {
+		if p != nil && p.Foodora != nil && p.Foodora.DeviceID == "" {
+			p.Foodora.DeviceID = newDeviceID()
+		}
 	}
-	if c.Providers.Foodora.DeviceID == "" {
-		c.Providers.Foodora.DeviceID = newDeviceID()
+}
+
+func (c *Config) Foodora() *FoodoraConfig { return c.FoodoraAccount(DefaultAccount) }
+
+func (c *Config) Deliveroo() *DeliverooConfig { return c.DeliverooAccount(DefaultAccount) }
+
+func (c *Config) Glovo() *GlovoConfig { return c.GlovoAccount(DefaultAccount) }
+
+func (c *Config) FoodoraAccount(name string) *FoodoraConfig {
+	p := c.account(name)
+	if p.Foodora == nil {
+		p.Foodora = &FoodoraConfig{}
+	}
+	if p.Foodora.DeviceID == "" {
+		p.Foodora.DeviceID = newDeviceID()
+	}
+	return p.Foodora
+}
+
+func (c *Config) DeliverooAccount(name string) *DeliverooConfig {
+	p := c.account(name)
+	if p.Deliveroo == nil {
+		p.Deliveroo = &DeliverooConfig{}
+	}
+	return p.Deliveroo
+}
+
+func (c *Config) GlovoAccount(name string) *GlovoConfig {
+	p := c.account(name)
+	if p.Glovo == nil {
+		p.Glovo = newGlovoConfig()
 	}
-	return c.Providers.Foodora
+	return p.Glovo
 }
 
-func (c *Config) Deliveroo() *DeliverooConfig {
-	if c.Providers.Deliveroo == nil {
-		c.Providers.Deliveroo = &DeliverooConfig{}
+func newGlovoConfig() *GlovoConfig {
+	return &GlovoConfig{
+		BaseURL: "https://api.glovoapp.com",
 	}
-	return c.Providers.Deliveroo
 }
 
-func (c *Config) Glovo() *GlovoConfig {
-	if c.Providers.Glovo == nil {
-		c.Providers.Glovo = &GlovoConfig{
-			BaseURL: "https://api.glovoapp.com",
+// LookupFoodoraAccount is FoodoraAccount for reading: a missing account or
+// section reads as an empty config that is not added to c.
+func (c *Config) LookupFoodoraAccount(name string) *FoodoraConfig {
+	if p := c.lookupAccount(name); p != nil && p.Foodora != nil {
+		return p.Foodora
+	}
+	return &FoodoraConfig{}
+}
+
+// LookupDeliverooAccount is DeliverooAccount for reading (see
+// LookupFoodoraAccount).
+func (c *Config) LookupDeliverooAccount(name string) *DeliverooConfig {
+	if p := c.lookupAccount(name); p != nil && p.Deliveroo != nil {
+		return p.Deliveroo
+	}
+	return &DeliverooConfig{}
+}
+
+// LookupGlovoAccount is GlovoAccount for reading (see LookupFoodoraAccount).
+func (c *Config) LookupGlovoAccount(name string) *GlovoConfig {
+	if p := c.lookupAccount(name); p != nil && p.Glovo != nil {
+		return p.Glovo
+	}
+	return newGlovoConfig()
+}
+
+func (c DeliverooConfig) HasSession() bool { return c.BearerToken != "" }
+
+// TokenExpiresAt decodes the bearer token's JWT expiry.
+func (c DeliverooConfig) TokenExpiresAt() (time.Time, bool) { return jwtExpiry(c.BearerToken) }
+
+// HasSession reports whether a token is stored; a refresh token alone is
+// enough to get a new access token.
+func (c GlovoConfig) HasSession() bool { return c.AccessToken != "" || c.RefreshToken != "" }
+
+// CanRefresh reports whether newGlovoClient can renew the access token.
+func (c GlovoConfig) CanRefresh() bool { return c.RefreshToken != "" }
+
+// TokenLikelyExpired mirrors FoodoraConfig.TokenLikelyExpired: ExpiresAt
+// first, else the JWT expiry, else assume the token still works.
+func (c GlovoConfig) TokenLikelyExpired(now time.Time) bool {
+	if c.AccessToken == "" {
+		return true
+	}
+	exp := c.ExpiresAt
+	if exp.IsZero() {
+		var ok bool
+		if exp, ok = jwtExpiry(c.AccessToken); !ok {
+			return false
 		}
 	}
-	return c.Providers.Glovo
+	return !exp.After(now.Add(30 * time.Second))
+}
+
+// SetCookies stores the cookie header for host and when it was captured.
+func (c *FoodoraConfig) SetCookies(host, header string, now time.Time) {
+	host = strings.ToLower(host)
+	if c.CookiesByHost == nil {
+		c.CookiesByHost = map[string]string{}
+	}
+	if c.CookiesSavedAt == nil {
+		c.CookiesSavedAt = map[string]time.Time{}
+	}
+	c.CookiesByHost[host] = header
+	c.CookiesSavedAt[host] = now.UTC()
 }
 
 func (c FoodoraConfig) HasSession() bool {
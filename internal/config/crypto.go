@@ -0,0 +1,291 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted values are stored as encPrefix + base64(nonce || AES-256-GCM
+// ciphertext). The field name is the additional data, so a value can't be
+// moved to another field.
+const encPrefix = "enc:v1:"
+
+const kdfScrypt = "scrypt"
+
+// checkPlaintext is sealed into Encryption.Check to tell a wrong passphrase
+// apart from corrupt values.
+const checkPlaintext = "ordercli"
+
+// scryptN is the scrypt cost for new passphrases (tests lower it).
+var scryptN = 1 << 15
+
+var (
+	ErrLocked        = errors.New("config is encrypted; passphrase required")
+	ErrBadPassphrase = errors.New("wrong config passphrase")
+)
+
+// Encryption describes how secret fields are encrypted. Only the key
+// derivation parameters are stored, never the key.
+type Encryption struct {
+	KDF   string `json:"kdf"`
+	Salt  string `json:"salt"`
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	Check string `json:"check"`
+}
+
+// Encrypted reports whether secret fields are stored encrypted.
+func (c *Config) Encrypted() bool { return c.Encryption != nil }
+
+// Unlock derives the key from passphrase and decrypts all secret fields in
+// place. The key is kept so Save encrypts them again.
+func (c *Config) Unlock(passphrase []byte) error {
+	if c.Encryption == nil {
+		return nil
+	}
+	key, err := deriveKey(*c.Encryption, passphrase)
+	if err != nil {
+		return err
+	}
+	return c.unlockWith(key)
+}
+
+func (c *Config) unlockWith(key []byte) error {
+	if got, err := openValue(key, "check", c.Encryption.Check); err != nil || got != checkPlaintext {
+		return ErrBadPassphrase
+	}
+	if err := eachSecret(c, func(name, v string) (string, error) {
+		if !strings.HasPrefix(v, encPrefix) {
+			return v, nil // hand-edited plaintext; encrypted on next save
+		}
+		return openValue(key, name, v)
+	}); err != nil {
+		return err
+	}
+	c.key = key
+	return nil
+}
+
+// SetPassphrase turns on encryption, or changes the passphrase of an unlocked
+// config. A fresh salt is used every time.
+func (c *Config) SetPassphrase(passphrase []byte) error {
+	if len(passphrase) == 0 {
+		return errors.New("empty passphrase")
+	}
+	if c.Encryption != nil && c.key == nil {
+		return ErrLocked
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	enc := Encryption{KDF: kdfScrypt, Salt: base64.StdEncoding.EncodeToString(salt), N: scryptN, R: 8, P: 1}
+	key, err := deriveKey(enc, passphrase)
+	if err != nil {
+		return err
+	}
+	if enc.Check, err = sealValue(key, "check", checkPlaintext); err != nil {
+		return err
+	}
+	c.Encryption = &enc
+	c.key = key
+	return nil
+}
+
+// RemovePassphrase turns encryption off; the next Save writes plaintext.
+func (c *Config) RemovePassphrase() error {
+	if c.Encryption != nil && c.key == nil {
+		return ErrLocked
+	}
+	c.Encryption = nil
+	c.key = nil
+	return nil
+}
+
+// sealed returns a copy of cfg with secret fields encrypted. cfg itself (and
+// the provider structs it points to) is left untouched.
+func sealed(cfg Config) (Config, error) {
+	if cfg.Encryption == nil {
+		return cfg, nil
+	}
+	cfg.Providers = cloneProviders(cfg.Providers)
+	if cfg.Accounts != nil {
+		accounts := make(map[string]*Providers, len(cfg.Accounts))
+		for name, p := range cfg.Accounts {
+			if p != nil {
+				cp := cloneProviders(*p)
+				p = &cp
+			}
+			accounts[name] = p
+		}
+		cfg.Accounts = accounts
+	}
+	key := cfg.key
+	err := eachSecret(&cfg, func(name, v string) (string, error) {
+		if strings.HasPrefix(v, encPrefix) {
+			return v, nil
+		}
+		if key == nil {
+			return "", ErrLocked
+		}
+		return sealValue(key, name, v)
+	})
+	return cfg, err
+}
+
+// cloneProviders copies the provider sections that hold secrets.
+func cloneProviders(p Providers) Providers {
+	if f := p.Foodora; f != nil {
+		cp := *f
+		cp.CookiesByHost = maps.Clone(f.CookiesByHost)
+		p.Foodora = &cp
+	}
+	if d := p.Deliveroo; d != nil {
+		cp := *d
+		p.Deliveroo = &cp
+	}
+	if g := p.Glovo; g != nil {
+		cp := *g
+		p.Glovo = &cp
+	}
+	return p
+}
+
+// eachSecret replaces every non-empty secret field of every account with
+// fn's result. Names of named-account fields start with "accounts.<name>.".
+func eachSecret(c *Config, fn func(name, v string) (string, error)) error {
+	if err := eachProviderSecret(&c.Providers, "", fn); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(c.Accounts))
+	for name := range c.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if p := c.Accounts[name]; p != nil {
+			if err := eachProviderSecret(p, "accounts."+name+".", fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func eachProviderSecret(p *Providers, prefix string, fn func(name, v string) (string, error)) error {
+	type field struct {
+		name string
+		v    *string
+	}
+	var fields []field
+	add := func(name string, v *string) { fields = append(fields, field{prefix + name, v}) }
+	if f := p.Foodora; f != nil {
+		add("foodora.access_token", &f.AccessToken)
+		add("foodora.refresh_token", &f.RefreshToken)
+		add("foodora.client_secret", &f.ClientSecret)
+		add("foodora.pending_mfa_token", &f.PendingMfaToken)
+		add("foodora.network.proxy", &f.Network.Proxy)
+	}
+	if d := p.Deliveroo; d != nil {
+		add("deliveroo.bearer_token", &d.BearerToken)
+		add("deliveroo.cookie", &d.Cookie)
+		add("deliveroo.network.proxy", &d.Network.Proxy)
+	}
+	if g := p.Glovo; g != nil {
+		add("glovo.access_token", &g.AccessToken)
+		add("glovo.refresh_token", &g.RefreshToken)
+		add("glovo.network.proxy", &g.Network.Proxy)
+	}
+	for _, f := range fields {
+		if *f.v == "" {
+			continue
+		}
+		v, err := fn(f.name, *f.v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		*f.v = v
+	}
+
+	if f := p.Foodora; f != nil && len(f.CookiesByHost) > 0 {
+		hosts := make([]string, 0, len(f.CookiesByHost))
+		for h := range f.CookiesByHost {
+			hosts = append(hosts, h)
+		}
+		sort.Strings(hosts)
+		for _, h := range hosts {
+			if f.CookiesByHost[h] == "" {
+				continue
+			}
+			name := prefix + "foodora.cookies_by_host." + h
+			v, err := fn(name, f.CookiesByHost[h])
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			f.CookiesByHost[h] = v
+		}
+	}
+	return nil
+}
+
+func deriveKey(enc Encryption, passphrase []byte) ([]byte, error) {
+	if enc.KDF != kdfScrypt {
+		return nil, fmt.Errorf("unsupported config kdf %q", enc.KDF)
+	}
+	salt, err := base64.StdEncoding.DecodeString(enc.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("config encryption salt: %w", err)
+	}
+	return scrypt.Key(passphrase, salt, enc.N, enc.R, enc.P, 32)
+}
+
+func sealValue(key []byte, name, plaintext string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	out := aead.Seal(nonce, nonce, []byte(plaintext), []byte(name))
+	return encPrefix + base64.StdEncoding.EncodeToString(out), nil
+}
+
+func openValue(key []byte, name, value string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encPrefix))
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < aead.NonceSize() {
+		return "", errors.New("encrypted value too short")
+	}
+	nonce, ct := raw[:aead.NonceSize()], raw[aead.NonceSize():]
+	pt, err := aead.Open(nil, nonce, ct, []byte(name))
+	if err != nil {
+		return "", errors.New("cannot decrypt value")
+	}
+	return string(pt), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockTimeout bounds how long Load/Save wait for another ordercli process.
+var lockTimeout = 10 * time.Second
+
+var errWouldBlock = errors.New("lock held")
+
+// openLockFile is replaced in tests.
+var openLockFile = os.OpenFile
+
+// lockUnavailable reports whether err means the lock file can't be created,
+// as in a read-only config dir; readers then go without the lock.
+func lockUnavailable(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || readOnlyFS(err)
+}
+
+// Lock takes an advisory lock on path+".lock" (a separate file, since Save
+// replaces path by rename). Writers lock exclusively, readers shared. The
+// returned func releases the lock. Other files that are rewritten by
+// rename, like the order archive index, use it too.
+func Lock(path string, exclusive bool) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := openLockFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := lockFile(f, exclusive)
+		if err == nil {
+			return func() {
+				_ = unlockFile(f)
+				_ = f.Close()
+			}, nil
+		}
+		if !errors.Is(err, errWouldBlock) || time.Now().After(deadline) {
+			_ = f.Close()
+			if errors.Is(err, errWouldBlock) {
+				return nil, errors.New("locked by another ordercli process: " + path)
+			}
+			return nil, err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
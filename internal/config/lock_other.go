@@ -0,0 +1,12 @@
+//go:build !unix && !windows
+
+package config
+
+import "os"
+
+// No advisory locks on this platform; Save stays atomic via rename.
+func lockFile(*os.File, bool) error { return nil }
+
+func unlockFile(*os.File) error { return nil }
+
+func readOnlyFS(error) bool { return false }
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Clone returns a deep copy of c, including its unlock key.
+func (c Config) Clone() Config {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return c
+	}
+	var out Config
+	if err := json.Unmarshal(b, &out); err != nil {
+		return c
+	}
+	out.key = c.key
+	out.loadedVersion = c.loadedVersion
+	return out
+}
+
+// Update writes the changes from base to cur onto the current file, under an
+// exclusive lock. Fields this process didn't touch keep whatever another
+// process wrote since base was loaded. It returns the merged config (still
+// unlocked if cur was); without changes it only re-reads the file.
+func Update(path string, base, cur Config) (Config, error) {
+	unlock, err := Lock(path, true)
+	if err != nil {
+		return cur, err
+	}
+	defer unlock()
+
+	disk, err := load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cur, save(path, cur)
+	}
+	if err != nil {
+		return cur, err
+	}
+	if disk.Encryption != nil {
+		key := keyFor(disk.Encryption, base, cur)
+		if key == nil {
+			return cur, errors.New("config encryption was changed by another process; rerun the command")
+		}
+		if err := disk.unlockWith(key); err != nil {
+			return cur, err
+		}
+	}
+
+	merged, changed, err := mergeConfigs(base, cur, disk)
+	if err != nil {
+		return cur, err
+	}
+	if merged.Encryption != nil {
+		merged.key = keyFor(merged.Encryption, cur, base, disk)
+	}
+	merged.loadedVersion = CurrentVersion
+	if changed || disk.loadedVersion < CurrentVersion {
+		if err := save(path, merged); err != nil {
+			return cur, err
+		}
+	}
+	return merged, nil
+}
+
+// keyFor returns the key of the first candidate encrypted the same way as enc.
+func keyFor(enc *Encryption, candidates ...Config) []byte {
+	for _, c := range candidates {
+		if c.key != nil && c.Encryption != nil && *c.Encryption == *enc {
+			return c.key
+		}
+	}
+	return nil
+}
+
+// mergeConfigs applies every JSON leaf that differs between base and cur to
+// disk. Removed leaves are removed; objects left empty are dropped.
+func mergeConfigs(base, cur, disk Config) (Config, bool, error) {
+	var b, c, d map[string]any
+	for _, x := range []struct {
+		cfg Config
+		out *map[string]any
+	}{{base, &b}, {cur, &c}, {disk, &d}} {
+		m, err := toDoc(x.cfg)
+		if err != nil {
+			return Config{}, false, err
+		}
+		*x.out = m
+	}
+
+	bl, cl := map[string]any{}, map[string]any{}
+	flatten(nil, b, bl)
+	flatten(nil, c, cl)
+	changed := false
+	for k, v := range cl {
+		if old, ok := bl[k]; !ok || !reflect.DeepEqual(old, v) {
+			setPath(d, strings.Split(k, "\x00"), v)
+			changed = true
+		}
+	}
+	for k := range bl {
+		if _, ok := cl[k]; !ok {
+			deletePath(d, strings.Split(k, "\x00"))
+			changed = true
+		}
+	}
+
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return Config{}, false, err
+	}
+	var out Config
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return Config{}, false, err
+	}
+	return out, changed, nil
+}
+
+func toDoc(cfg Config) (map[string]any, error) {
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// flatten collects the non-object values of v by their key path (joined with
+// NUL, which JSON keys here never contain).
+func flatten(path []string, v any, out map[string]any) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		out[strings.Join(path, "\x00")] = v
+		return
+	}
+	for k, child := range m {
+		flatten(append(path[:len(path):len(path)], k), child, out)
+	}
+}
+
+func setPath(m map[string]any, path []string, v any) {
+	for _, k := range path[:len(path)-1] {
+		next, ok := m[k].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[k] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = v
+}
+
+// deletePath removes the leaf at path and prunes parents left empty.
+func deletePath(m map[string]any, path []string) {
+	if len(path) == 1 {
+		delete(m, path[0])
+		return
+	}
+	next, ok := m[path[0]].(map[string]any)
+	if !ok {
+		return
+	}
+	deletePath(next, path[1:])
+	if len(next) == 0 {
+		delete(m, path[0])
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestUpdate_MergesConcurrentChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := New()
+	cfg.Foodora().BaseURL = "https://at.fd-api.com/api/v5/"
+	cfg.Foodora().AccessToken = "old"
+	cfg.Foodora().CookiesByHost = map[string]string{"a.example": "a=1"}
+	if err := cfg.AddAccount("glovo", "work"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	load := func() (Config, Config) {
+		c, err := Load(path)
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		return c.Clone(), c
+	}
+	watchBase, watch := load()
+	loginBase, login := load()
+
+	// "login" refreshes the foodora session and adds a cookie.
+	login.Foodora().AccessToken = "new"
+	login.Foodora().RefreshToken = "refresh"
+	login.Foodora().CookiesByHost["b.example"] = "b=2"
+	if _, err := Update(path, loginBase, login); err != nil {
+		t.Fatalf("update login: %v", err)
+	}
+
+	// "watch" exits later with an unrelated change and a removed account.
+	watch.Glovo().Language = "de"
+	if err := watch.RemoveAccount("glovo", "work"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	merged, err := Update(path, watchBase, watch)
+	if err != nil {
+		t.Fatalf("update watch: %v", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	f := got.Foodora()
+	if f.AccessToken != "new" || f.RefreshToken != "refresh" {
+		t.Fatalf("refreshed tokens lost: %#v", f)
+	}
+	if f.CookiesByHost["a.example"] != "a=1" || f.CookiesByHost["b.example"] != "b=2" {
+		t.Fatalf("cookies not merged: %#v", f.CookiesByHost)
+	}
+	if got.Glovo().Language != "de" || got.Accounts != nil {
+		t.Fatalf("watch changes lost: %#v %#v", got.Glovo(), got.Accounts)
+	}
+	if merged.Foodora().AccessToken != "new" {
+		t.Fatalf("merged config should include other writers' changes")
+	}
+}
+
+func TestUpdate_Encrypted(t *testing.T) {
+	scryptN = 1 << 10
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := New()
+	cfg.Foodora().AccessToken = "old"
+	if err := cfg.SetPassphrase([]byte("pw")); err != nil {
+		t.Fatalf("set passphrase: %v", err)
+	}
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	load := func() (Config, Config) {
+		c, err := Load(path)
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		if err := c.Unlock([]byte("pw")); err != nil {
+			t.Fatalf("unlock: %v", err)
+		}
+		return c.Clone(), c
+	}
+	aBase, a := load()
+	bBase, b := load()
+
+	a.Foodora().AccessToken = "new"
+	if _, err := Update(path, aBase, a); err != nil {
+		t.Fatalf("update a: %v", err)
+	}
+	b.Foodora().BaseURL = "https://hu.fd-api.com/api/v5/"
+	if _, err := Update(path, bBase, b); err != nil {
+		t.Fatalf("update b: %v", err)
+	}
+
+	_, got := load()
+	if got.Foodora().AccessToken != "new" || got.Foodora().BaseURL != "https://hu.fd-api.com/api/v5/" {
+		t.Fatalf("unexpected merge: %#v", got.Foodora())
+	}
+}
+
+func TestUpdate_NoChangesOnlyRereads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := New()
+	cfg.Glovo().Language = "en"
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	mine, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	other := mine.Clone()
+	other.Glovo().Language = "hu"
+	if err := Save(path, other); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, err := Update(path, mine.Clone(), mine)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got.Glovo().Language != "hu" {
+		t.Fatalf("expected other process' change, got %q", got.Glovo().Language)
+	}
+}
+
+func TestLock_Exclusive(t *testing.T) {
+	old := lockTimeout
+	lockTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { lockTimeout = old })
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	unlock, err := Lock(path, true)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Fatalf("missing files need no lock: %v", err)
+	}
+	if err := Save(path, New()); err == nil || !strings.Contains(err.Error(), "locked by another ordercli process") {
+		t.Fatalf("expected lock error, got %v", err)
+	}
+	unlock()
+	if err := Save(path, New()); err != nil {
+		t.Fatalf("save after unlock: %v", err)
+	}
+}
+
+func TestLoad_ReadOnlyDirSkipsLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := New()
+	cfg.Foodora().AccessToken = "tok"
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := os.Remove(path + ".lock"); err != nil {
+		t.Fatalf("remove lock: %v", err)
+	}
+
+	old := openLockFile
+	t.Cleanup(func() { openLockFile = old })
+	for _, errno := range []error{syscall.EROFS, syscall.EACCES} {
+		openLockFile = func(name string, _ int, _ os.FileMode) (*os.File, error) {
+			return nil, &os.PathError{Op: "open", Path: name, Err: errno}
+		}
+		got, err := Load(path)
+		if err != nil || got.Foodora().AccessToken != "tok" {
+			t.Fatalf("%v: load=%v token=%q", errno, err, got.Foodora().AccessToken)
+		}
+		if err := Save(path, cfg); err == nil {
+			t.Fatalf("%v: save without lock should fail", errno)
+		}
+	}
+}
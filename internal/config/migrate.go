@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// CurrentVersion is the config format this build writes. Bumping it needs a
+// new entry in migrations.
+const CurrentVersion = 1
+
+// migration upgrades a decoded config document by one version. Migrations
+// work on raw JSON so they don't depend on today's Go structs.
+type migration struct {
+	desc  string
+	apply func(doc map[string]any) error
+}
+
+// migrations[i] upgrades version i to i+1.
+var migrations = []migration{
+	{desc: "move flat foodora settings under providers.foodora", apply: migrateFlatFoodora},
+}
+
+// Upgrade runs the migrations from the document's version up to
+// CurrentVersion. It returns the (re-indented) document and the version it
+// started at. Files without "version" are 0 when flat, 1 otherwise.
+func Upgrade(b []byte) ([]byte, int, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, 0, err
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	from, err := docVersion(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if from > CurrentVersion {
+		return nil, from, fmt.Errorf("config version %d is newer than this ordercli supports (%d); please upgrade ordercli", from, CurrentVersion)
+	}
+	for v := from; v < CurrentVersion; v++ {
+		if err := migrations[v].apply(doc); err != nil {
+			return nil, from, fmt.Errorf("config migration %d->%d (%s): %w", v, v+1, migrations[v].desc, err)
+		}
+		doc["version"] = v + 1
+	}
+	if from == CurrentVersion {
+		doc["version"] = CurrentVersion
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, from, err
+	}
+	return append(out, '\n'), from, nil
+}
+
+// Pending lists the migrations Upgrade would run from version from.
+func Pending(from int) []string {
+	var out []string
+	for v := from; v < CurrentVersion && v < len(migrations); v++ {
+		out = append(out, fmt.Sprintf("%d->%d: %s", v, v+1, migrations[v].desc))
+	}
+	return out
+}
+
+func docVersion(doc map[string]any) (int, error) {
+	raw, ok := doc["version"]
+	if !ok || raw == nil {
+		if _, ok := doc["providers"]; ok {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	n, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("config version is not a number: %v", raw)
+	}
+	v, err := n.Int64()
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid config version %s", n)
+	}
+	if v == 0 {
+		// Written by early builds that left Version unset.
+		if _, ok := doc["providers"]; ok {
+			return 1, nil
+		}
+	}
+	return int(v), nil
+}
+
+// migrateFlatFoodora handles foodcli/foodoracli configs, which were a bare
+// FoodoraConfig object.
+func migrateFlatFoodora(doc map[string]any) error {
+	if _, ok := doc["providers"]; ok {
+		return nil
+	}
+	foodora := map[string]any{}
+	for k, v := range doc {
+		if k == "version" {
+			continue
+		}
+		foodora[k] = v
+		delete(doc, k)
+	}
+	if len(foodora) > 0 {
+		doc["providers"] = map[string]any{"foodora": foodora}
+	}
+	return nil
+}
+
+// Backup copies path to "<path>.bak-<timestamp>" (mode 0600) and returns
+// the copy's path.
+func Backup(path string, now time.Time) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	base := path + ".bak-" + now.Format("20060102-150405")
+	dst := base
+	for i := 2; ; i++ {
+		f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			dst = fmt.Sprintf("%s-%d", base, i)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(b); err != nil {
+			_ = f.Close()
+			return "", err
+		}
+		return dst, f.Close()
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMigrationsCoverCurrentVersion(t *testing.T) {
+	if len(migrations) != CurrentVersion {
+		t.Fatalf("%d migrations for CurrentVersion %d", len(migrations), CurrentVersion)
+	}
+}
+
+func TestUpgrade_FlatLegacy(t *testing.T) {
+	out, from, err := Upgrade([]byte(`{"base_url":"https://hu.fd-api.com/api/v5/","access_token":"a","device_id":"d"}`))
+	if err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	if from != 0 {
+		t.Fatalf("expected version 0, got %d", from)
+	}
+	var cfg Config
+	if err := json.Unmarshal(out, &cfg); err != nil {
+		t.Fatalf("decode: %v\n%s", err, out)
+	}
+	if cfg.Version != CurrentVersion || cfg.Providers.Foodora == nil || cfg.Providers.Foodora.AccessToken != "a" {
+		t.Fatalf("unexpected upgrade:\n%s", out)
+	}
+	if got := Pending(from); len(got) != 1 || !strings.HasPrefix(got[0], "0->1: ") {
+		t.Fatalf("unexpected pending: %v", got)
+	}
+}
+
+func TestUpgrade_VersionDetection(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want int
+	}{
+		{`{"providers":{}}`, 1},
+		{`{"version":0,"providers":{}}`, 1},
+		{`{"version":1,"providers":{"glovo":{"latitude":47.5}}}`, 1},
+		{`{}`, 0},
+	} {
+		out, from, err := Upgrade([]byte(tc.in))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.in, err)
+		}
+		if from != tc.want {
+			t.Fatalf("%s: got version %d, want %d", tc.in, from, tc.want)
+		}
+		if tc.want == 1 && strings.Contains(tc.in, "47.5") && !strings.Contains(string(out), "47.5") {
+			t.Fatalf("numbers must survive: %s", out)
+		}
+	}
+	if _, _, err := Upgrade([]byte(`{"version":99}`)); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected newer-version error, got %v", err)
+	}
+}
+
+func TestLoad_RecordsLoadedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"access_token":"a"}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.LoadedVersion() != 0 || cfg.Version != CurrentVersion {
+		t.Fatalf("loaded=%d version=%d", cfg.LoadedVersion(), cfg.Version)
+	}
+	if New().LoadedVersion() != CurrentVersion {
+		t.Fatalf("new configs need no upgrade")
+	}
+}
+
+func TestBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	first, err := Backup(path, now)
+	if err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	second, err := Backup(path, now)
+	if err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if first != path+".bak-20260102-030405" || second != first+"-2" {
+		t.Fatalf("unexpected backup names: %s %s", first, second)
+	}
+	if b, _ := os.ReadFile(second); string(b) != "old" {
+		t.Fatalf("unexpected backup content %q", b)
+	}
+}
@@ -7,14 +7,16 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
+
+	"github.com/steipete/ordercli/internal/auth"
+	"github.com/steipete/ordercli/internal/httpx"
 )
 
 type Client struct {
 	http        *http.Client
 	market      string
 	consumerURL string
-	bearerToken string
+	tokens      auth.Source
 	cookie      string
 }
 
@@ -22,16 +24,21 @@ type ClientOptions struct {
 	BaseURL     string
 	Market      string
 	BearerToken string
+	// TokenSource overrides BearerToken; a refreshing source renews the
+	// token after a 401 and retries once.
+	TokenSource auth.Source
 	Cookie      string
-	Timeout     time.Duration
+	// HTTP tunes timeouts, retries and pacing (see httpx.Options).
+	HTTP httpx.Options
 }
 
 func NewClient(opts ClientOptions) (*Client, error) {
-	if strings.TrimSpace(opts.BearerToken) == "" {
-		return nil, errors.New("missing bearer token")
-	}
-	if opts.Timeout == 0 {
-		opts.Timeout = 20 * time.Second
+	tokens := opts.TokenSource
+	if tokens == nil {
+		if strings.TrimSpace(opts.BearerToken) == "" {
+			return nil, errors.New("missing bearer token")
+		}
+		tokens = auth.Static(strings.TrimSpace(opts.BearerToken))
 	}
 	base := strings.TrimSpace(opts.BaseURL)
 	if base == "" {
@@ -48,12 +55,10 @@ func NewClient(opts ClientOptions) (*Client, error) {
 	}
 
 	return &Client{
-		http: &http.Client{
-			Timeout: opts.Timeout,
-		},
+		http:        httpx.NewClient(opts.HTTP),
 		market:      strings.TrimSpace(opts.Market),
 		consumerURL: consumer,
-		bearerToken: strings.TrimSpace(opts.BearerToken),
+		tokens:      tokens,
 		cookie:      strings.TrimSpace(opts.Cookie),
 	}, nil
 }
@@ -70,11 +75,6 @@ func (c *Client) OrderHistory(ctx context.Context, p OrderHistoryParams) (Orders
 	}
 	req.Header.Set("Accept", "application/json")
 
-	auth := c.bearerToken
-	if !strings.HasPrefix(strings.ToLower(auth), "bearer ") {
-		auth = "Bearer " + auth
-	}
-	req.Header.Set("Authorization", auth)
 	if c.cookie != "" {
 		req.Header.Set("Cookie", c.cookie)
 	}
@@ -82,10 +82,22 @@ func (c *Client) OrderHistory(ctx context.Context, p OrderHistoryParams) (Orders
 		req.Header.Set("X-Deliveroo-Market", c.market)
 	}
 
-	resp, err := c.http.Do(req)
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return OrdersResponse{}, fmt.Errorf("deliveroo: access token: %w", err)
+	}
+	resp, err := c.send(req, token)
 	if err != nil {
 		return OrdersResponse{}, err
 	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		if fresh, rerr := c.tokens.Refresh(ctx, token); rerr == nil && fresh != token {
+			resp.Body.Close()
+			if resp, err = c.send(req.Clone(ctx), fresh); err != nil {
+				return OrdersResponse{}, err
+			}
+		}
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
@@ -101,3 +113,11 @@ func (c *Client) OrderHistory(ctx context.Context, p OrderHistoryParams) (Orders
 	}
 	return out, nil
 }
+
+func (c *Client) send(req *http.Request, token string) (*http.Response, error) {
+	if !strings.HasPrefix(strings.ToLower(token), "bearer ") {
+		token = "Bearer " + token
+	}
+	req.Header.Set("Authorization", token)
+	return c.http.Do(req)
+}
@@ -0,0 +1,316 @@
+// Package drift compares API responses with the Go models that decode them,
+// so added, renamed or dropped fields show up before a model goes stale.
+//
+// Layout (inside the drift dir):
+//
+//	observed.json  one Aggregate per endpoint/model, merged on every response
+//	baseline.json  the fields seen per endpoint as of the last Report commit
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// JSON value kinds as recorded in Observation.Fields.
+const (
+	KindString = "string"
+	KindNumber = "number"
+	KindBool   = "bool"
+	KindObject = "object"
+	KindArray  = "array"
+	KindNull   = "null"
+)
+
+// Observation is the shape of one response next to the model it decoded into.
+// Paths are dotted JSON paths with "[]" for array elements, e.g.
+// "data.items[].order_code".
+type Observation struct {
+	Endpoint string    `json:"endpoint"`
+	Model    string    `json:"model"`
+	At       time.Time `json:"at"`
+	// Fields maps every path in the response to the kinds seen there.
+	Fields map[string][]string `json:"fields"`
+	// Unknown are paths the model does not declare.
+	Unknown []string `json:"unknown,omitempty"`
+	// Missing are model fields absent from every object they belong to.
+	Missing []string `json:"missing,omitempty"`
+	// Mismatched are fields whose JSON kind differs from the model's, such as
+	// numbers sent as strings and absorbed by a FlexibleInt.
+	Mismatched []Mismatch `json:"mismatched,omitempty"`
+}
+
+// Mismatch is a field decoded from a different JSON kind than its Go type.
+type Mismatch struct {
+	Path  string `json:"path"`
+	Model string `json:"model"`
+	JSON  string `json:"json"`
+}
+
+// Key identifies what an Observation is compared with across runs.
+func (o Observation) Key() string { return o.Endpoint + " " + o.Model }
+
+// Observe walks body alongside model (a value or pointer of the type it was
+// decoded into).
+func Observe(endpoint string, body []byte, model any, now time.Time) (Observation, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return Observation{}, err
+	}
+	if dec.More() {
+		return Observation{}, errors.New("drift: trailing data after JSON value")
+	}
+
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	w := walker{fields: map[string]map[string]bool{}, declared: map[string]bool{}, unknown: map[string]bool{}, mismatched: map[string]Mismatch{}}
+	w.walk("", v, t)
+
+	o := Observation{Endpoint: endpoint, At: now.UTC(), Fields: map[string][]string{}}
+	if t != nil {
+		o.Model = t.String()
+	}
+	for p, kinds := range w.fields {
+		o.Fields[p] = sortedKeys(kinds)
+	}
+	o.Unknown = sortedKeys(w.unknown)
+	for p := range w.declared {
+		if _, ok := w.fields[p]; !ok {
+			o.Missing = append(o.Missing, p)
+		}
+	}
+	sort.Strings(o.Missing)
+	for _, m := range w.mismatched {
+		o.Mismatched = append(o.Mismatched, m)
+	}
+	sort.Slice(o.Mismatched, func(i, j int) bool { return o.Mismatched[i].Path < o.Mismatched[j].Path })
+	return o, nil
+}
+
+type walker struct {
+	fields     map[string]map[string]bool
+	declared   map[string]bool
+	unknown    map[string]bool
+	mismatched map[string]Mismatch
+}
+
+// walk records v at path; t is the Go type it decodes into (nil when the
+// model has no opinion, e.g. below map[string]any or an unknown field).
+func (w *walker) walk(path string, v any, t reflect.Type) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	got := kindOf(v)
+	if path != "" {
+		if w.fields[path] == nil {
+			w.fields[path] = map[string]bool{}
+		}
+		w.fields[path][got] = true
+	}
+	if t == nil {
+		w.walkUntyped(path, v)
+		return
+	}
+	if want := expectedKind(t); want != "" && got != KindNull && got != want {
+		w.mismatched[path] = Mismatch{Path: path, Model: want, JSON: got}
+	}
+	if custom(t) {
+		// Types with their own UnmarshalJSON decide what their input means.
+		w.walkUntyped(path, v)
+		return
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := structFields(t)
+			for name := range fields {
+				w.declared[join(path, name)] = true
+			}
+			for k, vv := range val {
+				ft, ok := fields[k]
+				if !ok {
+					w.unknown[join(path, k)] = true
+				}
+				w.walk(join(path, k), vv, ft)
+			}
+		case reflect.Map:
+			for k, vv := range val {
+				w.walk(join(path, k), vv, elemType(t))
+			}
+		default:
+			w.walkUntyped(path, v)
+		}
+	case []any:
+		var et reflect.Type
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			et = elemType(t)
+		}
+		for _, vv := range val {
+			w.walk(path+"[]", vv, et)
+		}
+	}
+}
+
+func (w *walker) walkUntyped(path string, v any) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, vv := range val {
+			w.walk(join(path, k), vv, nil)
+		}
+	case []any:
+		for _, vv := range val {
+			w.walk(path+"[]", vv, nil)
+		}
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func kindOf(v any) string {
+	switch v.(type) {
+	case string:
+		return KindString
+	case json.Number:
+		return KindNumber
+	case bool:
+		return KindBool
+	case map[string]any:
+		return KindObject
+	case []any:
+		return KindArray
+	}
+	return KindNull
+}
+
+var (
+	unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	timeType        = reflect.TypeFor[time.Time]()
+	rawType         = reflect.TypeFor[json.RawMessage]()
+)
+
+func custom(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(unmarshalerType) || t.Implements(unmarshalerType)
+}
+
+// expectedKind is the JSON kind t decodes from by default ("" = any). Types
+// with their own UnmarshalJSON (FlexibleInt, FlexibleString, ...) are judged
+// by their underlying kind, so the drift they absorb is still reported.
+func expectedKind(t reflect.Type) string {
+	if t == rawType {
+		return ""
+	}
+	if t == timeType || embedsTime(t) {
+		return KindString
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return KindString
+	case reflect.Bool:
+		return KindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return KindNumber
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return KindString
+		}
+		return KindArray
+	case reflect.Array:
+		return KindArray
+	case reflect.Map:
+		return KindObject
+	case reflect.Struct:
+		if custom(t) {
+			return ""
+		}
+		return KindObject
+	}
+	return ""
+}
+
+func embedsTime(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := range t.NumField() {
+		if f := t.Field(i); f.Anonymous && f.Type == timeType {
+			return true
+		}
+	}
+	return false
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	e := t.Elem()
+	if e.Kind() == reflect.Interface {
+		return nil
+	}
+	return e
+}
+
+// structFields maps the JSON names of t's fields to their types, following
+// encoding/json: embedded structs are flattened and `json:"-"` is skipped.
+func structFields(t reflect.Type) map[string]reflect.Type {
+	out := map[string]reflect.Type{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && !custom(ft) {
+			for k, v := range structFields(ft) {
+				if _, ok := out[k]; !ok {
+					out[k] = v
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if ft.Kind() == reflect.Interface {
+			out[name] = nil
+			continue
+		}
+		out[name] = f.Type
+	}
+	return out
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,149 @@
+package drift
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// flexInt stands in for foodora.FlexibleInt.
+type flexInt int
+
+func (i *flexInt) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		s = string(b)
+	}
+	n, err := strconv.Atoi(s)
+	*i = flexInt(n)
+	return err
+}
+
+type history struct {
+	Status int `json:"status"`
+	Data   struct {
+		TotalCount flexInt `json:"total_count"`
+		Items      []struct {
+			OrderCode string  `json:"order_code"`
+			Total     float64 `json:"total_value"`
+			Vendor    *struct {
+				Name string `json:"name"`
+			} `json:"vendor"`
+			Extra map[string]any `json:"extra"`
+		} `json:"items"`
+	} `json:"data"`
+}
+
+func TestObserve(t *testing.T) {
+	t.Parallel()
+
+	body := `{"status":200,"data":{"total_count":"2","items":[
+		{"order_code":"a","total_value":9.5,"vendor":{"name":"V","logo":"x"},"new_flag":true},
+		{"order_code":"b","total_value":null,"vendor":null}
+	]}}`
+	o, err := Observe("GET orders/order_history", []byte(body), &history{}, time.Unix(0, 0))
+	if err != nil {
+		t.Fatalf("Observe: %v", err)
+	}
+	if o.Model != "drift.history" || o.Key() != "GET orders/order_history drift.history" {
+		t.Fatalf("model=%q", o.Model)
+	}
+	if got := o.Fields["data.items[].vendor"]; !slices.Equal(got, []string{KindNull, KindObject}) {
+		t.Fatalf("vendor kinds: %v", got)
+	}
+	if !slices.Equal(o.Unknown, []string{"data.items[].new_flag", "data.items[].vendor.logo"}) {
+		t.Fatalf("unknown: %v", o.Unknown)
+	}
+	if !slices.Equal(o.Missing, []string{"data.items[].extra"}) {
+		t.Fatalf("missing: %v", o.Missing)
+	}
+	if len(o.Mismatched) != 1 || o.Mismatched[0] != (Mismatch{Path: "data.total_count", Model: KindNumber, JSON: KindString}) {
+		t.Fatalf("mismatched: %+v", o.Mismatched)
+	}
+}
+
+func TestLog_ReportSinceLastCommit(t *testing.T) {
+	t.Parallel()
+
+	l := Open(t.TempDir())
+	l.now = func() time.Time { return time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC) }
+	l.Record("GET orders/order_history", []byte(`{"status":200,"data":{"total_count":1,"items":[{"order_code":"a","total_value":1,"vendor":{"name":"V"},"extra":{}}]}}`), &history{})
+
+	rep, err := l.Report()
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if len(rep.Endpoints) != 1 || rep.Endpoints[0].Responses != 1 || !rep.Endpoints[0].Since.IsZero() || rep.Endpoints[0].Changed() {
+		t.Fatalf("first report: %+v", rep.Endpoints)
+	}
+	if err := l.Commit(rep); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if rep, _ := l.Report(); len(rep.Endpoints) != 0 {
+		t.Fatalf("log not cleared: %+v", rep.Endpoints)
+	}
+
+	// Next run: total_count turns into a string, vendor is gone, a field is new.
+	l.Record("GET orders/order_history", []byte(`{"status":200,"data":{"total_count":"1","items":[{"order_code":"a","total_value":1,"extra":{},"eta":"soon"}]}}`), &history{})
+	rep, err = l.Report()
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	e := rep.Endpoints[0]
+	b, _ := json.Marshal(e)
+	if e.Since.IsZero() ||
+		len(e.New) != 1 || e.New[0].Path != "data.items[].eta" ||
+		len(e.Vanished) != 2 || e.Vanished[0].Path != "data.items[].vendor" || e.Vanished[1].Path != "data.items[].vendor.name" ||
+		len(e.TypeChanges) != 1 || e.TypeChanges[0].Path != "data.total_count" || !slices.Equal(e.TypeChanges[0].Now, []string{KindString}) ||
+		!slices.Equal(e.Missing, []string{"data.items[].vendor"}) ||
+		len(e.Mismatched) != 1 {
+		t.Fatalf("second report: %s", b)
+	}
+}
+
+func TestLog_BoundedAndCommitKeepsLateResponses(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	l := Open(dir)
+	l.now = func() time.Time { return time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC) }
+	body := []byte(`{"status":200,"data":{"total_count":1,"items":[{"order_code":"a","total_value":1,"vendor":{"name":"V"},"extra":{}}]}}`)
+	l.Record("GET orders/order_history", body, &history{})
+	st, err := os.Stat(filepath.Join(dir, observedFile))
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	for range 50 {
+		l.Record("GET orders/order_history", body, &history{})
+	}
+	st2, err := os.Stat(filepath.Join(dir, observedFile))
+	if err != nil || st2.Size() > st.Size()+2 {
+		t.Fatalf("log grew from %d to %d bytes (err %v)", st.Size(), st2.Size(), err)
+	}
+
+	// A response recorded by another process between Report and Commit
+	// stays for the next report; the legacy log is folded in and removed.
+	legacy := []byte(`{"endpoint":"GET vendors/{code}","model":"drift.history","at":"2026-01-01T00:00:00Z","fields":{"status":["number"]}}` + "\n")
+	if err := os.WriteFile(filepath.Join(dir, legacyLogFile), legacy, 0o600); err != nil {
+		t.Fatalf("write legacy: %v", err)
+	}
+	rep, err := l.Report()
+	if err != nil || len(rep.Endpoints) != 2 || rep.Endpoints[0].Responses != 51 || rep.Endpoints[1].Responses != 1 {
+		t.Fatalf("report: %+v %v", rep.Endpoints, err)
+	}
+	Open(dir).Record("GET orders/order_history", body, &history{})
+	if err := l.Commit(rep); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, legacyLogFile)); !os.IsNotExist(err) {
+		t.Fatalf("legacy log not removed: %v", err)
+	}
+	rep, err = l.Report()
+	if err != nil || len(rep.Endpoints) != 1 || rep.Endpoints[0].Endpoint != "GET orders/order_history" || rep.Endpoints[0].Since.IsZero() {
+		t.Fatalf("late response lost: %+v %v", rep.Endpoints, err)
+	}
+}
@@ -0,0 +1,412 @@
+package drift
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/steipete/ordercli/internal/config"
+)
+
+const (
+	observedFile = "observed.json"
+	baselineFile = "baseline.json"
+	// legacyLogFile is the unbounded per-response log older versions wrote;
+	// Report still reads it and Commit removes it.
+	legacyLogFile = "log.jsonl"
+)
+
+// DefaultDir returns the drift dir that lives next to the config file.
+func DefaultDir(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "drift")
+}
+
+// Log merges observations into dir and reports on them.
+type Log struct {
+	dir string
+	mu  sync.Mutex
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+func Open(dir string) *Log { return &Log{dir: dir, now: time.Now} }
+
+func (l *Log) Dir() string { return l.dir }
+
+// Record observes one decoded response and merges it into the log. Drift
+// tracking must never fail an API call, so errors are dropped.
+func (l *Log) Record(endpoint string, body []byte, model any) {
+	o, err := Observe(endpoint, body, model, l.now())
+	if err != nil {
+		return
+	}
+	_ = l.Append(o)
+}
+
+// Aggregate is what every response of one endpoint/model pair returned since
+// the last Commit. The log keeps one per pair, so it stays as small as the
+// API surface however many responses are recorded.
+type Aggregate struct {
+	Endpoint  string    `json:"endpoint"`
+	Model     string    `json:"model"`
+	Responses int       `json:"responses"`
+	At        time.Time `json:"at"`
+	// Fields maps every path seen to the union of its kinds.
+	Fields  map[string][]string `json:"fields"`
+	Unknown []string            `json:"unknown,omitempty"`
+	// Missing are model fields no response had.
+	Missing    []string   `json:"missing,omitempty"`
+	Mismatched []Mismatch `json:"mismatched,omitempty"`
+}
+
+func (a *Aggregate) add(o Observation) {
+	if a.Fields == nil {
+		a.Fields = map[string][]string{}
+	}
+	for p, kinds := range o.Fields {
+		a.Fields[p] = union(a.Fields[p], kinds)
+	}
+	a.Unknown = union(a.Unknown, o.Unknown)
+	if a.Responses == 0 {
+		a.Missing = o.Missing
+	} else {
+		a.Missing = intersect(a.Missing, o.Missing)
+	}
+	for _, m := range o.Mismatched {
+		i := slices.IndexFunc(a.Mismatched, func(x Mismatch) bool { return x.Path == m.Path })
+		if i >= 0 {
+			a.Mismatched[i] = m
+		} else {
+			a.Mismatched = append(a.Mismatched, m)
+		}
+	}
+	sort.Slice(a.Mismatched, func(i, j int) bool { return a.Mismatched[i].Path < a.Mismatched[j].Path })
+	a.Responses++
+	if o.At.After(a.At) {
+		a.At = o.At
+	}
+}
+
+// Append merges o into the log. Other processes may append at the same time,
+// so the file is updated under a lock (see config.Lock).
+func (l *Log) Append(o Observation) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	obs, err := l.readObserved()
+	if err != nil {
+		return err
+	}
+	a := obs[o.Key()]
+	if a == nil {
+		a = &Aggregate{Endpoint: o.Endpoint, Model: o.Model}
+		obs[o.Key()] = a
+	}
+	a.add(o)
+	return l.writeObserved(obs)
+}
+
+// Snapshot is the fields one endpoint/model pair returned.
+type Snapshot struct {
+	Endpoint string              `json:"endpoint"`
+	Model    string              `json:"model"`
+	At       time.Time           `json:"at"`
+	Fields   map[string][]string `json:"fields"`
+}
+
+// Report is the drift of every endpoint called since the last Commit.
+type Report struct {
+	Endpoints []EndpointReport `json:"endpoints"`
+
+	current map[string]Snapshot
+	// seen is the response count per key Report read from the log.
+	seen map[string]int
+}
+
+// EndpointReport compares one endpoint/model pair with its baseline (the
+// fields seen up to the last Commit) and with its model.
+type EndpointReport struct {
+	Endpoint  string `json:"endpoint"`
+	Model     string `json:"model"`
+	Responses int    `json:"responses"`
+	// Since is when the baseline was taken (zero on the first report).
+	Since time.Time `json:"since,omitzero"`
+
+	New         []Field      `json:"new,omitempty"`
+	Vanished    []Field      `json:"vanished,omitempty"`
+	TypeChanges []TypeChange `json:"type_changes,omitempty"`
+
+	Unknown    []string   `json:"unknown,omitempty"`
+	Missing    []string   `json:"missing,omitempty"`
+	Mismatched []Mismatch `json:"mismatched,omitempty"`
+}
+
+// Field is a path with the kinds it had.
+type Field struct {
+	Path  string   `json:"path"`
+	Kinds []string `json:"kinds"`
+}
+
+// TypeChange is a path whose kinds differ from the baseline.
+type TypeChange struct {
+	Path string   `json:"path"`
+	Was  []string `json:"was"`
+	Now  []string `json:"now"`
+}
+
+// Changed reports whether anything differs from the baseline or the model.
+func (r EndpointReport) Changed() bool {
+	return len(r.New)+len(r.Vanished)+len(r.TypeChanges)+len(r.Unknown)+len(r.Missing)+len(r.Mismatched) > 0
+}
+
+// Report compares the log per endpoint and model with the baseline.
+// Endpoints not called since the last Commit are left out.
+func (l *Log) Report() (Report, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	obs, err := l.readObserved()
+	if err != nil {
+		return Report{}, err
+	}
+	base, err := l.readBaseline()
+	if err != nil {
+		return Report{}, err
+	}
+	r := Report{current: map[string]Snapshot{}, seen: map[string]int{}}
+	for k, a := range obs {
+		r.seen[k] = a.Responses
+	}
+	legacy, err := l.readLegacyLog()
+	if err != nil {
+		return Report{}, err
+	}
+	for _, o := range legacy {
+		a := obs[o.Key()]
+		if a == nil {
+			a = &Aggregate{Endpoint: o.Endpoint, Model: o.Model}
+			obs[o.Key()] = a
+		}
+		a.add(o)
+	}
+
+	keys := make([]string, 0, len(obs))
+	for k := range obs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		a := obs[k]
+		rep := EndpointReport{
+			Endpoint:   a.Endpoint,
+			Model:      a.Model,
+			Responses:  a.Responses,
+			Unknown:    a.Unknown,
+			Missing:    a.Missing,
+			Mismatched: a.Mismatched,
+		}
+		if prev, ok := base[k]; ok {
+			rep.Since = prev.At
+			for _, p := range sortedPaths(a.Fields) {
+				was, ok := prev.Fields[p]
+				switch {
+				case !ok:
+					rep.New = append(rep.New, Field{Path: p, Kinds: a.Fields[p]})
+				case !slices.Equal(was, a.Fields[p]):
+					rep.TypeChanges = append(rep.TypeChanges, TypeChange{Path: p, Was: was, Now: a.Fields[p]})
+				}
+			}
+			for _, p := range sortedPaths(prev.Fields) {
+				if _, ok := a.Fields[p]; !ok {
+					rep.Vanished = append(rep.Vanished, Field{Path: p, Kinds: prev.Fields[p]})
+				}
+			}
+		}
+		r.Endpoints = append(r.Endpoints, rep)
+		r.current[k] = Snapshot{Endpoint: a.Endpoint, Model: a.Model, At: a.At, Fields: a.Fields}
+	}
+	return r, nil
+}
+
+// Commit makes r's fields the new baseline and clears what r reported from
+// the log, so the next Report shows only what changed after it. Endpoints
+// that received responses after r was taken stay in the log.
+func (l *Log) Commit(r Report) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	base, err := l.readBaseline()
+	if err != nil {
+		return err
+	}
+	for k, s := range r.current {
+		base[k] = s
+	}
+	b, err := json.MarshalIndent(base, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(l.dir, baselineFile), append(b, '\n')); err != nil {
+		return err
+	}
+
+	obs, err := l.readObserved()
+	if err != nil {
+		return err
+	}
+	for k, a := range obs {
+		if _, reported := r.current[k]; reported && a.Responses == r.seen[k] {
+			delete(obs, k)
+		}
+	}
+	if err := l.writeObserved(obs); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(l.dir, legacyLogFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// lock takes the cross-process lock on the log (see config.Lock).
+func (l *Log) lock() (func(), error) {
+	if err := os.MkdirAll(l.dir, 0o700); err != nil {
+		return nil, err
+	}
+	return config.Lock(filepath.Join(l.dir, observedFile), true)
+}
+
+func (l *Log) readObserved() (map[string]*Aggregate, error) {
+	out := map[string]*Aggregate{}
+	b, err := os.ReadFile(filepath.Join(l.dir, observedFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("drift log: %w", err)
+	}
+	return out, nil
+}
+
+func (l *Log) writeObserved(obs map[string]*Aggregate) error {
+	path := filepath.Join(l.dir, observedFile)
+	if len(obs) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	b, err := json.Marshal(obs)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, append(b, '\n'))
+}
+
+// writeFile replaces path atomically.
+func writeFile(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (l *Log) readLegacyLog() ([]Observation, error) {
+	f, err := os.Open(filepath.Join(l.dir, legacyLogFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Observation
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var o Observation
+		if err := json.Unmarshal(sc.Bytes(), &o); err != nil {
+			// A torn last line (crash mid-append) is skipped, not fatal.
+			continue
+		}
+		out = append(out, o)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("drift log: %w", err)
+	}
+	return out, nil
+}
+
+func (l *Log) readBaseline() (map[string]Snapshot, error) {
+	b, err := os.ReadFile(filepath.Join(l.dir, baselineFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]Snapshot{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("drift baseline: %w", err)
+	}
+	return out, nil
+}
+
+func union(a, b []string) []string {
+	m := map[string]bool{}
+	for _, s := range a {
+		m[s] = true
+	}
+	for _, s := range b {
+		m[s] = true
+	}
+	return sortedKeys(m)
+}
+
+func intersect(a, b []string) []string {
+	var out []string
+	for _, s := range a {
+		if slices.Contains(b, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func sortedPaths(m map[string][]string) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,249 @@
+// Package export writes normalized orders to file formats consumed by other
+// tools (expense reports, calendars).
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// Expense is one order reduced to the fields expense reports ask for.
+type Expense struct {
+	Date        time.Time
+	Provider    string
+	OrderID     string
+	Vendor      string
+	Items       []string
+	Subtotal    float64
+	DeliveryFee float64
+	ServiceFee  float64
+	Tip         float64
+	Discount    float64
+	VAT         float64
+	Total       float64
+	Currency    string
+	Payment     string
+}
+
+// NewExpense flattens o; fees of the same kind are summed.
+func NewExpense(o provider.Order) Expense {
+	e := Expense{
+		Date:     o.When(),
+		Provider: o.Provider,
+		OrderID:  o.ID,
+		Vendor:   o.Vendor,
+		Subtotal: o.Subtotal,
+		VAT:      o.VAT,
+		Total:    o.Total,
+		Currency: o.Currency,
+		Payment:  o.Payment,
+	}
+	for _, it := range o.Items {
+		e.Items = append(e.Items, itemLine(it))
+	}
+	for _, f := range o.Fees {
+		switch f.Kind {
+		case provider.FeeDelivery:
+			e.DeliveryFee += f.Amount
+		case provider.FeeTip:
+			e.Tip += f.Amount
+		case provider.FeeDiscount:
+			e.Discount += f.Amount
+		default:
+			e.ServiceFee += f.Amount
+		}
+	}
+	return e
+}
+
+// Expenses converts and sorts orders oldest first, as reports are read.
+func Expenses(orders []provider.Order) []Expense {
+	out := make([]Expense, 0, len(orders))
+	for _, o := range orders {
+		out = append(out, NewExpense(o))
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Date.Before(out[j].Date) })
+	return out
+}
+
+var expenseColumns = []string{
+	"date", "provider", "order_id", "vendor", "items",
+	"subtotal", "delivery_fee", "service_fee", "tip", "discount", "vat", "total", "currency", "payment_method",
+}
+
+func WriteExpensesCSV(w io.Writer, expenses []Expense) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(expenseColumns)
+	for _, e := range expenses {
+		_ = cw.Write([]string{
+			dateString(e.Date), e.Provider, e.OrderID, e.Vendor, strings.Join(e.Items, "; "),
+			amount(e.Subtotal), amount(e.DeliveryFee), amount(e.ServiceFee), amount(e.Tip), amount(e.Discount),
+			amount(e.VAT), amount(e.Total), e.Currency, e.Payment,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteExpensesHTML writes a self-contained page with one printable receipt per order.
+func WriteExpensesHTML(w io.Writer, expenses []Expense) error {
+	return receiptTemplate.Execute(w, expenses)
+}
+
+// WriteExpensesOFX writes an OFX 2.x credit card statement per currency, each
+// order a debit. Banking tools import these without further mapping.
+func WriteExpensesOFX(w io.Writer, expenses []Expense, now time.Time) error {
+	byCurrency := map[string][]Expense{}
+	var currencies []string
+	for _, e := range expenses {
+		cur := e.Currency
+		if cur == "" {
+			cur = "XXX"
+		}
+		if _, ok := byCurrency[cur]; !ok {
+			currencies = append(currencies, cur)
+		}
+		byCurrency[cur] = append(byCurrency[cur], e)
+	}
+	sort.Strings(currencies)
+
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	b.WriteString("<?OFX OFXHEADER=\"200\" VERSION=\"220\" SECURITY=\"NONE\" OLDFILEUID=\"NONE\" NEWFILEUID=\"NONE\"?>\n")
+	b.WriteString("<OFX>\n<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>")
+	fmt.Fprintf(&b, "<DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>\n", ofxTime(now))
+	b.WriteString("<CREDITCARDMSGSRSV1>\n")
+	for i, cur := range currencies {
+		list := byCurrency[cur]
+		fmt.Fprintf(&b, "<CCSTMTTRNRS><TRNUID>%d</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n", i+1)
+		fmt.Fprintf(&b, "<CCSTMTRS><CURDEF>%s</CURDEF><CCACCTFROM><ACCTID>ordercli-%s</ACCTID></CCACCTFROM>\n", cur, strings.ToLower(cur))
+		start, end := list[0].Date, list[len(list)-1].Date
+		if start.IsZero() {
+			start = end
+		}
+		fmt.Fprintf(&b, "<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>\n", ofxTime(start), ofxTime(end))
+		total := 0.0
+		for _, e := range list {
+			total += e.Total
+			b.WriteString("<STMTTRN><TRNTYPE>DEBIT</TRNTYPE>")
+			fmt.Fprintf(&b, "<DTPOSTED>%s</DTPOSTED>", ofxTime(e.Date))
+			fmt.Fprintf(&b, "<TRNAMT>%s</TRNAMT>", strconv.FormatFloat(-e.Total, 'f', 2, 64))
+			fmt.Fprintf(&b, "<FITID>%s</FITID>", ofxEscape(e.Provider+"-"+e.OrderID))
+			fmt.Fprintf(&b, "<NAME>%s</NAME>", ofxEscape(truncate(e.Vendor, 32)))
+			if memo := strings.Join(e.Items, "; "); memo != "" {
+				fmt.Fprintf(&b, "<MEMO>%s</MEMO>", ofxEscape(truncate(memo, 255)))
+			}
+			b.WriteString("</STMTTRN>\n")
+		}
+		b.WriteString("</BANKTRANLIST>")
+		fmt.Fprintf(&b, "<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>", strconv.FormatFloat(-total, 'f', 2, 64), ofxTime(now))
+		b.WriteString("</CCSTMTRS></CCSTMTTRNRS>\n")
+	}
+	b.WriteString("</CREDITCARDMSGSRSV1>\n</OFX>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func itemLine(it provider.Item) string {
+	s := it.Name
+	if it.Variation != "" {
+		s += " (" + it.Variation + ")"
+	}
+	if len(it.Toppings) > 0 {
+		s += " + " + strings.Join(it.Toppings, ", ")
+	}
+	if it.Quantity > 0 {
+		s = strconv.Itoa(it.Quantity) + "x " + s
+	}
+	return s
+}
+
+func amount(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func dateString(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(time.Local).Format("2006-01-02")
+}
+
+func ofxTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("20060102150405") + "[0:GMT]"
+}
+
+var ofxEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func ofxEscape(s string) string { return ofxEscaper.Replace(s) }
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+var receiptTemplate = template.Must(template.New("receipts").Funcs(template.FuncMap{
+	"amount": amount,
+	"when": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.In(time.Local).Format("2006-01-02 15:04")
+	},
+}).Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Receipts</title>
+<style>
+body { font-family: -apple-system, "Helvetica Neue", Arial, sans-serif; margin: 0; color: #111; }
+.receipt { max-width: 36em; margin: 2em auto; padding: 1.5em; border: 1px solid #ccc; }
+.receipt + .receipt { break-before: page; page-break-before: always; }
+h1 { font-size: 1.3em; margin: 0 0 .2em; }
+.meta { color: #555; margin-bottom: 1em; }
+table { width: 100%; border-collapse: collapse; }
+td { padding: .2em 0; vertical-align: top; }
+td.num { text-align: right; white-space: nowrap; }
+tr.total td { border-top: 1px solid #111; font-weight: bold; }
+@media print { .receipt { border: none; margin: 0 auto; } }
+</style>
+</head>
+<body>
+{{- range . }}
+<section class="receipt">
+<h1>{{ .Vendor }}</h1>
+<div class="meta">{{ when .Date }} · {{ .Provider }} order {{ .OrderID }}{{ if .Payment }} · paid with {{ .Payment }}{{ end }}</div>
+<table>
+{{- range .Items }}
+<tr><td>{{ . }}</td><td></td></tr>
+{{- end }}
+{{- if .Subtotal }}<tr><td>Subtotal</td><td class="num">{{ amount .Subtotal }} {{ .Currency }}</td></tr>{{ end }}
+{{- if .DeliveryFee }}<tr><td>Delivery fee</td><td class="num">{{ amount .DeliveryFee }} {{ .Currency }}</td></tr>{{ end }}
+{{- if .ServiceFee }}<tr><td>Service fees</td><td class="num">{{ amount .ServiceFee }} {{ .Currency }}</td></tr>{{ end }}
+{{- if .Tip }}<tr><td>Tip</td><td class="num">{{ amount .Tip }} {{ .Currency }}</td></tr>{{ end }}
+{{- if .Discount }}<tr><td>Discount</td><td class="num">{{ amount .Discount }} {{ .Currency }}</td></tr>{{ end }}
+<tr class="total"><td>Total</td><td class="num">{{ amount .Total }} {{ .Currency }}</td></tr>
+{{- if .VAT }}<tr><td>incl. VAT</td><td class="num">{{ amount .VAT }} {{ .Currency }}</td></tr>{{ end }}
+</table>
+</section>
+{{- end }}
+</body>
+</html>
+`))
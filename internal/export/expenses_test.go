@@ -0,0 +1,71 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func testOrders() []provider.Order {
+	return []provider.Order{
+		{
+			Provider: "foodora", ID: "B", Vendor: "Pizza & Co", Currency: "EUR",
+			DeliveredAt: time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC),
+			Total:       21.4, Subtotal: 18, VAT: 1.95, Payment: "PayPal",
+			Items: []provider.Item{{Name: "Margherita", Quantity: 2, Toppings: []string{"Basil"}}},
+			Fees:  []provider.Fee{{Kind: provider.FeeDelivery, Amount: 2.9}, {Kind: provider.FeeTip, Amount: 1.5}, {Kind: provider.FeeDiscount, Amount: -1}},
+		},
+		{
+			Provider: "foodora", ID: "A", Vendor: "Wok", Currency: "EUR",
+			DeliveredAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+			Total:       9.5,
+		},
+	}
+}
+
+func TestWriteExpensesCSV(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	if err := WriteExpensesCSV(&b, Expenses(testOrders())); err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "date,provider,order_id,vendor,items,subtotal,delivery_fee") {
+		t.Fatalf("unexpected csv:\n%s", b.String())
+	}
+	if !strings.Contains(lines[1], ",A,Wok,") {
+		t.Fatalf("expected oldest first: %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], ",B,Pizza & Co,2x Margherita + Basil,18.00,2.90,,1.50,-1.00,1.95,21.40,EUR,PayPal") {
+		t.Fatalf("unexpected row: %q", lines[2])
+	}
+}
+
+func TestWriteExpensesOFXAndHTML(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	now := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	if err := WriteExpensesOFX(&b, Expenses(testOrders()), now); err != nil {
+		t.Fatalf("ofx: %v", err)
+	}
+	ofx := b.String()
+	for _, want := range []string{"<CURDEF>EUR</CURDEF>", "<TRNAMT>-21.40</TRNAMT>", "<FITID>foodora-B</FITID>", "<NAME>Pizza &amp; Co</NAME>", "<BALAMT>-30.90</BALAMT>"} {
+		if !strings.Contains(ofx, want) {
+			t.Fatalf("ofx missing %s:\n%s", want, ofx)
+		}
+	}
+
+	b.Reset()
+	if err := WriteExpensesHTML(&b, Expenses(testOrders())); err != nil {
+		t.Fatalf("html: %v", err)
+	}
+	html := b.String()
+	if strings.Count(html, `<section class="receipt">`) != 2 || !strings.Contains(html, "Pizza &amp; Co") || !strings.Contains(html, "paid with PayPal") {
+		t.Fatalf("unexpected html:\n%s", html)
+	}
+}
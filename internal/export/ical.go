@@ -0,0 +1,117 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// EventDuration is the length of each delivery event.
+const EventDuration = 30 * time.Minute
+
+// EventTime is when an order shows up in the calendar: the confirmed
+// delivery, else the estimate for orders underway. ok is false for orders with
+// neither (they're left out).
+func EventTime(o provider.Order) (t time.Time, estimated bool, ok bool) {
+	switch {
+	case !o.DeliveredAt.IsZero():
+		return o.DeliveredAt, false, true
+	case !o.EstimatedAt.IsZero():
+		return o.EstimatedAt, true, true
+	default:
+		return time.Time{}, false, false
+	}
+}
+
+// WriteICal writes an RFC 5545 calendar with one event per deliverable order.
+// UIDs are stable (provider + order id), so re-imports update events in place.
+func WriteICal(w io.Writer, name string, orders []provider.Order, now time.Time) error {
+	sorted := append([]provider.Order(nil), orders...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, _, _ := EventTime(sorted[i])
+		tj, _, _ := EventTime(sorted[j])
+		return ti.Before(tj)
+	})
+
+	var b strings.Builder
+	line := func(s string) { b.WriteString(foldLine(s)) }
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//ordercli//orders//EN")
+	line("CALSCALE:GREGORIAN")
+	if name != "" {
+		line("X-WR-CALNAME:" + icalText(name))
+	}
+	for _, o := range sorted {
+		start, estimated, ok := EventTime(o)
+		if !ok {
+			continue
+		}
+		summary := o.Vendor
+		if summary == "" {
+			summary = o.Provider + " order"
+		}
+		line("BEGIN:VEVENT")
+		line("UID:" + icalText(o.Provider+"-"+o.ID+"@ordercli"))
+		line("DTSTAMP:" + icalTime(now))
+		line("DTSTART:" + icalTime(start))
+		line("DTEND:" + icalTime(start.Add(EventDuration)))
+		line("SUMMARY:" + icalText(summary))
+		line("DESCRIPTION:" + icalText(eventDescription(o)))
+		if estimated {
+			line("STATUS:TENTATIVE")
+		} else {
+			line("STATUS:CONFIRMED")
+		}
+		line("TRANSP:TRANSPARENT")
+		line("CATEGORIES:" + icalText(o.Provider))
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func eventDescription(o provider.Order) string {
+	var lines []string
+	for _, it := range o.Items {
+		lines = append(lines, itemLine(it))
+	}
+	if o.Total != 0 {
+		lines = append(lines, strings.TrimSpace("Total: "+strconv.FormatFloat(o.Total, 'f', 2, 64)+" "+o.Currency))
+	}
+	if o.Status != "" {
+		lines = append(lines, "Status: "+o.Status)
+	}
+	lines = append(lines, fmt.Sprintf("%s order %s", o.Provider, o.ID))
+	return strings.Join(lines, "\n")
+}
+
+func icalTime(t time.Time) string { return t.UTC().Format("20060102T150405Z") }
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func icalText(s string) string { return icalEscaper.Replace(s) }
+
+// foldLine terminates s with CRLF, folding at 75 octets without splitting
+// UTF-8 sequences (RFC 5545 3.1).
+func foldLine(s string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > 75 {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func TestWriteICal(t *testing.T) {
+	t.Parallel()
+
+	orders := []provider.Order{
+		{
+			Provider: "foodora", ID: "F1", Vendor: "Thai, Place", Total: 12.3, Currency: "EUR",
+			DeliveredAt: time.Date(2025, 3, 2, 12, 30, 0, 0, time.UTC),
+			Items:       []provider.Item{{Name: "Pad Thai", Quantity: 1}, {Name: strings.Repeat("Very long dish name ", 5)}},
+		},
+		{Provider: "deliveroo", ID: "D1", Vendor: "Burger", EstimatedAt: time.Date(2025, 3, 3, 19, 0, 0, 0, time.UTC)},
+		{Provider: "glovo", ID: "G1", Vendor: "No time"},
+	}
+	var b bytes.Buffer
+	if err := WriteICal(&b, "Lunch", orders, time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteICal: %v", err)
+	}
+	out := b.String()
+
+	if strings.Count(out, "BEGIN:VEVENT") != 2 || strings.Contains(out, "G1") {
+		t.Fatalf("unexpected events:\n%s", out)
+	}
+	for _, want := range []string{
+		"X-WR-CALNAME:Lunch\r\n",
+		"UID:foodora-F1@ordercli\r\n",
+		"DTSTART:20250302T123000Z\r\nDTEND:20250302T130000Z\r\n",
+		`SUMMARY:Thai\, Place`,
+		`DESCRIPTION:1x Pad Thai\nVery long dish name`,
+		"DTSTART:20250303T190000Z\r\n",
+		"STATUS:TENTATIVE\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+	for _, l := range strings.Split(out, "\r\n") {
+		if len(l) > 75 {
+			t.Fatalf("line not folded (%d octets): %q", len(l), l)
+		}
+	}
+	if strings.Index(out, "foodora-F1") > strings.Index(out, "deliveroo-D1") {
+		t.Fatalf("expected events sorted by time")
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/steipete/ordercli/internal/httpx"
 )
 
 type APKFirebaseConfig struct {
@@ -49,12 +51,12 @@ type RemoteConfigClient struct {
 	http *http.Client
 }
 
-func NewRemoteConfigClient(cfg APKFirebaseConfig) *RemoteConfigClient {
+// NewRemoteConfigClient returns a client for the app's Firebase project;
+// opts tunes the HTTP transport (see httpx.Options).
+func NewRemoteConfigClient(cfg APKFirebaseConfig, opts httpx.Options) *RemoteConfigClient {
 	return &RemoteConfigClient{
-		cfg: cfg,
-		http: &http.Client{
-			Timeout: 20 * time.Second,
-		},
+		cfg:  cfg,
+		http: httpx.NewClient(opts),
 	}
 }
 
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+
+	"github.com/steipete/ordercli/internal/httpx"
 )
 
 type roundTripFunc func(*http.Request) (*http.Response, error)
@@ -16,7 +18,7 @@ func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { retu
 func TestRemoteConfigClient_Fetch(t *testing.T) {
 	t.Parallel()
 
-	c := NewRemoteConfigClient(MjamAT)
+	c := NewRemoteConfigClient(MjamAT, httpx.Options{})
 
 	var sawInstall bool
 	var sawFetch bool
@@ -93,7 +95,7 @@ func TestNewFID_Length(t *testing.T) {
 func TestRemoteConfigClient_Fetch_ErrorStatus(t *testing.T) {
 	t.Parallel()
 
-	c := NewRemoteConfigClient(MjamAT)
+	c := NewRemoteConfigClient(MjamAT, httpx.Options{})
 	c.http.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
 		return &http.Response{
 			StatusCode: 500,
@@ -11,7 +11,9 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
+
+	"github.com/steipete/ordercli/internal/auth"
+	"github.com/steipete/ordercli/internal/httpx"
 )
 
 type Client struct {
@@ -26,21 +28,36 @@ type Client struct {
 	appName        string
 	originalUA     string
 
-	accessToken string
-	userAgent   string
+	tokens    auth.Source
+	userAgent string
+	drift     DriftRecorder
+}
+
+// DriftRecorder is shown every decoded fd-api response with the model it
+// went into, to track API changes (see internal/drift). endpoint is the
+// method and path with IDs replaced, e.g. "GET tracking/orders/{code}".
+type DriftRecorder interface {
+	Record(endpoint string, body []byte, model any)
 }
 
 type Options struct {
-	BaseURL           string
-	DeviceID          string
-	GlobalEntityID    string
-	TargetCountryISO  string
-	AccessToken       string
+	BaseURL          string
+	DeviceID         string
+	GlobalEntityID   string
+	TargetCountryISO string
+	AccessToken      string
+	// TokenSource overrides AccessToken; a refreshing source lets requests
+	// recover from HTTP 401 by renewing the token and retrying once.
+	TokenSource       auth.Source
 	UserAgent         string
 	CookieHeader      string
 	FPAPIKey          string
 	AppName           string
 	OriginalUserAgent string
+	// HTTP tunes timeouts, retries and pacing (see httpx.Options).
+	HTTP httpx.Options
+	// Drift, if set, records the shape of every decoded response.
+	Drift DriftRecorder
 }
 
 func New(opts Options) (*Client, error) {
@@ -55,16 +72,19 @@ func New(opts Options) (*Client, error) {
 		u.Path += "/"
 	}
 
+	tokens := opts.TokenSource
+	if tokens == nil && opts.AccessToken != "" {
+		tokens = auth.Static(opts.AccessToken)
+	}
+
 	ua := opts.UserAgent
 	if ua == "" {
 		ua = "ordercli"
 	}
 
 	return &Client{
-		baseURL: u,
-		http: &http.Client{
-			Timeout: 20 * time.Second,
-		},
+		baseURL:        u,
+		http:           httpx.NewClient(opts.HTTP),
 		deviceID:       opts.DeviceID,
 		globalEntityID: opts.GlobalEntityID,
 		targetISO:      opts.TargetCountryISO,
@@ -72,12 +92,14 @@ func New(opts Options) (*Client, error) {
 		fpAPIKey:       opts.FPAPIKey,
 		appName:        opts.AppName,
 		originalUA:     opts.OriginalUserAgent,
-		accessToken:    opts.AccessToken,
+		tokens:         tokens,
 		userAgent:      ua,
+		drift:          opts.Drift,
 	}, nil
 }
 
-func (c *Client) SetAccessToken(token string) { c.accessToken = token }
+// SetAccessToken switches the client to a fixed token.
+func (c *Client) SetAccessToken(token string) { c.tokens = auth.Static(token) }
 
 func (c *Client) OAuthTokenPassword(ctx context.Context, req OAuthPasswordRequest) (AuthToken, *MfaChallenge, error) {
 	values := url.Values{}
@@ -206,33 +228,11 @@ type oauthHeaders struct {
 }
 
 func (c *Client) oauthToken(ctx context.Context, form url.Values, h oauthHeaders) (AuthToken, *MfaChallenge, error) {
-	u := c.baseURL.ResolveReference(&url.URL{Path: "oauth2/token"})
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(form.Encode()))
+	req, err := c.newRequest(ctx, http.MethodPost, "oauth2/token", nil, strings.NewReader(form.Encode()))
 	if err != nil {
 		return AuthToken{}, nil, err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("Accept", "application/json")
-	if c.userAgent != "" {
-		req.Header.Set("User-Agent", c.userAgent)
-	}
-	if c.originalUA != "" {
-		req.Header.Set("X-Original-User-Agent", c.originalUA)
-	}
-	if c.deviceID != "" {
-		req.Header.Set("X-Device", c.deviceID)
-		req.Header.Set("Device-Id", c.deviceID)
-	}
-	if c.cookieHeader != "" {
-		req.Header.Set("Cookie", c.cookieHeader)
-	}
-	if c.fpAPIKey != "" {
-		req.Header.Set("X-FP-API-KEY", c.fpAPIKey)
-	}
-	if c.appName != "" {
-		req.Header.Set("App-Name", c.appName)
-	}
 	req.Header.Set("X-OTP-Method", h.otpMethod)
 	if h.otpCode != "" {
 		req.Header.Set("X-OTP", h.otpCode)
@@ -277,151 +277,165 @@ func (c *Client) oauthToken(ctx context.Context, form url.Values, h oauthHeaders
 }
 
 func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out any) error {
-	u := c.baseURL.ResolveReference(&url.URL{Path: path})
-	if len(query) > 0 {
-		u.RawQuery = query.Encode()
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	req, err := c.newRequest(ctx, http.MethodGet, path, query, nil)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Accept", "application/json")
-	if c.userAgent != "" {
-		req.Header.Set("User-Agent", c.userAgent)
-	}
-	if c.originalUA != "" {
-		req.Header.Set("X-Original-User-Agent", c.originalUA)
-	}
-	if c.deviceID != "" {
-		req.Header.Set("X-Device", c.deviceID)
-		req.Header.Set("Device-Id", c.deviceID)
-	}
-	if c.cookieHeader != "" {
-		req.Header.Set("Cookie", c.cookieHeader)
-	}
-	if c.fpAPIKey != "" {
-		req.Header.Set("X-FP-API-KEY", c.fpAPIKey)
-	}
-	if c.appName != "" {
-		req.Header.Set("App-Name", c.appName)
-	}
-	if c.globalEntityID != "" {
-		req.Header.Set("X-Global-Entity-ID", c.globalEntityID)
-	}
-	if c.targetISO != "" {
-		req.Header.Set("X-Target-Country-Code-ISO", c.targetISO)
-	}
-	if c.accessToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	c.setMarketHeaders(req)
+	body, err := c.doAuthed(req)
+	if err != nil {
+		return err
 	}
+	return c.decode(req.Method, path, body, out)
+}
 
-	res, err := c.http.Do(req)
+func (c *Client) postJSON(ctx context.Context, path string, query url.Values, in any, out any) error {
+	b, err := json.Marshal(in)
 	if err != nil {
-		return err
+		return fmt.Errorf("%s: encode JSON: %w", path, err)
 	}
-	defer res.Body.Close()
 
-	body, err := io.ReadAll(io.LimitReader(res.Body, 4<<20))
+	req, err := c.newRequest(ctx, http.MethodPost, path, query, bytes.NewReader(b))
 	if err != nil {
 		return err
 	}
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return &HTTPError{
-			Method:     req.Method,
-			URL:        req.URL.String(),
-			StatusCode: res.StatusCode,
-			Body:       body,
-		}
+	req.Header.Set("Content-Type", "application/json")
+	c.setMarketHeaders(req)
+	body, err := c.doAuthed(req)
+	if err != nil {
+		return err
 	}
+	return c.decode(req.Method, path, body, out)
+}
 
+// decode fills out from body, tolerating API drift: a strict decode first,
+// then a lenient one. The drift recorder sees every response either way.
+func (c *Client) decode(method, path string, body []byte, out any) error {
 	dec := json.NewDecoder(bytes.NewReader(body))
 	dec.DisallowUnknownFields()
-	if err := dec.Decode(out); err == nil {
-		return nil
+	if err := dec.Decode(out); err != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("%s: decode JSON: %w", path, err)
+		}
 	}
-
-	// Fallback: tolerate API drift by decoding as lenient JSON.
-	if err := json.Unmarshal(body, out); err != nil {
-		return fmt.Errorf("%s: decode JSON: %w", path, err)
+	if c.drift != nil {
+		c.drift.Record(method+" "+endpoint(path), body, out)
 	}
 	return nil
 }
 
-func (c *Client) postJSON(ctx context.Context, path string, query url.Values, in any, out any) error {
+// endpoint is path with order codes and other IDs (segments containing a
+// digit) replaced by {code}, so calls group per API endpoint.
+func endpoint(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if strings.ContainsAny(p, "0123456789") {
+			parts[i] = "{code}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// newRequest builds a request for path (relative to the base URL) with the
+// app headers every fd-api call sends.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Request, error) {
 	u := c.baseURL.ResolveReference(&url.URL{Path: path})
 	if len(query) > 0 {
 		u.RawQuery = query.Encode()
 	}
-
-	b, err := json.Marshal(in)
-	if err != nil {
-		return fmt.Errorf("%s: encode JSON: %w", path, err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(b))
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
-	if c.userAgent != "" {
-		req.Header.Set("User-Agent", c.userAgent)
-	}
-	if c.originalUA != "" {
-		req.Header.Set("X-Original-User-Agent", c.originalUA)
-	}
-	if c.deviceID != "" {
-		req.Header.Set("X-Device", c.deviceID)
-		req.Header.Set("Device-Id", c.deviceID)
-	}
-	if c.cookieHeader != "" {
-		req.Header.Set("Cookie", c.cookieHeader)
-	}
-	if c.fpAPIKey != "" {
-		req.Header.Set("X-FP-API-KEY", c.fpAPIKey)
-	}
-	if c.appName != "" {
-		req.Header.Set("App-Name", c.appName)
+	for k, v := range map[string]string{
+		"User-Agent":            c.userAgent,
+		"X-Original-User-Agent": c.originalUA,
+		"X-Device":              c.deviceID,
+		"Device-Id":             c.deviceID,
+		"Cookie":                c.cookieHeader,
+		"X-FP-API-KEY":          c.fpAPIKey,
+		"App-Name":              c.appName,
+	} {
+		if v != "" {
+			req.Header.Set(k, v)
+		}
 	}
+	return req, nil
+}
+
+// setMarketHeaders adds the entity/country headers API (non-OAuth) calls need.
+func (c *Client) setMarketHeaders(req *http.Request) {
 	if c.globalEntityID != "" {
 		req.Header.Set("X-Global-Entity-ID", c.globalEntityID)
 	}
 	if c.targetISO != "" {
 		req.Header.Set("X-Target-Country-Code-ISO", c.targetISO)
 	}
-	if c.accessToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+}
+
+// doAuthed sends req with the current token and returns the body of a 2xx
+// response. After a 401 it asks the token source for a new token and retries
+// once; sources that cannot refresh leave the 401 as is.
+func (c *Client) doAuthed(req *http.Request) ([]byte, error) {
+	ctx := req.Context()
+	var token string
+	if c.tokens != nil {
+		var err error
+		if token, err = c.tokens.Token(ctx); err != nil {
+			return nil, fmt.Errorf("access token: %w", err)
+		}
+	}
+
+	status, body, err := c.send(req, token)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusUnauthorized && c.tokens != nil {
+		fresh, rerr := c.tokens.Refresh(ctx, token)
+		switch {
+		case rerr == nil && fresh != token:
+			retry := req.Clone(ctx)
+			if req.GetBody != nil {
+				if retry.Body, err = req.GetBody(); err != nil {
+					return nil, err
+				}
+			}
+			if status, body, err = c.send(retry, fresh); err != nil {
+				return nil, err
+			}
+		case rerr != nil && !errors.Is(rerr, auth.ErrNoRefresh):
+			return nil, fmt.Errorf("%w (token refresh failed: %v)", c.httpError(req, status, body), rerr)
+		}
+	}
+	if status < 200 || status >= 300 {
+		return nil, c.httpError(req, status, body)
 	}
+	return body, nil
+}
 
+func (c *Client) send(req *http.Request, token string) (int, []byte, error) {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 	res, err := c.http.Do(req)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 	defer res.Body.Close()
 
 	body, err := io.ReadAll(io.LimitReader(res.Body, 4<<20))
 	if err != nil {
-		return err
-	}
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return &HTTPError{
-			Method:     req.Method,
-			URL:        req.URL.String(),
-			StatusCode: res.StatusCode,
-			Body:       body,
-		}
-	}
-
-	dec := json.NewDecoder(bytes.NewReader(body))
-	dec.DisallowUnknownFields()
-	if err := dec.Decode(out); err == nil {
-		return nil
+		return 0, nil, err
 	}
+	return res.StatusCode, body, nil
+}
 
-	// Fallback: tolerate API drift by decoding as lenient JSON.
-	if err := json.Unmarshal(body, out); err != nil {
-		return fmt.Errorf("%s: decode JSON: %w", path, err)
+func (c *Client) httpError(req *http.Request, status int, body []byte) *HTTPError {
+	return &HTTPError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: status,
+		Body:       body,
 	}
-	return nil
 }
@@ -2,10 +2,14 @@ package foodora
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+
+	"github.com/steipete/ordercli/internal/auth"
 )
 
 func TestClient_ActiveOrders_DecodeFallback(t *testing.T) {
@@ -138,3 +142,58 @@ func TestNew_AddsTrailingSlash(t *testing.T) {
 		t.Fatalf("got %q", u)
 	}
 }
+
+func TestClient_RefreshesAndRetriesOn401(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":"invalid_token"}`))
+			return
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["reorder_time"] != "now" {
+			t.Errorf("retry lost the request body: %v %v", body, err)
+		}
+		_, _ = w.Write([]byte(`{"status":200}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var saved []auth.Token
+	tokens := auth.NewRefresher(auth.Token{AccessToken: "stale", RefreshToken: "r"}, func(ctx context.Context, cur auth.Token) (auth.Token, error) {
+		return auth.Token{AccessToken: "fresh"}, nil
+	}, func(tok auth.Token) { saved = append(saved, tok) })
+
+	c, err := New(Options{BaseURL: srv.URL + "/", TokenSource: tokens})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := c.OrderReorder(context.Background(), "abc", ReorderRequestBody{ReorderTime: "now"}); err != nil {
+		t.Fatalf("OrderReorder: %v", err)
+	}
+	if calls != 2 || len(saved) != 1 || saved[0].AccessToken != "fresh" {
+		t.Fatalf("calls=%d saved=%+v", calls, saved)
+	}
+}
+
+func TestClient_401WithoutRefreshIsReturned(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := New(Options{BaseURL: srv.URL + "/", AccessToken: "tok"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_, err = c.ActiveOrders(context.Background())
+	var he *HTTPError
+	if !errors.As(err, &he) || he.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -4,9 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"regexp"
-	"strconv"
-	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/httpx"
+	"github.com/steipete/ordercli/internal/redact"
 )
 
 type HTTPError struct {
@@ -24,53 +25,9 @@ func (e *HTTPError) Error() string {
 	return fmt.Sprintf("%s %s: HTTP %d: %s", e.Method, e.URL, e.StatusCode, body)
 }
 
-var sensitiveJSONKeys = map[string]struct{}{
-	"access_token":  {},
-	"refresh_token": {},
-	"client_secret": {},
-	"password":      {},
-	"mfa_token":     {},
-	"otp":           {},
-	"x-otp":         {},
-	"address":       {},
-}
-
-var sensitiveJSONValueRE = regexp.MustCompile(`(?i)(\"(?:access_token|refresh_token|client_secret|password|mfa_token|otp)\"\\s*:\\s*)\"[^\"]*\"`)
-
-func redactSensitive(b []byte) string {
-	if len(b) == 0 {
-		return ""
-	}
-
-	var v any
-	if err := json.Unmarshal(b, &v); err == nil {
-		redactAny(v)
-		if out, err := json.Marshal(v); err == nil {
-			return string(out)
-		}
-	}
-
-	// Best-effort: redact common JSON patterns in string bodies.
-	s := string(b)
-	return sensitiveJSONValueRE.ReplaceAllString(s, `$1"***"`)
-}
-
-func redactAny(v any) {
-	switch t := v.(type) {
-	case map[string]any:
-		for k, vv := range t {
-			if _, ok := sensitiveJSONKeys[strings.ToLower(k)]; ok {
-				t[k] = "***"
-				continue
-			}
-			redactAny(vv)
-		}
-	case []any:
-		for i := range t {
-			redactAny(t[i])
-		}
-	}
-}
+// redactSensitive masks tokens, secrets, credentials and addresses in a
+// response body (see redact.Body).
+func redactSensitive(b []byte) string { return redact.Body(b) }
 
 type MfaChallenge struct {
 	Channel        string
@@ -98,10 +55,8 @@ func parseMfaTriggered(body []byte, header http.Header) (MfaChallenge, bool) {
 	}
 
 	reset := 30
-	if v := header.Get("ratelimit-reset"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			reset = n
-		}
+	if d, ok := httpx.RetryAfter(header, time.Now()); ok {
+		reset = int(d / time.Second)
 	}
 
 	ch := MfaChallenge{
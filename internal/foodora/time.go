@@ -3,6 +3,7 @@ package foodora
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -62,6 +63,12 @@ func (t *FlexibleTime) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// ParseTime parses the string forms FlexibleTime accepts, for payloads that
+// are decoded loosely (maps, other providers).
+func ParseTime(s string) (time.Time, error) {
+	return parseAPITimeString(strings.TrimSpace(s))
+}
+
 func parseAPITimeString(s string) (time.Time, error) {
 	layouts := []string{
 		time.RFC3339Nano,
@@ -0,0 +1,98 @@
+package glovo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/auth"
+)
+
+// AuthToken is the response of the oauth/token and oauth/refresh endpoints
+// (the same object the web app keeps in localStorage glovo_auth_info).
+type AuthToken struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"`
+	TokenType    string `json:"tokenType,omitempty"`
+}
+
+// ExpiresAt returns when the access token expires, or zero if unknown.
+func (t AuthToken) ExpiresAt(now time.Time) time.Time {
+	if t.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+	return now.Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
+type passwordGrant struct {
+	GrantType string `json:"grantType"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	UserType  string `json:"userType"`
+}
+
+type refreshGrant struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Login exchanges email and password for a token pair.
+func (c *Client) Login(ctx context.Context, email, password string) (AuthToken, error) {
+	if strings.TrimSpace(email) == "" || password == "" {
+		return AuthToken{}, errors.New("login: missing email or password")
+	}
+	return c.postToken(ctx, "oauth/token", passwordGrant{
+		GrantType: "password",
+		Username:  email,
+		Password:  password,
+		UserType:  "customer",
+	})
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+func (c *Client) Refresh(ctx context.Context, refreshToken string) (AuthToken, error) {
+	if refreshToken == "" {
+		return AuthToken{}, errors.New("refresh: missing refresh token")
+	}
+	return c.postToken(ctx, "oauth/refresh", refreshGrant{RefreshToken: refreshToken})
+}
+
+// SetAccessToken switches the client to a fixed token.
+func (c *Client) SetAccessToken(token string) { c.tokens = auth.Static(token) }
+
+func (c *Client) postToken(ctx context.Context, path string, in any) (AuthToken, error) {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return AuthToken{}, fmt.Errorf("%s: encode JSON: %w", path, err)
+	}
+	u := c.baseURL.ResolveReference(&url.URL{Path: path})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(b))
+	if err != nil {
+		return AuthToken{}, err
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	status, body, err := c.send(req, "")
+	if err != nil {
+		return AuthToken{}, err
+	}
+	if status < 200 || status >= 300 {
+		return AuthToken{}, httpError(req, status, body)
+	}
+
+	var tok AuthToken
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return AuthToken{}, fmt.Errorf("%s: decode JSON: %w", path, err)
+	}
+	if tok.AccessToken == "" {
+		return AuthToken{}, fmt.Errorf("%s: response has no accessToken", path)
+	}
+	return tok, nil
+}
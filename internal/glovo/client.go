@@ -12,13 +12,16 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/steipete/ordercli/internal/auth"
+	"github.com/steipete/ordercli/internal/httpx"
 )
 
 // Client is a Glovo API client
 type Client struct {
 	baseURL      *url.URL
 	http         *http.Client
-	accessToken  string
+	tokens       auth.Source
 	deviceURN    string
 	cityCode     string
 	countryCode  string
@@ -32,20 +35,21 @@ type Client struct {
 type Options struct {
 	BaseURL     string
 	AccessToken string
+	// TokenSource overrides AccessToken; a refreshing source renews the
+	// token after an authentication error and retries once.
+	TokenSource auth.Source
 	DeviceURN   string
 	CityCode    string
 	CountryCode string
 	Language    string
 	Latitude    float64
 	Longitude   float64
+	// HTTP tunes timeouts, retries and pacing (see httpx.Options).
+	HTTP httpx.Options
 }
 
-// New creates a new Glovo API client
+// New creates a new Glovo API client. AccessToken may be empty for Login.
 func New(opts Options) (*Client, error) {
-	if opts.AccessToken == "" {
-		return nil, errors.New("access token not set (run `ordercli glovo session <token>`)")
-	}
-
 	baseURL := opts.BaseURL
 	if baseURL == "" {
 		baseURL = "https://api.glovoapp.com"
@@ -66,6 +70,11 @@ func New(opts Options) (*Client, error) {
 
 	sessionID := newUUID()
 
+	tokens := opts.TokenSource
+	if tokens == nil {
+		tokens = auth.Static(opts.AccessToken)
+	}
+
 	lang := opts.Language
 	if lang == "" {
 		lang = "en"
@@ -73,8 +82,8 @@ func New(opts Options) (*Client, error) {
 
 	return &Client{
 		baseURL:      u,
-		http:         &http.Client{Timeout: 20 * time.Second},
-		accessToken:  opts.AccessToken,
+		http:         httpx.NewClient(opts.HTTP),
+		tokens:       tokens,
 		deviceURN:    deviceURN,
 		cityCode:     opts.CityCode,
 		countryCode:  opts.CountryCode,
@@ -88,7 +97,6 @@ func New(opts Options) (*Client, error) {
 // setHeaders sets all required Glovo API headers
 func (c *Client) setHeaders(req *http.Request) {
 	req.Header.Set("Accept", "application/json, text/plain, */*")
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
 
 	// Required glovo-* headers
 	req.Header.Set("glovo-api-version", "14")
@@ -164,26 +172,11 @@ func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out
 	}
 	c.setHeaders(req)
 
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	body, err := c.doAuthed(req)
 	if err != nil {
 		return err
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return &HTTPError{
-			Method:     req.Method,
-			URL:        req.URL.String(),
-			StatusCode: resp.StatusCode,
-			Body:       body,
-		}
-	}
-
 	if err := json.Unmarshal(body, out); err != nil {
 		return fmt.Errorf("%s: decode JSON: %w", path, err)
 	}
@@ -261,3 +254,59 @@ func (c *Client) GetOrder(ctx context.Context, orderID int) (Order, error) {
 
 	return Order{}, fmt.Errorf("order %d not found in recent history", orderID)
 }
+
+// doAuthed sends req with the current token and returns the body of a 2xx
+// response. After a 401/403 it asks the token source for a new token and
+// retries once.
+func (c *Client) doAuthed(req *http.Request) ([]byte, error) {
+	ctx := req.Context()
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("access token: %w", err)
+	}
+	status, body, err := c.send(req, token)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		fresh, rerr := c.tokens.Refresh(ctx, token)
+		switch {
+		case rerr == nil && fresh != token:
+			if status, body, err = c.send(req.Clone(ctx), fresh); err != nil {
+				return nil, err
+			}
+		case rerr != nil && !errors.Is(rerr, auth.ErrNoRefresh):
+			return nil, fmt.Errorf("%w (token refresh failed: %v)", httpError(req, status, body), rerr)
+		}
+	}
+	if status < 200 || status >= 300 {
+		return nil, httpError(req, status, body)
+	}
+	return body, nil
+}
+
+func (c *Client) send(req *http.Request, token string) (int, []byte, error) {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
+
+func httpError(req *http.Request, status int, body []byte) *HTTPError {
+	return &HTTPError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: status,
+		Body:       body,
+	}
+}
@@ -0,0 +1,271 @@
+// Package httpx is the HTTP transport shared by the provider clients: it
+// retries idempotent requests with exponential backoff and jitter, waits out
+// Retry-After / ratelimit-reset, and paces requests per host.
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults for a zero Options.
+const (
+	DefaultTimeout        = 60 * time.Second
+	DefaultAttemptTimeout = 20 * time.Second
+	DefaultRetries        = 3
+	DefaultBaseDelay      = 250 * time.Millisecond
+	DefaultMaxDelay       = 5 * time.Second
+	DefaultMaxWait        = 30 * time.Second
+	DefaultRate           = 5
+	DefaultBurst          = 10
+)
+
+// Options configures NewClient. Zero values pick the defaults above;
+// negative Retries or Rate turn retries or pacing off.
+type Options struct {
+	// Timeout bounds a whole call, retries and waits included.
+	Timeout time.Duration
+	// AttemptTimeout bounds the wait for response headers of one attempt.
+	AttemptTimeout time.Duration
+
+	// Retries is how often an idempotent request is retried after a network
+	// error, 429 or 5xx.
+	Retries   int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MaxWait is the longest Retry-After / ratelimit-reset that is waited
+	// out; longer ones return the response as is.
+	MaxWait time.Duration
+
+	// Rate is requests per second per host, with bursts up to Burst. Clients
+	// with the default rate share their buckets across the process.
+	Rate  float64
+	Burst int
+
+	// Base is the underlying transport (default: http.DefaultTransport,
+	// cloned to apply AttemptTimeout).
+	Base http.RoundTripper
+}
+
+// NewClient returns an http.Client using a Transport built from opts.
+func NewClient(opts Options) *http.Client {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	return &http.Client{Timeout: opts.Timeout, Transport: NewTransport(opts)}
+}
+
+// Transport is the retrying, rate-limited http.RoundTripper.
+type Transport struct {
+	base      http.RoundTripper
+	retries   int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	maxWait   time.Duration
+	limits    *hostLimits
+
+	// now and sleep are replaced in tests.
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// sharedLimits paces every client that uses the default rate.
+var sharedLimits = newHostLimits(DefaultRate, DefaultBurst)
+
+func NewTransport(opts Options) *Transport {
+	t := &Transport{
+		base:      opts.Base,
+		retries:   opts.Retries,
+		baseDelay: opts.BaseDelay,
+		maxDelay:  opts.MaxDelay,
+		maxWait:   opts.MaxWait,
+		now:       time.Now,
+		sleep:     sleep,
+	}
+	if t.base == nil {
+		t.base = NewBaseTransport(opts)
+	}
+	switch {
+	case t.retries == 0:
+		t.retries = DefaultRetries
+	case t.retries < 0:
+		t.retries = 0
+	}
+	if t.baseDelay <= 0 {
+		t.baseDelay = DefaultBaseDelay
+	}
+	if t.maxDelay <= 0 {
+		t.maxDelay = DefaultMaxDelay
+	}
+	if t.maxWait <= 0 {
+		t.maxWait = DefaultMaxWait
+	}
+	switch {
+	case opts.Rate == 0:
+		t.limits = sharedLimits
+	case opts.Rate > 0:
+		burst := opts.Burst
+		if burst <= 0 {
+			burst = DefaultBurst
+		}
+		t.limits = newHostLimits(opts.Rate, burst)
+	}
+	return t
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retryable := Idempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for attempt := 0; ; attempt++ {
+		if t.limits != nil {
+			if err := t.limits.wait(ctx, req.URL.Host, t.now, t.sleep); err != nil {
+				return nil, err
+			}
+		}
+
+		r := req
+		if attempt > 0 {
+			r = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+		res, err := t.base.RoundTrip(r)
+
+		if !retryable || attempt >= t.retries {
+			return res, err
+		}
+		var delay time.Duration
+		switch {
+		case err != nil:
+			if !retryableError(ctx, err) {
+				return nil, err
+			}
+			delay = t.backoff(attempt)
+		case res.StatusCode == http.StatusTooManyRequests || retryableStatus(res.StatusCode):
+			wait, ok := RetryAfter(res.Header, t.now())
+			switch {
+			case ok && wait > t.maxWait:
+				return res, nil
+			case ok:
+				delay = wait
+			default:
+				delay = t.backoff(attempt)
+			}
+		default:
+			return res, nil
+		}
+		if deadline, ok := ctx.Deadline(); ok && t.now().Add(delay).After(deadline) {
+			return res, err
+		}
+		if res != nil {
+			drain(res)
+		}
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// NewBaseTransport returns the network transport NewTransport uses when
+// opts.Base is unset: http.DefaultTransport, cloned to apply AttemptTimeout.
+// Wrappers such as recorders go between it and the Transport.
+func NewBaseTransport(opts Options) http.RoundTripper {
+	tr, _ := NewNetworkTransport(opts, Network{})
+	return tr
+}
+
+// backoff is the delay before retry attempt+1: exponential, capped, with
+// equal jitter so concurrent clients don't retry in lockstep.
+func (t *Transport) backoff(attempt int) time.Duration {
+	d := t.baseDelay << attempt
+	if d <= 0 || d > t.maxDelay {
+		d = t.maxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// Idempotent reports whether req may be sent again: safe methods, PUT and
+// DELETE, or any request carrying an Idempotency-Key.
+func Idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func retryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var ne net.Error
+	if errors.As(err, &ne) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// RetryAfter reads how long the server asks to wait: Retry-After (seconds or
+// an HTTP date), else ratelimit-reset (seconds, or a Unix time).
+func RetryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return time.Duration(n) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(0, t.Sub(now)), true
+		}
+	}
+	if v := strings.TrimSpace(h.Get("ratelimit-reset")); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n >= 0 {
+			// Values this large are timestamps, not a delta.
+			if n > 1_000_000_000 {
+				return max(0, time.Unix(n, 0).Sub(now)), true
+			}
+			return time.Duration(n) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+// drain discards a response that is about to be retried so its connection
+// can be reused.
+func drain(res *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	_ = res.Body.Close()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
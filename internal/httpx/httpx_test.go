@@ -0,0 +1,237 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testClient returns a client whose sleeps are recorded instead of waited.
+func testClient(opts Options) (*http.Client, *[]time.Duration) {
+	var slept []time.Duration
+	if opts.Rate == 0 {
+		opts.Rate = -1
+	}
+	tr := NewTransport(opts)
+	tr.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return ctx.Err()
+	}
+	return &http.Client{Transport: tr}, &slept
+}
+
+func TestTransport_RetriesGETOn5xx(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(srv.Close)
+
+	c, slept := testClient(Options{BaseDelay: 100 * time.Millisecond})
+	res, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 || calls.Load() != 3 {
+		t.Fatalf("status=%d calls=%d", res.StatusCode, calls.Load())
+	}
+	if len(*slept) != 2 {
+		t.Fatalf("slept %v", *slept)
+	}
+	// Equal jitter: half the exponential delay plus up to the other half.
+	for i, d := range *slept {
+		full := 100 * time.Millisecond << i
+		if d < full/2 || d > full {
+			t.Fatalf("sleep %d = %s, want [%s, %s]", i, d, full/2, full)
+		}
+	}
+}
+
+func TestTransport_GivesUpAfterRetries(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, _ := testClient(Options{Retries: 2})
+	res, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable || calls.Load() != 3 {
+		t.Fatalf("status=%d calls=%d", res.StatusCode, calls.Load())
+	}
+}
+
+func TestTransport_DoesNotRetryPOST(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, slept := testClient(Options{})
+	res, err := c.Post(srv.URL, "text/plain", strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	res.Body.Close()
+	if calls.Load() != 1 || len(*slept) != 0 {
+		t.Fatalf("calls=%d slept=%v", calls.Load(), *slept)
+	}
+
+	// An Idempotency-Key makes it safe, and the body is sent again.
+	calls.Store(0)
+	var bodies []string
+	srv2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv2.Close)
+	req, _ := http.NewRequest(http.MethodPost, srv2.URL, strings.NewReader("payload"))
+	req.Header.Set("Idempotency-Key", "k")
+	res, err = c.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 200 || strings.Join(bodies, ",") != "payload,payload" {
+		t.Fatalf("status=%d bodies=%v", res.StatusCode, bodies)
+	}
+}
+
+func TestTransport_HonorsRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.Header().Set("ratelimit-reset", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c, slept := testClient(Options{})
+	res, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 200 || len(*slept) != 2 || (*slept)[0] != 7*time.Second || (*slept)[1] != 3*time.Second {
+		t.Fatalf("status=%d slept=%v", res.StatusCode, *slept)
+	}
+}
+
+func TestTransport_LongRetryAfterReturnsResponse(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, _ := testClient(Options{})
+	res, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Fatalf("status=%d calls=%d", res.StatusCode, calls.Load())
+	}
+}
+
+type failingTransport struct{ calls int }
+
+func (f *failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	f.calls++
+	return nil, io.ErrUnexpectedEOF
+}
+
+func TestTransport_RetriesNetworkErrors(t *testing.T) {
+	t.Parallel()
+
+	base := &failingTransport{}
+	c, slept := testClient(Options{Base: base, Retries: 2})
+	_, err := c.Get("http://example.invalid/")
+	if !errors.Is(err, io.ErrUnexpectedEOF) || base.calls != 3 || len(*slept) != 2 {
+		t.Fatalf("err=%v calls=%d slept=%v", err, base.calls, *slept)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		name, key, value string
+		want             time.Duration
+		ok               bool
+	}{
+		{"seconds", "Retry-After", "12", 12 * time.Second, true},
+		{"date", "Retry-After", now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{"reset delta", "Ratelimit-Reset", "30", 30 * time.Second, true},
+		{"reset timestamp", "Ratelimit-Reset", "1735787105", 60 * time.Second, true},
+		{"garbage", "Retry-After", "soon", 0, false},
+		{"none", "X-Other", "1", 0, false},
+	} {
+		h := http.Header{}
+		h.Set(tc.key, tc.value)
+		got, ok := RetryAfter(h, now)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("%s: got %s,%v want %s,%v", tc.name, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestHostLimits(t *testing.T) {
+	l := newHostLimits(2, 2)
+	now := time.Unix(0, 0)
+	if d := l.reserve("a", now); d != 0 {
+		t.Fatalf("first: %s", d)
+	}
+	if d := l.reserve("a", now); d != 0 {
+		t.Fatalf("burst: %s", d)
+	}
+	if d := l.reserve("a", now); d != 500*time.Millisecond {
+		t.Fatalf("third: %s", d)
+	}
+	if d := l.reserve("b", now); d != 0 {
+		t.Fatalf("other host: %s", d)
+	}
+	// One second refills two tokens; one pays the debt.
+	if d := l.reserve("a", now.Add(time.Second)); d != 0 {
+		t.Fatalf("after refill: %s", d)
+	}
+}
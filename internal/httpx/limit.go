@@ -0,0 +1,54 @@
+package httpx
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hostLimits is a token bucket per host.
+type hostLimits struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newHostLimits(rate float64, burst int) *hostLimits {
+	return &hostLimits{rate: rate, burst: float64(burst), buckets: map[string]*bucket{}}
+}
+
+// wait takes a token for host, sleeping until one is available.
+func (l *hostLimits) wait(ctx context.Context, host string, now func() time.Time, sleep func(context.Context, time.Duration) error) error {
+	if d := l.reserve(host, now()); d > 0 {
+		return sleep(ctx, d)
+	}
+	return nil
+}
+
+// reserve takes a token (possibly going into debt) and returns how long the
+// caller has to wait for it.
+func (l *hostLimits) reserve(host string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.buckets[host]
+	if b == nil {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[host] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(l.burst, b.tokens+elapsed.Seconds()*l.rate)
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / l.rate * float64(time.Second))
+}
@@ -0,0 +1,135 @@
+package httpx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/steipete/ordercli/internal/redact"
+)
+
+// TraceBodyLimit is how much of a request or response body a trace shows.
+const TraceBodyLimit = 4096
+
+// Tracer is an http.RoundTripper that logs every exchange it forwards: method,
+// URL, status, timing, headers and bodies, with secrets redacted by the same
+// rules as provider HTTP errors (see internal/redact). Put it below Transport
+// to see each retry attempt.
+type Tracer struct {
+	base http.RoundTripper
+	*traceLog
+}
+
+// traceLog is the writer a Tracer and its With copies share.
+type traceLog struct {
+	mu sync.Mutex
+	w  io.Writer
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// NewTracer traces to w. A nil base uses NewBaseTransport(Options{}).
+func NewTracer(w io.Writer, base http.RoundTripper) *Tracer {
+	if base == nil {
+		base = NewBaseTransport(Options{})
+	}
+	return &Tracer{base: base, traceLog: &traceLog{w: w, now: time.Now}}
+}
+
+// With returns a Tracer forwarding to base that logs to the same writer
+// without interleaving with t.
+func (t *Tracer) With(base http.RoundTripper) *Tracer {
+	return &Tracer{base: base, traceLog: t.traceLog}
+}
+
+func (t *Tracer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = b
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	start := t.now()
+	res, err := t.base.RoundTrip(req)
+	elapsed := t.now().Sub(start).Round(time.Millisecond)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--> %s %s\n", req.Method, redact.URL(req.URL.String()))
+	writeHeader(&buf, req.Header)
+	writeBody(&buf, reqBody)
+	if err != nil {
+		fmt.Fprintf(&buf, "<-- error (%s): %v\n\n", elapsed, err)
+		t.write(buf.Bytes())
+		return nil, err
+	}
+
+	resBody, readErr := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	fmt.Fprintf(&buf, "<-- %s %s %s (%s)\n", res.Status, req.Method, redact.URL(req.URL.String()), elapsed)
+	writeHeader(&buf, res.Header)
+	writeBody(&buf, resBody)
+	if readErr != nil {
+		fmt.Fprintf(&buf, "(body read error: %v)\n", readErr)
+	}
+	buf.WriteByte('\n')
+	t.write(buf.Bytes())
+	if readErr != nil {
+		return nil, readErr
+	}
+	return res, nil
+}
+
+// write emits one exchange at a time so concurrent requests don't interleave.
+func (t *traceLog) write(b []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, _ = t.w.Write(b)
+}
+
+func writeHeader(w io.Writer, h http.Header) {
+	h = redact.Header(h)
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			fmt.Fprintf(w, "%s: %s\n", k, v)
+		}
+	}
+}
+
+func writeBody(w io.Writer, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	if !utf8.Valid(b) {
+		fmt.Fprintf(w, "\n(%d bytes binary)\n", len(b))
+		return
+	}
+	body := redact.Body(b)
+	if len(body) > TraceBodyLimit {
+		cut := TraceBodyLimit
+		for cut > 0 && !utf8.RuneStart(body[cut]) {
+			cut--
+		}
+		body = fmt.Sprintf("%s… (%d bytes)", body[:cut], len(b))
+	}
+	fmt.Fprintf(w, "\n%s\n", strings.TrimRight(body, "\n"))
+}
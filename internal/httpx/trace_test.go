@@ -0,0 +1,83 @@
+package httpx
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracer_RedactsAndTimes(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "__cf_bm=cfSECRET")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `{"code":"mfa_triggered","metadata":{"more_information":{"mfa_token":"mfaSECRET"}},"pad":"`+strings.Repeat("x", 2*TraceBodyLimit)+`"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	var log bytes.Buffer
+	tr := NewTracer(&log, http.DefaultTransport)
+	clock := time.Unix(0, 0)
+	tr.now = func() time.Time {
+		clock = clock.Add(125 * time.Millisecond)
+		return clock
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/oauth2/token?key=AIzaSECRET", strings.NewReader("username=me&password=pwSECRET&grant_type=password"))
+	req.Header.Set("Authorization", "Bearer tokSECRET")
+	req.Header.Set("User-Agent", "Android-app-24.1.0(240100)")
+	res, err := (&http.Client{Transport: tr}).Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "mfaSECRET") {
+		t.Fatalf("tracer changed the response: %.100s", body)
+	}
+
+	got := log.String()
+	if strings.Contains(got, "SECRET") {
+		t.Fatalf("trace leaks a secret:\n%s", got)
+	}
+	for _, want := range []string{
+		"--> POST " + srv.URL + "/oauth2/token?key=***\n",
+		"Authorization: ***\n",
+		"User-Agent: Android-app-24.1.0(240100)\n",
+		"username=***&password=***&grant_type=password\n",
+		"<-- 403 Forbidden POST ",
+		"(125ms)\n",
+		"Set-Cookie: ***\n",
+		`"mfa_token":"***"`,
+		"… (",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("trace lacks %q:\n%.2000s", want, got)
+		}
+	}
+	if len(got) > 3*TraceBodyLimit {
+		t.Fatalf("body not truncated: %d bytes", len(got))
+	}
+}
+
+func TestTracer_LogsNetworkErrors(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	var log bytes.Buffer
+	_, err := (&http.Client{Transport: NewTracer(&log, http.DefaultTransport)}).Get(url + "/x")
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(log.String(), "--> GET "+url+"/x\n") || !strings.Contains(log.String(), "<-- error (") {
+		t.Fatalf("trace:\n%s", log.String())
+	}
+}
@@ -0,0 +1,45 @@
+package provider
+
+import "context"
+
+// WithAccount wraps p so its orders carry the named account. Name becomes
+// "<provider>/<account>".
+func WithAccount(p Provider, account string) Provider {
+	if account == "" {
+		return p
+	}
+	return accountProvider{Provider: p, account: account}
+}
+
+type accountProvider struct {
+	Provider
+	account string
+}
+
+func (a accountProvider) Name() string { return a.Provider.Name() + "/" + a.account }
+
+func (a accountProvider) ActiveOrders(ctx context.Context) ([]Order, error) {
+	orders, err := a.Provider.ActiveOrders(ctx)
+	return a.stamp(orders), err
+}
+
+func (a accountProvider) History(ctx context.Context, req HistoryRequest) (HistoryPage, error) {
+	page, err := a.Provider.History(ctx, req)
+	page.Orders = a.stamp(page.Orders)
+	return page, err
+}
+
+func (a accountProvider) OrderDetail(ctx context.Context, id string) (Order, error) {
+	o, err := a.Provider.OrderDetail(ctx, id)
+	if err == nil {
+		o.Account = a.account
+	}
+	return o, err
+}
+
+func (a accountProvider) stamp(orders []Order) []Order {
+	for i := range orders {
+		orders[i].Account = a.account
+	}
+	return orders
+}
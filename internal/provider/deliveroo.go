@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/steipete/ordercli/internal/deliveroo"
+)
+
+var DeliverooCapabilities = Capabilities{ActiveOrders: true, History: true}
+
+type deliverooProvider struct {
+	c *deliveroo.Client
+}
+
+func NewDeliveroo(c *deliveroo.Client) Provider {
+	return &deliverooProvider{c: c}
+}
+
+func (p *deliverooProvider) Name() string { return "deliveroo" }
+
+func (p *deliverooProvider) Capabilities() Capabilities { return DeliverooCapabilities }
+
+func (p *deliverooProvider) ActiveOrders(ctx context.Context) ([]Order, error) {
+	resp, err := p.c.OrderHistory(ctx, deliveroo.OrderHistoryParams{State: "active"})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Order, 0, len(resp.Orders))
+	for _, o := range resp.Orders {
+		out = append(out, DeliverooOrder(o))
+	}
+	return out, nil
+}
+
+func (p *deliverooProvider) History(ctx context.Context, req HistoryRequest) (HistoryPage, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	resp, err := p.c.OrderHistory(ctx, deliveroo.OrderHistoryParams{Offset: req.Offset, Limit: limit})
+	if err != nil {
+		return HistoryPage{}, err
+	}
+	page := HistoryPage{Orders: make([]Order, 0, len(resp.Orders))}
+	for _, o := range resp.Orders {
+		page.Orders = append(page.Orders, DeliverooOrder(o))
+	}
+	page.More = len(resp.Orders) == limit
+	if resp.Count > 0 {
+		page.More = req.Offset+len(resp.Orders) < resp.Count
+	}
+	return page, nil
+}
+
+func (p *deliverooProvider) OrderDetail(ctx context.Context, id string) (Order, error) {
+	return Order{}, unsupported(p, "order detail")
+}
+
+func (p *deliverooProvider) Profile(ctx context.Context) (Profile, error) {
+	return Profile{}, unsupported(p, "profile")
+}
+
+// DeliverooOrder normalizes an order-history entry.
+func DeliverooOrder(d deliveroo.Order) Order {
+	o := Order{
+		Provider:    "deliveroo",
+		ID:          d.ID,
+		Status:      d.Status,
+		PlacedAt:    parseTime(d.SubmittedAt),
+		DeliveredAt: parseTime(d.DeliveredAt),
+		EstimatedAt: parseTime(d.EstimatedDeliveryAt),
+		Currency:    strings.ToUpper(d.CurrencyCode),
+		Raw:         rawJSON(d),
+	}
+	if o.ID == "" {
+		o.ID = d.OrderNumber
+	}
+	if d.Restaurant != nil {
+		o.Vendor = d.Restaurant.Name
+	}
+	if d.Total != nil {
+		o.Total = *d.Total
+	}
+	if o.Currency == "" {
+		o.Currency = CurrencyForSymbol(d.CurrencySymbol)
+	}
+	return o
+}
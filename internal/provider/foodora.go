@@ -0,0 +1,296 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/steipete/ordercli/internal/foodora"
+)
+
+var FoodoraCapabilities = Capabilities{ActiveOrders: true, History: true, OrderDetail: true}
+
+type foodoraProvider struct {
+	c        *foodora.Client
+	currency string
+}
+
+// NewFoodora wraps an authenticated fd-api client. fd-api amounts carry no
+// currency, so the caller passes the one for the configured country.
+func NewFoodora(c *foodora.Client, currency string) Provider {
+	return &foodoraProvider{c: c, currency: currency}
+}
+
+func (p *foodoraProvider) Name() string { return "foodora" }
+
+func (p *foodoraProvider) Capabilities() Capabilities { return FoodoraCapabilities }
+
+func (p *foodoraProvider) ActiveOrders(ctx context.Context) ([]Order, error) {
+	resp, err := p.c.ActiveOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Order, 0, len(resp.Data.ActiveOrders))
+	for _, a := range resp.Data.ActiveOrders {
+		out = append(out, FoodoraActiveOrder(a, p.currency))
+	}
+	return out, nil
+}
+
+func (p *foodoraProvider) History(ctx context.Context, req HistoryRequest) (HistoryPage, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	resp, err := p.c.OrderHistory(ctx, foodora.OrderHistoryRequest{
+		Offset: req.Offset,
+		Limit:  min(limit, 100),
+	})
+	if err != nil {
+		return HistoryPage{}, err
+	}
+
+	page := HistoryPage{Orders: make([]Order, 0, len(resp.Data.Items))}
+	for _, it := range resp.Data.Items {
+		page.Orders = append(page.Orders, FoodoraHistoryOrder(it, p.currency))
+	}
+	next := req.Offset + len(resp.Data.Items)
+	page.More = len(resp.Data.Items) == min(limit, 100)
+	if resp.Data.TotalCount > 0 {
+		page.More = next < int(resp.Data.TotalCount)
+	}
+	return page, nil
+}
+
+func (p *foodoraProvider) OrderDetail(ctx context.Context, id string) (Order, error) {
+	resp, err := p.c.OrderHistoryByCode(ctx, foodora.OrderHistoryByCodeRequest{OrderCode: id})
+	if err != nil {
+		return Order{}, err
+	}
+	if len(resp.Data.Items) == 0 {
+		return Order{}, errors.New("no order found")
+	}
+	return FoodoraDetailOrder(resp.Data.Items[0], p.currency), nil
+}
+
+func (p *foodoraProvider) Profile(ctx context.Context) (Profile, error) {
+	return Profile{}, unsupported(p, "profile")
+}
+
+// FoodoraActiveOrder normalizes one tracking/active-orders entry.
+func FoodoraActiveOrder(a foodora.ActiveOrder, currency string) Order {
+	status := a.Status.Subtitle
+	if status == "" && len(a.Status.Titles) > 0 {
+		status = a.Status.Titles[0].Name
+	}
+	return Order{
+		Provider: "foodora",
+		ID:       a.Code,
+		Vendor:   a.Vendor.Name,
+		Status:   status,
+		Currency: currency,
+		Raw:      rawJSON(a),
+	}
+}
+
+// FoodoraHistoryOrder normalizes one orders/order_history list item.
+func FoodoraHistoryOrder(it foodora.OrderHistoryItem, currency string) Order {
+	o := Order{
+		Provider: "foodora",
+		ID:       it.OrderCode,
+		Total:    it.TotalValue,
+		Currency: currency,
+		Raw:      rawJSON(it),
+	}
+	if it.Vendor != nil {
+		o.Vendor = it.Vendor.Name
+	}
+	if s := it.CurrentStatus; s != nil {
+		switch {
+		case s.Message != "":
+			o.Status = s.Message
+		case s.Code != "":
+			o.Status = string(s.Code)
+		default:
+			o.Status = string(s.InternalStatusCode)
+		}
+	}
+	if it.ConfirmedDeliveryTime != nil {
+		o.DeliveredAt = it.ConfirmedDeliveryTime.Date.Time
+	}
+	return o
+}
+
+// FoodoraDetailOrder normalizes an order_history?order_code=... item (a loose map).
+func FoodoraDetailOrder(item map[string]any, currency string) Order {
+	o := Order{
+		Provider: "foodora",
+		ID:       str(item["order_code"]),
+		Vendor:   str(dig(item, "vendor", "name")),
+		Status:   str(dig(item, "current_status", "message")),
+		Currency: currency,
+		Raw:      rawJSON(item),
+	}
+	if o.Status == "" {
+		o.Status = str(dig(item, "current_status", "code"))
+	}
+	if v, ok := num(item["total_value"]); ok {
+		o.Total = v
+	} else if v, ok := num(dig(item, "payment", "total_value")); ok {
+		o.Total = v
+	}
+	o.DeliveredAt = parseTime(str(dig(item, "confirmed_delivery_time", "date")))
+	if v, ok := foodoraAmount(item, "subtotal"); ok {
+		o.Subtotal = v
+	}
+	for _, f := range foodoraFees {
+		for _, k := range f.keys {
+			if v, ok := foodoraAmount(item, k); ok && v != 0 {
+				o.Fees = append(o.Fees, Fee{Kind: f.kind, Amount: f.sign * v})
+				break
+			}
+		}
+	}
+	for _, k := range []string{"total_vat", "vat_total", "vat_amount", "vat"} {
+		if v, ok := foodoraAmount(item, k); ok && v != 0 {
+			o.VAT = v
+			break
+		}
+	}
+	o.Payment = foodoraPayment(item)
+	o.Address = RedactAddress(str(item["order_address"]))
+	if o.Address == nil {
+		o.Address = RedactAddress(str(dig(item, "delivery_address", "formatted_address")))
+	}
+
+	products, _ := item["order_products"].([]any)
+	for _, raw := range products {
+		m, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		it := Item{
+			Name:      str(m["name"]),
+			Variation: str(m["variation_name"]),
+			Notes:     str(m["special_instructions"]),
+		}
+		if it.Name == "" {
+			it.Name = str(m["title"])
+		}
+		toppings, _ := m["toppings"].([]any)
+		for _, t := range toppings {
+			tm, ok := t.(map[string]any)
+			if !ok {
+				continue
+			}
+			if name := str(tm["name"]); name != "" {
+				it.Toppings = append(it.Toppings, name)
+			}
+		}
+		if q, ok := num(m["quantity"]); ok {
+			it.Quantity = int(q)
+		}
+		for _, k := range []string{"total_price", "total_value", "price"} {
+			if v, ok := num(m[k]); ok {
+				it.Total = v
+				break
+			}
+		}
+		if it.Name != "" {
+			o.Items = append(o.Items, it)
+		}
+	}
+	return o
+}
+
+// foodoraFees maps fee kinds to the order_history detail keys seen for them.
+// Keys are looked up at the top level and under "payment".
+var foodoraFees = []struct {
+	kind string
+	sign float64
+	keys []string
+}{
+	{FeeDelivery, 1, []string{"delivery_fee"}},
+	{FeeService, 1, []string{"service_fee", "service_fee_total"}},
+	{FeeSmallOrder, 1, []string{"difference_to_minimum", "minimum_order_value_fee"}},
+	{FeeContainer, 1, []string{"container_charges", "container_charge"}},
+	{FeeTip, 1, []string{"rider_tip", "tip"}},
+	{FeeDiscount, -1, []string{"discount", "voucher_value", "total_discount"}},
+}
+
+// foodoraPayment returns the payment method title (e.g. "PayPal"), falling
+// back to the type code.
+func foodoraPayment(item map[string]any) string {
+	for _, v := range []any{
+		item["payment_type"], item["payment_method"], dig(item, "payment", "payment_type"), dig(item, "payment", "method"),
+		item["payment_type_code"], dig(item, "payment", "payment_type_code"),
+	} {
+		if m, ok := v.(map[string]any); ok {
+			for _, k := range []string{"title", "name", "code"} {
+				if s := str(m[k]); s != "" {
+					return s
+				}
+			}
+			continue
+		}
+		if s := str(v); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// foodoraAmount reads a money value that may be a number, a numeric string or
+// an {"amount": ...} object.
+func foodoraAmount(item map[string]any, key string) (float64, bool) {
+	for _, v := range []any{item[key], dig(item, "payment", key)} {
+		if m, ok := v.(map[string]any); ok {
+			v = m["amount"]
+		}
+		if f, ok := num(v); ok {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+func dig(m map[string]any, keys ...string) any {
+	var cur any = m
+	for _, k := range keys {
+		mm, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = mm[k]
+	}
+	return cur
+}
+
+func str(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
+
+func num(v any) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/steipete/ordercli/internal/glovo"
+)
+
+var GlovoCapabilities = Capabilities{ActiveOrders: true, History: true, OrderDetail: true, Profile: true}
+
+type glovoProvider struct {
+	c *glovo.Client
+}
+
+func NewGlovo(c *glovo.Client) Provider {
+	return &glovoProvider{c: c}
+}
+
+func (p *glovoProvider) Name() string { return "glovo" }
+
+func (p *glovoProvider) Capabilities() Capabilities { return GlovoCapabilities }
+
+func (p *glovoProvider) ActiveOrders(ctx context.Context) ([]Order, error) {
+	orders, err := p.c.ActiveOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Order, 0, len(orders))
+	for _, o := range orders {
+		out = append(out, GlovoOrder(o))
+	}
+	return out, nil
+}
+
+func (p *glovoProvider) History(ctx context.Context, req HistoryRequest) (HistoryPage, error) {
+	resp, err := p.c.OrderHistory(ctx, req.Offset, req.Limit)
+	if err != nil {
+		return HistoryPage{}, err
+	}
+	page := HistoryPage{
+		Orders: make([]Order, 0, len(resp.Orders)),
+		More:   resp.Pagination.Next != nil && *resp.Pagination.Next != "",
+	}
+	for _, o := range resp.Orders {
+		page.Orders = append(page.Orders, GlovoOrder(o))
+	}
+	return page, nil
+}
+
+func (p *glovoProvider) OrderDetail(ctx context.Context, id string) (Order, error) {
+	orderID, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return Order{}, fmt.Errorf("invalid order ID: %s", id)
+	}
+	o, err := p.c.GetOrder(ctx, orderID)
+	if err != nil {
+		return Order{}, err
+	}
+	return GlovoOrder(o), nil
+}
+
+func (p *glovoProvider) Profile(ctx context.Context) (Profile, error) {
+	u, err := p.c.Me(ctx)
+	if err != nil {
+		return Profile{}, err
+	}
+	return Profile{ID: strconv.Itoa(u.ID), Name: u.Name, Email: u.Email}, nil
+}
+
+// GlovoOrder normalizes an orders-list card. Glovo cards carry no timestamps;
+// items come from the free-form body lines.
+func GlovoOrder(g glovo.Order) Order {
+	o := Order{
+		Provider: "glovo",
+		ID:       strconv.Itoa(g.OrderID),
+		Vendor:   g.Content.Title,
+		Status:   g.LayoutType,
+		Raw:      rawJSON(g),
+	}
+	if g.Footer.Left != nil {
+		o.Total, o.Currency, _ = ParsePrice(g.Footer.Left.DataString())
+	}
+	for _, b := range g.Content.Body {
+		for _, line := range strings.Split(b.Data, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				o.Items = append(o.Items, GlovoItem(line))
+			}
+		}
+	}
+	return o
+}
+
+// GlovoItem parses body lines like "2 x Pad Thai".
+func GlovoItem(line string) Item {
+	qty, name, ok := strings.Cut(line, " x ")
+	if ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(qty)); err == nil {
+			return Item{Name: strings.TrimSpace(name), Quantity: n}
+		}
+	}
+	return Item{Name: line}
+}
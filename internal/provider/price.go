@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steipete/ordercli/internal/foodora"
+)
+
+var currencySymbols = map[string]string{
+	"€":   "EUR",
+	"£":   "GBP",
+	"$":   "USD",
+	"zł":  "PLN",
+	"Ft":  "HUF",
+	"Kč":  "CZK",
+	"lei": "RON",
+}
+
+// CurrencyForSymbol maps a display symbol ("€", "Ft") to an ISO code; codes pass through.
+func CurrencyForSymbol(sym string) string {
+	sym = strings.TrimSpace(sym)
+	if sym == "" {
+		return ""
+	}
+	if c, ok := currencySymbols[sym]; ok {
+		return c
+	}
+	return strings.ToUpper(sym)
+}
+
+// zeroDecimal are currencies whose prices carry no minor unit in practice, so
+// "1.234 Ft" is 1234, not 1.234.
+var zeroDecimal = map[string]bool{"HUF": true, "JPY": true, "KRW": true, "ISK": true, "CLP": true}
+
+// ParsePrice parses display prices like "10,00 EUR", "€10.50", "1.234,50 €"
+// or "1 234,50 zł" (spaces, NBSP and narrow NBSP group thousands).
+func ParsePrice(s string) (float64, string, bool) {
+	s = strings.NewReplacer("\u00a0", " ", "\u202f", " ").Replace(s)
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, "", false
+	}
+
+	isDigit := func(b byte) bool { return b >= '0' && b <= '9' }
+	start := strings.IndexFunc(s, func(r rune) bool { return r >= '0' && r <= '9' })
+	if start < 0 {
+		return 0, "", false
+	}
+	end := start
+	for end < len(s) {
+		c := s[end]
+		// A space belongs to the number only between digits ("1 234").
+		if isDigit(c) || c == '.' || c == ',' || (c == ' ' && isDigit(s[end-1]) && end+1 < len(s) && isDigit(s[end+1])) {
+			end++
+			continue
+		}
+		break
+	}
+	num := strings.ReplaceAll(s[start:end], " ", "")
+	cur := CurrencyForSymbol(strings.TrimSpace(s[:start]) + strings.TrimSpace(s[end:]))
+
+	// Whichever separator comes last is the decimal separator, unless it
+	// repeats (then it groups thousands, e.g. "12.345.678 Ft") or the
+	// currency has no decimals and three digits follow it ("1.234 Ft").
+	lastDot, lastComma := strings.LastIndex(num, "."), strings.LastIndex(num, ",")
+	last := max(lastDot, lastComma)
+	switch {
+	case strings.Count(num, ".") > 1 && lastComma < 0:
+		num = strings.ReplaceAll(num, ".", "")
+	case strings.Count(num, ",") > 1 && lastDot < 0:
+		num = strings.ReplaceAll(num, ",", "")
+	case zeroDecimal[cur] && last >= 0 && len(num)-last-1 == 3:
+		num = strings.NewReplacer(".", "", ",", "").Replace(num)
+	case lastComma > lastDot:
+		num = strings.ReplaceAll(num, ".", "")
+		num = strings.Replace(num, ",", ".", 1)
+	case lastDot > lastComma:
+		num = strings.ReplaceAll(num, ",", "")
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return v, cur, true
+}
+
+// parseTime accepts the formats foodora.FlexibleTime does; anything else is zero.
+func parseTime(s string) time.Time {
+	if strings.TrimSpace(s) == "" {
+		return time.Time{}
+	}
+	t, err := foodora.ParseTime(s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
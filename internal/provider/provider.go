@@ -0,0 +1,167 @@
+// Package provider adapts the individual delivery clients (foodora, deliveroo,
+// glovo) to one interface and one normalized order model.
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrUnsupported is returned by adapters for operations the upstream API lacks.
+var ErrUnsupported = errors.New("not supported by this provider")
+
+// Capabilities advertises which Provider methods are backed by a real API.
+type Capabilities struct {
+	ActiveOrders bool
+	History      bool
+	OrderDetail  bool
+	Profile      bool
+}
+
+// Provider is the common surface of all delivery clients.
+type Provider interface {
+	Name() string
+	Capabilities() Capabilities
+	ActiveOrders(ctx context.Context) ([]Order, error)
+	History(ctx context.Context, req HistoryRequest) (HistoryPage, error)
+	OrderDetail(ctx context.Context, id string) (Order, error)
+	Profile(ctx context.Context) (Profile, error)
+}
+
+// Order is the provider-neutral order shape.
+type Order struct {
+	Provider string `json:"provider"`
+	// Account is the named account the order came from ("" for the default).
+	Account     string    `json:"account,omitempty"`
+	ID          string    `json:"id"`
+	Vendor      string    `json:"vendor,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	PlacedAt    time.Time `json:"placed_at,omitzero"`
+	DeliveredAt time.Time `json:"delivered_at,omitzero"`
+	// EstimatedAt is the expected delivery time of orders still underway.
+	EstimatedAt time.Time `json:"estimated_delivery_at,omitzero"`
+	Total       float64   `json:"total,omitempty"`
+	Subtotal    float64   `json:"subtotal,omitempty"`
+	Currency    string    `json:"currency,omitempty"`
+	Items       []Item    `json:"items,omitempty"`
+	Fees        []Fee     `json:"fees,omitempty"`
+	VAT         float64   `json:"vat,omitempty"`
+	Payment     string    `json:"payment_method,omitempty"`
+	Address     *Address  `json:"address,omitempty"`
+
+	// Raw is the upstream payload the order was built from.
+	Raw json.RawMessage `json:"-"`
+}
+
+// Item is a single ordered line. History lists only carry items when the
+// upstream list does (glovo); otherwise they come from OrderDetail.
+type Item struct {
+	Name      string   `json:"name"`
+	Variation string   `json:"variation,omitempty"`
+	Quantity  int      `json:"quantity,omitempty"`
+	Total     float64  `json:"total,omitempty"`
+	Toppings  []string `json:"toppings,omitempty"`
+	Notes     string   `json:"notes,omitempty"`
+}
+
+// When returns the best timestamp for ordering (delivery, then placement).
+func (o Order) When() time.Time {
+	if !o.DeliveredAt.IsZero() {
+		return o.DeliveredAt
+	}
+	return o.PlacedAt
+}
+
+// Cancelled reports whether the status says the order was cancelled or failed.
+// Providers word it differently ("CANCELLED", "Order canceled", "failed"), so
+// this matches on the word stems.
+func (o Order) Cancelled() bool {
+	s := strings.ToLower(o.Status)
+	return strings.Contains(s, "cancel") || strings.Contains(s, "fail")
+}
+
+// Key identifies an order across providers.
+func (o Order) Key() string { return o.Provider + ":" + o.ID }
+
+// Source is the provider name, suffixed with "/<account>" for named accounts.
+func (o Order) Source() string {
+	if o.Account == "" {
+		return o.Provider
+	}
+	return o.Provider + "/" + o.Account
+}
+
+// Profile is the signed-in customer.
+type Profile struct {
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type HistoryRequest struct {
+	Offset int
+	Limit  int
+}
+
+type HistoryPage struct {
+	Orders []Order
+	// More reports whether a request at Offset+len(Orders) may return more.
+	More bool
+}
+
+// FetchHistory pages through p until limit orders are collected or the history ends.
+func FetchHistory(ctx context.Context, p Provider, limit, pageSize int) ([]Order, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	var out []Order
+	for len(out) < limit {
+		req := HistoryRequest{Offset: len(out), Limit: min(pageSize, limit-len(out))}
+		page, err := p.History(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range page.Orders {
+			if len(out) >= limit {
+				break
+			}
+			out = append(out, o)
+		}
+		if !page.More || len(page.Orders) == 0 {
+			break
+		}
+	}
+	return out, nil
+}
+
+// SortNewestFirst orders by When(); orders without any timestamp go last.
+func SortNewestFirst(orders []Order) {
+	sort.SliceStable(orders, func(i, j int) bool {
+		ti, tj := orders[i].When(), orders[j].When()
+		if ti.IsZero() != tj.IsZero() {
+			return !ti.IsZero()
+		}
+		return ti.After(tj)
+	})
+}
+
+func rawJSON(v any) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func unsupported(p Provider, op string) error {
+	return fmt.Errorf("%s: %s: %w", p.Name(), op, ErrUnsupported)
+}
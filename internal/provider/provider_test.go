@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/deliveroo"
+	"github.com/steipete/ordercli/internal/glovo"
+)
+
+func TestParsePrice(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		in   string
+		want float64
+		cur  string
+	}{
+		{"10,00 EUR", 10, "EUR"},
+		{"€10.50", 10.5, "EUR"},
+		{"1.234,50 €", 1234.5, "EUR"},
+		{"£1,234.50", 1234.5, "GBP"},
+		{"12.345.678 Ft", 12345678, "HUF"},
+		// PLN: spaces, NBSP and narrow NBSP group thousands.
+		{"1 234,50 zł", 1234.5, "PLN"},
+		{"1\u00a0234,50\u00a0zł", 1234.5, "PLN"},
+		{"12\u202f345,00 zł", 12345, "PLN"},
+		{"34,99 zł", 34.99, "PLN"},
+		// HUF has no decimals: one separator before three digits groups.
+		{"1.234 Ft", 1234, "HUF"},
+		{"1,234 Ft", 1234, "HUF"},
+		{"1 234 Ft", 1234, "HUF"},
+		{"12\u00a0990 Ft", 12990, "HUF"},
+		{"990 Ft", 990, "HUF"},
+		// CZK groups with spaces like PLN.
+		{"1 234,50 Kč", 1234.5, "CZK"},
+		{"1\u00a0234 Kč", 1234, "CZK"},
+		{"129,90 Kč", 129.9, "CZK"},
+		// A separator before three digits stays decimal for other currencies.
+		{"1,234 €", 1.234, "EUR"},
+	}
+	for _, tc := range cases {
+		got, cur, ok := ParsePrice(tc.in)
+		if !ok || got != tc.want || cur != tc.cur {
+			t.Fatalf("%q: got %v %q ok=%v", tc.in, got, cur, ok)
+		}
+	}
+	if _, _, ok := ParsePrice("free"); ok {
+		t.Fatalf("expected no price")
+	}
+}
+
+func TestSortNewestFirst_ZeroLast(t *testing.T) {
+	t.Parallel()
+
+	t1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	orders := []Order{
+		{ID: "none"},
+		{ID: "old", PlacedAt: t1},
+		{ID: "new", DeliveredAt: t2},
+	}
+	SortNewestFirst(orders)
+	if orders[0].ID != "new" || orders[1].ID != "old" || orders[2].ID != "none" {
+		t.Fatalf("unexpected order: %#v", orders)
+	}
+}
+
+func TestFoodoraDetailOrder(t *testing.T) {
+	t.Parallel()
+
+	o := FoodoraDetailOrder(map[string]any{
+		"order_code":              "OC",
+		"vendor":                  map[string]any{"name": "V"},
+		"current_status":          map[string]any{"code": "delivered"},
+		"confirmed_delivery_time": map[string]any{"date": "2025-12-20T00:00:00Z"},
+		"total_value":             12.3,
+		"order_products": []any{
+			map[string]any{
+				"name": "Burger", "variation_name": "Large", "quantity": float64(2), "total_price": 10.0,
+				"special_instructions": "no onions",
+				"toppings":             []any{map[string]any{"name": "Bacon"}},
+			},
+		},
+	}, "EUR")
+	if o.ID != "OC" || o.Vendor != "V" || o.Status != "delivered" || o.Total != 12.3 || o.DeliveredAt.IsZero() {
+		t.Fatalf("unexpected: %#v", o)
+	}
+	if len(o.Items) != 1 {
+		t.Fatalf("unexpected items: %#v", o.Items)
+	}
+	if it := o.Items[0]; it.Quantity != 2 || it.Total != 10 || it.Variation != "Large" || it.Notes != "no onions" || len(it.Toppings) != 1 {
+		t.Fatalf("unexpected item: %#v", it)
+	}
+}
+
+func TestFoodoraDetailOrder_FeesAndAddress(t *testing.T) {
+	t.Parallel()
+
+	o := FoodoraDetailOrder(map[string]any{
+		"order_code":    "OC",
+		"subtotal":      "20.5",
+		"delivery_fee":  2.9,
+		"rider_tip":     map[string]any{"amount": 1.5},
+		"payment":       map[string]any{"voucher_value": 3.0},
+		"service_fee":   0.0,
+		"order_address": "Hauptstraße 1/2/3, 1010 Wien",
+	}, "EUR")
+	if o.Subtotal != 20.5 {
+		t.Fatalf("subtotal=%v", o.Subtotal)
+	}
+	want := []Fee{{FeeDelivery, 2.9}, {FeeTip, 1.5}, {FeeDiscount, -3}}
+	if len(o.Fees) != len(want) {
+		t.Fatalf("unexpected fees: %#v", o.Fees)
+	}
+	for i := range want {
+		if o.Fees[i] != want[i] {
+			t.Fatalf("fee %d: got %#v want %#v", i, o.Fees[i], want[i])
+		}
+	}
+	if o.Address == nil || *o.Address != (Address{Postcode: "1010", City: "Wien"}) {
+		t.Fatalf("unexpected address: %#v", o.Address)
+	}
+}
+
+func TestRedactAddress(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]*Address{
+		"10 Downing Street, London SW1A 2AA": nil,
+		"10 Downing Street, SW1A 2AA London": {Postcode: "SW1A 2AA", City: "London"},
+		"Karl-Marx-Allee 5, 10178 Berlin":    {Postcode: "10178", City: "Berlin"},
+		"Somewhere 12":                       nil,
+		"":                                   nil,
+	}
+	for in, want := range cases {
+		got := RedactAddress(in)
+		if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+			t.Fatalf("%q: got %#v want %#v", in, got, want)
+		}
+	}
+}
+
+func TestGlovoOrder_Items(t *testing.T) {
+	t.Parallel()
+
+	o := GlovoOrder(glovo.Order{
+		OrderID: 5,
+		Content: glovo.Content{Title: "S", Body: []glovo.ContentBody{{Data: "2 x Pad Thai\nWater"}}},
+		Footer:  glovo.Footer{Left: &glovo.FooterItem{Data: "9,50 €"}},
+	})
+	if o.ID != "5" || o.Total != 9.5 || o.Currency != "EUR" {
+		t.Fatalf("unexpected: %#v", o)
+	}
+	if len(o.Items) != 2 || o.Items[0].Name != "Pad Thai" || o.Items[0].Quantity != 2 || o.Items[1].Name != "Water" {
+		t.Fatalf("unexpected items: %#v", o.Items)
+	}
+}
+
+func TestFetchHistory_PagesDeliveroo(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("offset") {
+		case "0":
+			_, _ = w.Write([]byte(`{"orders":[{"id":"a","submitted_at":"2025-01-01T10:00:00Z","total":5,"currency_symbol":"£"},{"id":"b"}],"count":3}`))
+		default:
+			_, _ = w.Write([]byte(`{"orders":[{"id":"c"}],"count":3}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := deliveroo.NewClient(deliveroo.ClientOptions{BaseURL: srv.URL, BearerToken: "tok"})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	orders, err := FetchHistory(context.Background(), NewDeliveroo(c), 10, 2)
+	if err != nil {
+		t.Fatalf("FetchHistory: %v", err)
+	}
+	if len(orders) != 3 || calls != 2 {
+		t.Fatalf("orders=%d calls=%d", len(orders), calls)
+	}
+	if orders[0].Currency != "GBP" || orders[0].PlacedAt.IsZero() {
+		t.Fatalf("unexpected first order: %#v", orders[0])
+	}
+
+	if _, err := NewDeliveroo(c).OrderDetail(context.Background(), "a"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SchemaVersion is the version of the normalized order JSON (docs/order-schema.md).
+// Adding optional fields keeps the version; renaming, removing or retyping a
+// field bumps it.
+const SchemaVersion = 1
+
+// Document is the envelope of normalized JSON output. Lists fill Orders,
+// single-order commands fill Order.
+type Document struct {
+	SchemaVersion int     `json:"schema_version"`
+	Orders        []Order `json:"orders,omitzero"`
+	Order         *Order  `json:"order,omitempty"`
+}
+
+func NewListDocument(orders []Order) Document {
+	if orders == nil {
+		orders = []Order{}
+	}
+	return Document{SchemaVersion: SchemaVersion, Orders: orders}
+}
+
+func NewOrderDocument(o Order) Document {
+	return Document{SchemaVersion: SchemaVersion, Order: &o}
+}
+
+// Fee kinds. Discounts are fees with a negative amount.
+const (
+	FeeDelivery   = "delivery"
+	FeeService    = "service"
+	FeeSmallOrder = "small_order"
+	FeeContainer  = "container"
+	FeeTip        = "tip"
+	FeeDiscount   = "discount"
+)
+
+// Fee is a non-item charge on an order.
+type Fee struct {
+	Kind   string  `json:"kind"`
+	Amount float64 `json:"amount"`
+}
+
+// Address is a delivery address reduced to its non-identifying parts. Street,
+// house number, floor, names, phone numbers and coordinates are never kept.
+type Address struct {
+	Postcode string `json:"postcode,omitempty"`
+	City     string `json:"city,omitempty"`
+}
+
+// postcodeCity matches "1010 Wien" / "10115 Berlin" / "SW1A 1AA London"-style
+// tails of a one-line address.
+var postcodeCity = regexp.MustCompile(`(?i)\b(\d{4,5}|[A-Z]{1,2}\d[A-Z\d]?\s?\d[A-Z]{2})\s+([\p{L}][\p{L} .'-]*)$`)
+
+// RedactAddress keeps only postcode and city of a one-line address. It returns
+// nil when neither can be found, rather than guessing.
+func RedactAddress(s string) *Address {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i := len(parts) - 1; i >= 0; i-- {
+		m := postcodeCity.FindStringSubmatch(strings.TrimSpace(parts[i]))
+		if m == nil {
+			continue
+		}
+		return &Address{Postcode: strings.ToUpper(m[1]), City: strings.TrimSpace(m[2])}
+	}
+	return nil
+}
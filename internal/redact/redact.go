@@ -0,0 +1,164 @@
+// Package redact removes secrets from HTTP traffic before it is shown or
+// stored: error messages, recorded cassettes and traces.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces redacted values.
+const Mask = "***"
+
+// sensitiveKeys are JSON keys, form fields and query parameters whose values
+// are removed (compared lowercase).
+var sensitiveKeys = map[string]struct{}{
+	"access_token":  {},
+	"refresh_token": {},
+	"accesstoken":   {},
+	"refreshtoken":  {},
+	"id_token":      {},
+	"token":         {},
+	"client_secret": {},
+	"password":      {},
+	"username":      {},
+	"mfa_token":     {},
+	"otp":           {},
+	"x-otp":         {},
+	"address":       {},
+}
+
+// sensitiveHeaders are masked in Header (canonical names).
+var sensitiveHeaders = map[string]struct{}{
+	"Authorization":                      {},
+	"Proxy-Authorization":                {},
+	"Cookie":                             {},
+	"Set-Cookie":                         {},
+	"X-Mfa-Token":                        {},
+	"X-Otp":                              {},
+	"X-Goog-Api-Key":                     {},
+	"X-Goog-Firebase-Installations-Auth": {},
+}
+
+var sensitiveJSONValueRE = regexp.MustCompile(`(?i)("(?:access_token|refresh_token|accessToken|refreshToken|id_token|token|client_secret|password|username|mfa_token|otp)"\s*:\s*)"[^"]*"`)
+
+// Sensitive reports whether a JSON key, form field or query parameter named
+// key holds a secret.
+func Sensitive(key string) bool {
+	_, ok := sensitiveKeys[strings.ToLower(key)]
+	return ok
+}
+
+// Body redacts a JSON or form-encoded body for display; a sensitive JSON
+// value is replaced by Mask whatever its type. Anything else gets a
+// best-effort pass over JSON-looking fragments.
+func Body(b []byte) string { return body(b, false) }
+
+// BodyKeepTypes is Body for bodies that must still decode into the same
+// structs (recorded cassettes): inside a sensitive value, strings become
+// Mask and numbers 0, but objects and arrays keep their shape.
+func BodyKeepTypes(b []byte) string { return body(b, true) }
+
+func body(b []byte, keepTypes bool) string {
+	if len(b) == 0 {
+		return ""
+	}
+
+	// UseNumber keeps large IDs exact through the round trip.
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err == nil && !dec.More() {
+		v = redactAny(v, false, keepTypes)
+		if out, err := json.Marshal(v); err == nil {
+			return string(out)
+		}
+	}
+
+	s := string(b)
+	if out, ok := form(s, Sensitive); ok {
+		return out
+	}
+	// Best-effort: redact common JSON patterns in string bodies.
+	return sensitiveJSONValueRE.ReplaceAllString(s, `$1"`+Mask+`"`)
+}
+
+// redactAny returns v with sensitive values masked; masked is set below a
+// sensitive key.
+func redactAny(v any, masked, keepTypes bool) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, vv := range t {
+			if !masked && Sensitive(k) && !keepTypes {
+				t[k] = Mask
+				continue
+			}
+			t[k] = redactAny(vv, masked || Sensitive(k), keepTypes)
+		}
+	case []any:
+		for i := range t {
+			t[i] = redactAny(t[i], masked, keepTypes)
+		}
+	case string:
+		if masked {
+			return Mask
+		}
+	case json.Number:
+		if masked {
+			return json.Number("0")
+		}
+	}
+	return v
+}
+
+// form redacts s if it parses as a form body (a=b&c=d), keeping field order.
+func form(s string, sensitive func(string) bool) (string, bool) {
+	if s == "" || strings.ContainsAny(s, " \n{}<>\"") || !strings.Contains(s, "=") {
+		return "", false
+	}
+	pairs := strings.Split(s, "&")
+	for i, p := range pairs {
+		k, _, ok := strings.Cut(p, "=")
+		if !ok {
+			return "", false
+		}
+		name, err := url.QueryUnescape(k)
+		if err != nil {
+			return "", false
+		}
+		if sensitive(name) {
+			pairs[i] = k + "=" + Mask
+		}
+	}
+	return strings.Join(pairs, "&"), true
+}
+
+// Header returns a copy of h with secret headers masked.
+func Header(h http.Header) http.Header {
+	out := h.Clone()
+	for k, vs := range out {
+		if _, ok := sensitiveHeaders[http.CanonicalHeaderKey(k)]; ok {
+			for i := range vs {
+				vs[i] = Mask
+			}
+		}
+	}
+	return out
+}
+
+// URL masks secret query parameters in raw, including Google API keys
+// (key=...).
+func URL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return raw
+	}
+	if out, ok := form(u.RawQuery, func(k string) bool { return k == "key" || Sensitive(k) }); ok {
+		u.RawQuery = out
+	}
+	return u.String()
+}
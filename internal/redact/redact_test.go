@@ -0,0 +1,71 @@
+package redact
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBody_Form(t *testing.T) {
+	in := "username=me%40example.com&password=hunter2&grant_type=password&client_secret=s3&client_id=android&refresh_token=r"
+	got := Body([]byte(in))
+	want := "username=***&password=***&grant_type=password&client_secret=***&client_id=android&refresh_token=***"
+	if got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestBody_GlovoJSON(t *testing.T) {
+	got := Body([]byte(`{"grantType":"password","username":"u","password":"p","accessToken":"a","refreshToken":"r"}`))
+	for _, leak := range []string{`"u"`, `"p"`, `"a"`, `"r"`} {
+		if strings.Contains(got, leak) {
+			t.Fatalf("leaked %s: %s", leak, got)
+		}
+	}
+	if !strings.Contains(got, `"grantType":"password"`) {
+		t.Fatalf("over-redacted: %s", got)
+	}
+}
+
+func TestBodyKeepTypes(t *testing.T) {
+	in := `{"id":12345678901234567890,"access_token":"a","address":{"street":"Main","lat":48.2,"lines":["x"]},"total":9.5}`
+	got := BodyKeepTypes([]byte(in))
+
+	var v struct {
+		ID          json.Number `json:"id"`
+		AccessToken string      `json:"access_token"`
+		Address     struct {
+			Street string   `json:"street"`
+			Lat    float64  `json:"lat"`
+			Lines  []string `json:"lines"`
+		} `json:"address"`
+		Total float64 `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(got), &v); err != nil {
+		t.Fatalf("decode %s: %v", got, err)
+	}
+	if v.ID != "12345678901234567890" || v.AccessToken != Mask || v.Address.Street != Mask || v.Address.Lat != 0 || v.Address.Lines[0] != Mask || v.Total != 9.5 {
+		t.Fatalf("unexpected: %+v (%s)", v, got)
+	}
+}
+
+func TestHeaderAndURL(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer t")
+	h.Set("Cookie", "a=b")
+	h.Set("X-Mfa-Token", "m")
+	h.Set("Accept", "application/json")
+	got := Header(h)
+	if got.Get("Authorization") != Mask || got.Get("Cookie") != Mask || got.Get("X-Mfa-Token") != Mask || got.Get("Accept") != "application/json" {
+		t.Fatalf("unexpected: %v", got)
+	}
+	if h.Get("Authorization") != "Bearer t" {
+		t.Fatalf("input modified")
+	}
+
+	u := URL("https://firebaseinstallations.googleapis.com/v1/projects/1/installations?key=AIza&x=1")
+	if u != "https://firebaseinstallations.googleapis.com/v1/projects/1/installations?key=***&x=1" {
+		t.Fatalf("url: %s", u)
+	}
+}
@@ -0,0 +1,235 @@
+// Package search keeps a small on-disk inverted index over ordered dishes
+// (product names, variations, toppings, special instructions).
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+const indexVersion = 1
+
+// Doc is one indexed order; Lines are the searchable item lines.
+type Doc struct {
+	Provider string    `json:"provider"`
+	OrderID  string    `json:"order_id"`
+	Vendor   string    `json:"vendor,omitempty"`
+	When     time.Time `json:"when,omitzero"`
+	Lines    []string  `json:"lines,omitempty"`
+}
+
+func (d Doc) Key() string { return d.Provider + ":" + d.OrderID }
+
+type Posting struct {
+	Doc  string `json:"d"`
+	Line int    `json:"l"`
+}
+
+type Hit struct {
+	Doc  Doc
+	Line string
+}
+
+type Index struct {
+	Version int                  `json:"version"`
+	Docs    map[string]Doc       `json:"docs"`
+	Terms   map[string][]Posting `json:"terms"`
+	// Complete lists the providers whose whole history has been indexed; until
+	// then refreshes keep paging past indexed orders (see SetComplete).
+	Complete map[string]bool `json:"complete,omitempty"`
+
+	path string
+}
+
+// DefaultPath returns the index path next to the config file.
+func DefaultPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "search", "index.json")
+}
+
+// Load reads the index at path; a missing or incompatible file yields an empty index.
+func Load(path string) (*Index, error) {
+	idx := &Index{Version: indexVersion, Docs: map[string]Doc{}, Terms: map[string][]Posting{}, path: path}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return idx, nil
+		}
+		return nil, err
+	}
+	var disk Index
+	if err := json.Unmarshal(b, &disk); err != nil {
+		return nil, fmt.Errorf("search index %s: %w", path, err)
+	}
+	if disk.Version != indexVersion {
+		return idx, nil
+	}
+	if disk.Docs != nil {
+		idx.Docs = disk.Docs
+	}
+	if disk.Terms != nil {
+		idx.Terms = disk.Terms
+	}
+	idx.Complete = disk.Complete
+	return idx, nil
+}
+
+func (x *Index) Save() error {
+	if err := os.MkdirAll(filepath.Dir(x.path), 0o700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(x)
+	if err != nil {
+		return err
+	}
+	tmp := x.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, x.path)
+}
+
+func (x *Index) Len() int { return len(x.Docs) }
+
+func (x *Index) Has(key string) bool {
+	_, ok := x.Docs[key]
+	return ok
+}
+
+// Reset drops all documents (used for --rebuild).
+func (x *Index) Reset() {
+	x.Docs = map[string]Doc{}
+	x.Terms = map[string][]Posting{}
+	x.Complete = nil
+}
+
+// IsComplete reports whether provider's history was indexed to its end.
+func (x *Index) IsComplete(provider string) bool { return x.Complete[provider] }
+
+// SetComplete records whether provider's history is indexed to its end.
+func (x *Index) SetComplete(provider string, complete bool) {
+	if !complete {
+		delete(x.Complete, provider)
+		return
+	}
+	if x.Complete == nil {
+		x.Complete = map[string]bool{}
+	}
+	x.Complete[provider] = true
+}
+
+// Add indexes an order. Orders without items are still recorded so incremental
+// refreshes don't fetch them again.
+func (x *Index) Add(o provider.Order) {
+	d := DocFromOrder(o)
+	key := d.Key()
+	if x.Has(key) {
+		return
+	}
+	x.Docs[key] = d
+	for i, line := range d.Lines {
+		seen := map[string]bool{}
+		for _, term := range Tokenize(line) {
+			if seen[term] {
+				continue
+			}
+			seen[term] = true
+			x.Terms[term] = append(x.Terms[term], Posting{Doc: key, Line: i})
+		}
+	}
+}
+
+// Search returns lines matching every query term (the last term also matches
+// as a prefix, so "pad th" finds "Pad Thai"), newest order first.
+func (x *Index) Search(query string) []Hit {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var matched map[Posting]bool
+	for i, term := range terms {
+		cur := map[Posting]bool{}
+		if i == len(terms)-1 {
+			for t, ps := range x.Terms {
+				if strings.HasPrefix(t, term) {
+					for _, p := range ps {
+						cur[p] = true
+					}
+				}
+			}
+		} else {
+			for _, p := range x.Terms[term] {
+				cur[p] = true
+			}
+		}
+		if matched == nil {
+			matched = cur
+			continue
+		}
+		for p := range matched {
+			if !cur[p] {
+				delete(matched, p)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(matched))
+	for p := range matched {
+		d, ok := x.Docs[p.Doc]
+		if !ok || p.Line >= len(d.Lines) {
+			continue
+		}
+		hits = append(hits, Hit{Doc: d, Line: d.Lines[p.Line]})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if !hits[i].Doc.When.Equal(hits[j].Doc.When) {
+			return hits[i].Doc.When.After(hits[j].Doc.When)
+		}
+		if hits[i].Doc.Key() != hits[j].Doc.Key() {
+			return hits[i].Doc.Key() < hits[j].Doc.Key()
+		}
+		return hits[i].Line < hits[j].Line
+	})
+	return hits
+}
+
+// DocFromOrder turns each item into one line: "2x Pad Thai (Tofu) + Egg, Peanuts [no cilantro]".
+func DocFromOrder(o provider.Order) Doc {
+	d := Doc{Provider: o.Provider, OrderID: o.ID, Vendor: o.Vendor, When: o.When()}
+	for _, it := range o.Items {
+		var b strings.Builder
+		if it.Quantity > 0 {
+			fmt.Fprintf(&b, "%dx ", it.Quantity)
+		}
+		b.WriteString(it.Name)
+		if it.Variation != "" {
+			fmt.Fprintf(&b, " (%s)", it.Variation)
+		}
+		if len(it.Toppings) > 0 {
+			b.WriteString(" + " + strings.Join(it.Toppings, ", "))
+		}
+		if it.Notes != "" {
+			fmt.Fprintf(&b, " [%s]", it.Notes)
+		}
+		if line := strings.TrimSpace(b.String()); line != "" {
+			d.Lines = append(d.Lines, line)
+		}
+	}
+	return d
+}
+
+// Tokenize lowercases s and splits it on anything that isn't a letter or digit.
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
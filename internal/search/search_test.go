@@ -0,0 +1,56 @@
+package search
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func TestIndex_SearchAndReload(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "search", "index.json")
+	idx, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	idx.Add(provider.Order{
+		Provider: "foodora", ID: "old", Vendor: "Thai Place",
+		DeliveredAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Items: []provider.Item{
+			{Name: "Pad Thai", Variation: "Tofu", Quantity: 1, Toppings: []string{"Peanuts"}},
+			{Name: "Spring Rolls", Notes: "extra sauce"},
+		},
+	})
+	idx.Add(provider.Order{
+		Provider: "glovo", ID: "new", Vendor: "Wok",
+		PlacedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		Items:    []provider.Item{{Name: "Pad Thai", Quantity: 2}},
+	})
+	idx.Add(provider.Order{Provider: "glovo", ID: "empty"})
+	if err := idx.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	idx, err = Load(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if idx.Len() != 3 || !idx.Has("glovo:empty") {
+		t.Fatalf("unexpected docs: %d", idx.Len())
+	}
+
+	hits := idx.Search("PAD th")
+	if len(hits) != 2 || hits[0].Doc.OrderID != "new" || hits[1].Line != "1x Pad Thai (Tofu) + Peanuts" {
+		t.Fatalf("unexpected hits: %#v", hits)
+	}
+	if hits := idx.Search("sauce"); len(hits) != 1 || hits[0].Line != "Spring Rolls [extra sauce]" {
+		t.Fatalf("unexpected notes hits: %#v", hits)
+	}
+	// Terms must match on the same line.
+	if hits := idx.Search("pad rolls"); len(hits) != 0 {
+		t.Fatalf("expected no cross-line match: %#v", hits)
+	}
+}
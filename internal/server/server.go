@@ -0,0 +1,312 @@
+// Package server exposes normalized orders over a small local HTTP/JSON API.
+//
+//	GET /v1/health
+//	GET /v1/orders/active[?provider=a,b]
+//	GET /v1/history[?provider=a,b&limit=N]
+//	GET /v1/orders/{provider}/{id}
+//
+// Every request needs "Authorization: Bearer <token>". Successful responses are
+// cached in memory for Options.CacheTTL; add ?fresh=1 to bypass the cache.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// ErrNotFound marks Open errors for providers that don't exist (HTTP 404).
+var ErrNotFound = errors.New("not found")
+
+type Options struct {
+	// Token is the bearer token clients must send. Required.
+	Token string
+	// CacheTTL is how long successful responses are reused (0 disables caching).
+	CacheTTL time.Duration
+	// Open returns ready-to-use providers (only the named ones when only is
+	// non-empty). It runs serialized with all other upstream calls, so it may
+	// refresh sessions in shared state.
+	Open func(ctx context.Context, only []string) ([]provider.Provider, error)
+	// AfterUpstream runs after every batch of upstream calls, e.g. to persist
+	// refreshed tokens. Errors are logged in the response, not fatal.
+	AfterUpstream func() error
+	Now           func() time.Time
+}
+
+// Response is the JSON body of order endpoints: the normalized order
+// document plus per-provider errors for partial failures.
+type Response struct {
+	provider.Document
+	Errors []string `json:"errors,omitempty"`
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+type cacheEntry struct {
+	status  int
+	body    []byte
+	expires time.Time
+}
+
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+
+	// upstream serializes provider access (sessions live in shared state).
+	upstream sync.Mutex
+
+	cacheMu sync.Mutex
+	cache   map[string]cacheEntry
+}
+
+func New(opts Options) (*Server, error) {
+	if strings.TrimSpace(opts.Token) == "" {
+		return nil, errors.New("server: missing token")
+	}
+	if opts.Open == nil {
+		return nil, errors.New("server: missing Open")
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	s := &Server{opts: opts, mux: http.NewServeMux(), cache: map[string]cacheEntry{}}
+	s.mux.HandleFunc("GET /v1/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "schema_version": provider.SchemaVersion})
+	})
+	s.mux.HandleFunc("GET /v1/orders/active", s.cached(s.activeOrders))
+	s.mux.HandleFunc("GET /v1/history", s.cached(s.history))
+	s.mux.HandleFunc("GET /v1/orders/{provider}/{id}", s.cached(s.orderDetail))
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="ordercli"`)
+		writeJSON(w, http.StatusUnauthorized, errorBody{Error: "missing or invalid bearer token"})
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), []byte(s.opts.Token)) == 1
+}
+
+// handlerFunc computes a response; it runs with the upstream lock held.
+type handlerFunc func(r *http.Request) (int, any)
+
+func (s *Server) cached(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := cacheKey(r)
+		fresh := r.URL.Query().Get("fresh") == "1"
+		if !fresh {
+			if e, ok := s.lookup(key); ok {
+				w.Header().Set("X-Cache", "hit")
+				writeRaw(w, e.status, e.body)
+				return
+			}
+		}
+
+		s.upstream.Lock()
+		// Another request may have filled the entry while we waited.
+		if e, ok := s.lookup(key); ok && !fresh {
+			s.upstream.Unlock()
+			w.Header().Set("X-Cache", "hit")
+			writeRaw(w, e.status, e.body)
+			return
+		}
+		status, v := h(r)
+		if s.opts.AfterUpstream != nil {
+			if err := s.opts.AfterUpstream(); err != nil {
+				if resp, ok := v.(*Response); ok {
+					resp.Errors = append(resp.Errors, "persist session: "+err.Error())
+				}
+			}
+		}
+		s.upstream.Unlock()
+
+		body, err := json.Marshal(v)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorBody{Error: err.Error()})
+			return
+		}
+		body = append(body, '\n')
+		if status == http.StatusOK && s.opts.CacheTTL > 0 {
+			s.cacheMu.Lock()
+			s.cache[key] = cacheEntry{status: status, body: body, expires: s.opts.Now().Add(s.opts.CacheTTL)}
+			s.cacheMu.Unlock()
+		}
+		w.Header().Set("X-Cache", "miss")
+		writeRaw(w, status, body)
+	}
+}
+
+func (s *Server) lookup(key string) (cacheEntry, bool) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	e, ok := s.cache[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if !s.opts.Now().Before(e.expires) {
+		delete(s.cache, key)
+		return cacheEntry{}, false
+	}
+	return e, true
+}
+
+func (s *Server) activeOrders(r *http.Request) (int, any) {
+	opened, err := s.opts.Open(r.Context(), providerFilter(r))
+	if err != nil {
+		return errorStatus(err), errorBody{Error: err.Error()}
+	}
+	resp := &Response{}
+	var orders []provider.Order
+	tried := 0
+	for _, p := range opened {
+		if !p.Capabilities().ActiveOrders {
+			continue
+		}
+		tried++
+		got, err := p.ActiveOrders(r.Context())
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %v", p.Name(), err))
+			continue
+		}
+		orders = append(orders, got...)
+	}
+	resp.Document = provider.NewListDocument(orders)
+	return listStatus(tried, len(resp.Errors)), resp
+}
+
+func (s *Server) history(r *http.Request) (int, any) {
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 500 {
+			return http.StatusBadRequest, errorBody{Error: "limit must be 1..500"}
+		}
+		limit = n
+	}
+	opened, err := s.opts.Open(r.Context(), providerFilter(r))
+	if err != nil {
+		return errorStatus(err), errorBody{Error: err.Error()}
+	}
+	resp := &Response{}
+	var orders []provider.Order
+	tried := 0
+	for _, p := range opened {
+		if !p.Capabilities().History {
+			continue
+		}
+		tried++
+		got, err := provider.FetchHistory(r.Context(), p, limit, 20)
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %v", p.Name(), err))
+			continue
+		}
+		orders = append(orders, got...)
+	}
+	provider.SortNewestFirst(orders)
+	resp.Document = provider.NewListDocument(orders)
+	return listStatus(tried, len(resp.Errors)), resp
+}
+
+func (s *Server) orderDetail(r *http.Request) (int, any) {
+	name, id := r.PathValue("provider"), r.PathValue("id")
+	opened, err := s.opts.Open(r.Context(), []string{name})
+	if err != nil {
+		return errorStatus(err), errorBody{Error: err.Error()}
+	}
+	if len(opened) == 0 {
+		return http.StatusNotFound, errorBody{Error: "unknown provider " + name}
+	}
+	// With several accounts, the first one that knows the order wins.
+	for _, p := range opened {
+		var o provider.Order
+		if o, err = p.OrderDetail(r.Context(), id); err == nil {
+			return http.StatusOK, &Response{Document: provider.NewOrderDocument(o)}
+		}
+	}
+	return errorStatus(err), errorBody{Error: err.Error()}
+}
+
+func providerFilter(r *http.Request) []string {
+	var out []string
+	for _, v := range r.URL.Query()["provider"] {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
+
+// listStatus is 200 unless every provider that was asked failed.
+func listStatus(tried, failed int) int {
+	if tried > 0 && failed == tried {
+		return http.StatusBadGateway
+	}
+	return http.StatusOK
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, provider.ErrUnsupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// cacheKey is the path plus the query without "fresh", with sorted keys.
+func cacheKey(r *http.Request) string {
+	q := r.URL.Query()
+	q.Del("fresh")
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(r.URL.Path)
+	for _, k := range keys {
+		b.WriteString("&" + k + "=" + strings.Join(q[k], ","))
+	}
+	return b.String()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		b = []byte(`{"error":"encode response"}`)
+	}
+	writeRaw(w, status, append(b, '\n'))
+}
+
+func writeRaw(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+type fakeProvider struct {
+	name  string
+	calls int
+	fail  bool
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{ActiveOrders: true, History: true, OrderDetail: p.name == "a"}
+}
+
+func (p *fakeProvider) ActiveOrders(ctx context.Context) ([]provider.Order, error) {
+	p.calls++
+	if p.fail {
+		return nil, errors.New("boom")
+	}
+	return []provider.Order{{Provider: p.name, ID: "active-1"}}, nil
+}
+
+func (p *fakeProvider) History(ctx context.Context, req provider.HistoryRequest) (provider.HistoryPage, error) {
+	p.calls++
+	return provider.HistoryPage{Orders: []provider.Order{{Provider: p.name, ID: "h-1"}}}, nil
+}
+
+func (p *fakeProvider) OrderDetail(ctx context.Context, id string) (provider.Order, error) {
+	p.calls++
+	if p.name != "a" {
+		return provider.Order{}, provider.ErrUnsupported
+	}
+	return provider.Order{Provider: p.name, ID: id, Vendor: "V"}, nil
+}
+
+func (p *fakeProvider) Profile(ctx context.Context) (provider.Profile, error) {
+	return provider.Profile{}, provider.ErrUnsupported
+}
+
+func TestServer(t *testing.T) {
+	a := &fakeProvider{name: "a"}
+	b := &fakeProvider{name: "b", fail: true}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	persisted := 0
+	srv, err := New(Options{
+		Token:    "secret",
+		CacheTTL: time.Minute,
+		Now:      func() time.Time { return now },
+		Open: func(ctx context.Context, only []string) ([]provider.Provider, error) {
+			all := map[string]provider.Provider{"a": a, "b": b}
+			if len(only) == 0 {
+				return []provider.Provider{a, b}, nil
+			}
+			var out []provider.Provider
+			for _, name := range only {
+				p, ok := all[name]
+				if !ok {
+					return nil, ErrNotFound
+				}
+				out = append(out, p)
+			}
+			return out, nil
+		},
+		AfterUpstream: func() error { persisted++; return nil },
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	do := func(path, token string) (*httptest.ResponseRecorder, Response) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		var resp Response
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	if rec, _ := do("/v1/orders/active", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+
+	rec, resp := do("/v1/orders/active", "secret")
+	if rec.Code != http.StatusOK || len(resp.Orders) != 1 || len(resp.Errors) != 1 || resp.SchemaVersion != provider.SchemaVersion {
+		t.Fatalf("active: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec, _ := do("/v1/orders/active", "secret"); rec.Header().Get("X-Cache") != "hit" || a.calls != 1 {
+		t.Fatalf("expected cache hit, calls=%d", a.calls)
+	}
+	now = now.Add(2 * time.Minute)
+	if do("/v1/orders/active", "secret"); a.calls != 2 {
+		t.Fatalf("expected expiry, calls=%d", a.calls)
+	}
+	if do("/v1/orders/active?fresh=1", "secret"); a.calls != 3 {
+		t.Fatalf("expected fresh bypass, calls=%d", a.calls)
+	}
+	if persisted != 3 {
+		t.Fatalf("AfterUpstream called %d times", persisted)
+	}
+
+	if rec, _ := do("/v1/orders/active?provider=b", "secret"); rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502 when all fail, got %d", rec.Code)
+	}
+
+	rec, resp = do("/v1/history?provider=a&limit=5", "secret")
+	if rec.Code != http.StatusOK || len(resp.Orders) != 1 || resp.Orders[0].ID != "h-1" {
+		t.Fatalf("history: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec, _ := do("/v1/history?limit=x", "secret"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+
+	rec, resp = do("/v1/orders/a/X1", "secret")
+	if rec.Code != http.StatusOK || resp.Order == nil || resp.Order.ID != "X1" {
+		t.Fatalf("detail: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec, _ := do("/v1/orders/b/X1", "secret"); rec.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d", rec.Code)
+	}
+	if rec, _ := do("/v1/orders/zz/X1", "secret"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,194 @@
+// Package stats aggregates normalized orders into spend reports. Amounts are
+// always grouped by currency; totals in different currencies are never summed.
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+// UnknownCurrency groups orders whose provider didn't report a currency.
+const UnknownCurrency = "?"
+
+type Bucket struct {
+	Key    string  `json:"key"`
+	Orders int     `json:"orders"`
+	Total  float64 `json:"total"`
+}
+
+// Spend is everything computed for one currency.
+type Spend struct {
+	Currency   string   `json:"currency"`
+	Orders     int      `json:"orders"`
+	Total      float64  `json:"total"`
+	Average    float64  `json:"average"`
+	ByMonth    []Bucket `json:"by_month"`
+	ByVendor   []Bucket `json:"by_vendor"`
+	ByProvider []Bucket `json:"by_provider"`
+	ByWeekday  []Bucket `json:"by_weekday"`
+}
+
+// Frequency describes how often orders were placed (all currencies).
+type Frequency struct {
+	First      time.Time `json:"first,omitzero"`
+	Last       time.Time `json:"last,omitzero"`
+	Days       int       `json:"days"`
+	PerWeek    float64   `json:"per_week"`
+	PerMonth   float64   `json:"per_month"`
+	AvgGapDays float64   `json:"avg_gap_days"`
+	Dated      int       `json:"dated_orders"`
+	Undated    int       `json:"undated_orders"`
+}
+
+type Report struct {
+	Orders     int       `json:"orders"`
+	Currencies []Spend   `json:"currencies"`
+	Frequency  Frequency `json:"frequency"`
+	// Cancelled counts cancelled and failed orders, which are left out of
+	// everything else.
+	Cancelled int `json:"cancelled_orders"`
+}
+
+// Compute builds a report; month and weekday buckets use loc. Cancelled and
+// failed orders (see provider.Order.Cancelled) are only counted.
+func Compute(orders []provider.Order, loc *time.Location) Report {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	type acc struct {
+		spend    Spend
+		month    map[string]*Bucket
+		vendor   map[string]*Bucket
+		provider map[string]*Bucket
+		weekday  [7]Bucket
+	}
+	byCur := map[string]*acc{}
+	var dated []time.Time
+	var r Report
+
+	for _, o := range orders {
+		if o.Cancelled() {
+			r.Cancelled++
+			continue
+		}
+		r.Orders++
+		cur := o.Currency
+		if cur == "" {
+			cur = UnknownCurrency
+		}
+		a := byCur[cur]
+		if a == nil {
+			a = &acc{
+				spend:    Spend{Currency: cur},
+				month:    map[string]*Bucket{},
+				vendor:   map[string]*Bucket{},
+				provider: map[string]*Bucket{},
+			}
+			for i := range a.weekday {
+				a.weekday[i].Key = time.Weekday((i + 1) % 7).String()
+			}
+			byCur[cur] = a
+		}
+		a.spend.Orders++
+		a.spend.Total += o.Total
+
+		vendor := o.Vendor
+		if vendor == "" {
+			vendor = "-"
+		}
+		add(a.vendor, vendor, o.Total)
+		add(a.provider, o.Provider, o.Total)
+
+		when := o.When()
+		if when.IsZero() {
+			continue
+		}
+		when = when.In(loc)
+		dated = append(dated, when)
+		add(a.month, when.Format("2006-01"), o.Total)
+		// Monday first.
+		wd := &a.weekday[(int(when.Weekday())+6)%7]
+		wd.Orders++
+		wd.Total += o.Total
+	}
+
+	for _, a := range byCur {
+		s := a.spend
+		if s.Orders > 0 {
+			s.Average = s.Total / float64(s.Orders)
+		}
+		s.ByMonth = sorted(a.month, func(x, y Bucket) bool { return x.Key < y.Key })
+		s.ByVendor = sorted(a.vendor, byTotal)
+		s.ByProvider = sorted(a.provider, byTotal)
+		s.ByWeekday = a.weekday[:]
+		r.Currencies = append(r.Currencies, s)
+	}
+	sort.Slice(r.Currencies, func(i, j int) bool {
+		if r.Currencies[i].Orders != r.Currencies[j].Orders {
+			return r.Currencies[i].Orders > r.Currencies[j].Orders
+		}
+		return r.Currencies[i].Currency < r.Currencies[j].Currency
+	})
+
+	r.Frequency = frequency(dated)
+	r.Frequency.Undated = r.Orders - len(dated)
+	return r
+}
+
+// TopVendors returns the n vendors with the highest spend.
+func (s Spend) TopVendors(n int) []Bucket {
+	if n <= 0 || n >= len(s.ByVendor) {
+		return s.ByVendor
+	}
+	return s.ByVendor[:n]
+}
+
+func frequency(dated []time.Time) Frequency {
+	f := Frequency{Dated: len(dated)}
+	if len(dated) == 0 {
+		return f
+	}
+	sort.Slice(dated, func(i, j int) bool { return dated[i].Before(dated[j]) })
+	f.First, f.Last = dated[0], dated[len(dated)-1]
+	span := f.Last.Sub(f.First)
+	// Count both end days so a single day counts as one.
+	f.Days = int(span.Hours()/24) + 1
+	f.PerWeek = float64(len(dated)) / (float64(f.Days) / 7)
+	f.PerMonth = float64(len(dated)) / (float64(f.Days) / 30.44)
+	if len(dated) > 1 {
+		f.AvgGapDays = span.Hours() / 24 / float64(len(dated)-1)
+	}
+	return f
+}
+
+func add(m map[string]*Bucket, key string, total float64) {
+	b := m[key]
+	if b == nil {
+		b = &Bucket{Key: key}
+		m[key] = b
+	}
+	b.Orders++
+	b.Total += total
+}
+
+func byTotal(x, y Bucket) bool {
+	if x.Total != y.Total {
+		return x.Total > y.Total
+	}
+	if x.Orders != y.Orders {
+		return x.Orders > y.Orders
+	}
+	return x.Key < y.Key
+}
+
+func sorted(m map[string]*Bucket, less func(x, y Bucket) bool) []Bucket {
+	out := make([]Bucket, 0, len(m))
+	for _, b := range m {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool { return less(out[i], out[j]) })
+	return out
+}
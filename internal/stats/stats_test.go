@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steipete/ordercli/internal/provider"
+)
+
+func TestCompute_PerCurrency(t *testing.T) {
+	t.Parallel()
+
+	mon := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC) // Monday
+	orders := []provider.Order{
+		{Provider: "foodora", Vendor: "A", Total: 10, Currency: "EUR", DeliveredAt: mon},
+		{Provider: "foodora", Vendor: "A", Total: 20, Currency: "EUR", DeliveredAt: mon.AddDate(0, 0, 7)},
+		{Provider: "glovo", Vendor: "B", Total: 5, Currency: "EUR", DeliveredAt: mon.AddDate(0, 1, 2)},
+		{Provider: "deliveroo", Vendor: "C", Total: 12, Currency: "GBP", PlacedAt: mon.AddDate(0, 0, 1)},
+		{Provider: "glovo", Vendor: "B", Total: 3},
+		{Provider: "foodora", Vendor: "A", Total: 40, Currency: "EUR", Status: "Order cancelled", DeliveredAt: mon},
+		{Provider: "deliveroo", Vendor: "D", Total: 9, Currency: "GBP", Status: "FAILED", PlacedAt: mon},
+	}
+	r := Compute(orders, time.UTC)
+
+	if r.Orders != 5 || r.Cancelled != 2 || len(r.Currencies) != 3 {
+		t.Fatalf("unexpected report: %#v", r)
+	}
+	eur := r.Currencies[0]
+	if eur.Currency != "EUR" || eur.Total != 35 || eur.Orders != 3 || eur.Average != 35.0/3 {
+		t.Fatalf("unexpected EUR: %#v", eur)
+	}
+	if top := eur.TopVendors(1); len(top) != 1 || top[0].Key != "A" || top[0].Total != 30 {
+		t.Fatalf("unexpected top vendors: %#v", top)
+	}
+	if len(eur.ByMonth) != 2 || eur.ByMonth[0].Key != "2025-03" || eur.ByMonth[0].Total != 30 {
+		t.Fatalf("unexpected months: %#v", eur.ByMonth)
+	}
+	if wd := eur.ByWeekday[0]; wd.Key != "Monday" || wd.Orders != 2 {
+		t.Fatalf("unexpected weekday: %#v", eur.ByWeekday)
+	}
+	if len(eur.ByWeekday) != 7 || eur.ByWeekday[6].Key != "Sunday" {
+		t.Fatalf("unexpected weekdays: %#v", eur.ByWeekday)
+	}
+	for _, s := range r.Currencies {
+		if s.Currency == "GBP" && s.Total != 12 {
+			t.Fatalf("GBP mixed: %#v", s)
+		}
+	}
+
+	f := r.Frequency
+	if f.Dated != 4 || f.Undated != 1 || f.Days != 34 || !f.First.Equal(mon) {
+		t.Fatalf("unexpected frequency: %#v", f)
+	}
+}